@@ -1,11 +1,9 @@
 package zksigma
 
 import (
-	"bytes"
-	"crypto/rand"
+	"fmt"
+	"io"
 	"math/big"
-
-	"github.com/mit-dci/zksigma/wire"
 )
 
 // ABCProof is a proof that generates a proof that the relationship between three
@@ -47,11 +45,13 @@ type ABCProof struct {
 	T1        ECPoint  // T1 = u1G + u2MTok
 	T2        ECPoint  // T2 = u1B + u3H
 	Challenge *big.Int // chal = HASH(G,H,CM,CMTok,B,C,T1,T2)
-	j         *big.Int // j = u1 + v * chal
-	k         *big.Int // k = u2 + inv(sk) * chal
-	l         *big.Int // l = u3 + (uc - v * ub) * chal
+	J         *big.Int // j = u1 + v * chal
+	K         *big.Int // k = u2 + inv(sk) * chal
+	L         *big.Int // l = u3 + (uc - v * ub) * chal
 	CToken    ECPoint
-	disjuncAC *DisjunctiveProof
+	DisjuncAC *DisjunctiveProof // proves c = 0 OR c = 1
+	curve     Group             // group the proof was computed in
+	hash      ChallengeHash     // hash the challenges were computed with
 }
 
 // NewABCProof generates a proof that the relationship between three scalars a,b and c is ab = c,
@@ -60,34 +60,46 @@ type ABCProof struct {
 // Option Right is proving that A, B and C commit to v, inv(v) and 1 respectively and simulating that A and C commit to 0.
 func NewABCProof(zkpcp ZKPCurveParams, CM, CMTok ECPoint, value, sk *big.Int, option Side) (*ABCProof, error) {
 
+	in := zkpcp.validateInputs(ABCProofType, "NewABCProof")
+	in.point("CM", CM)
+	in.point("CMTok", CMTok)
+	if err := in.done(); err != nil {
+		return nil, err
+	}
+
 	// We cannot check that CM log is actually the value, but the verification should catch that
 
-	u1, err := rand.Int(rand.Reader, zkpcp.C.Params().N)
+	nonces, err := zkpcp.newNonceSource(ABCProofType, []*big.Int{value, sk}, CM, CMTok)
 	if err != nil {
 		return nil, err
 	}
-	u2, err := rand.Int(rand.Reader, zkpcp.C.Params().N)
+	defer nonces.destroy()
+	u1, err := nonces.scalar()
+	if err != nil {
+		return nil, err
+	}
+	u2, err := nonces.scalar()
 	if err != nil {
 		return nil, err
 	}
 
-	u3, err := rand.Int(rand.Reader, zkpcp.C.Params().N)
+	u3, err := nonces.scalar()
 	if err != nil {
 		return nil, err
 	}
 
-	ub, err := rand.Int(rand.Reader, zkpcp.C.Params().N)
+	ub, err := nonces.scalar()
 	if err != nil {
 		return nil, err
 	}
-	uc, err := rand.Int(rand.Reader, zkpcp.C.Params().N)
+	uc, err := nonces.scalar()
 	if err != nil {
 		return nil, err
 	}
 
 	B := ECPoint{}
 	C := ECPoint{}
-	CToken := zkpcp.Mult(zkpcp.Mult(zkpcp.H, sk), uc)
+	CToken := zkpcp.MultSecret(zkpcp.MultSecret(zkpcp.H, sk), uc)
 
 	var disjuncAC *DisjunctiveProof
 	var e error
@@ -106,7 +118,7 @@ func NewABCProof(zkpcp ZKPCurveParams, CM, CMTok ECPoint, value, sk *big.Int, op
 	} else if option == Right && value.Cmp(BigZero) != 0 {
 		// MUST: c = 1! ; side = right
 
-		B = PedCommitR(zkpcp, new(big.Int).ModInverse(value, zkpcp.C.Params().N), ub)
+		B = PedCommitR(zkpcp, zkpcp.C.InvertScalar(value), ub)
 
 		// C = G + ucH
 		C = PedCommitR(zkpcp, big.NewInt(1), uc)
@@ -114,49 +126,51 @@ func NewABCProof(zkpcp ZKPCurveParams, CM, CMTok ECPoint, value, sk *big.Int, op
 		// Look at notes a couple lines above on what the input is like this
 		disjuncAC, e = NewDisjunctiveProof(zkpcp, CM, CMTok, zkpcp.H, zkpcp.Sub(C, zkpcp.G), uc, Right)
 	} else {
-		return &ABCProof{}, &errorProof{"ABCProof", "invalid side-value pair passed"}
+		return &ABCProof{}, zkpcp.traceError(&ProofError{Type: ABCProofType, Op: "NewABCProof", Err: ErrInvalidWitness, Msg: "invalid side-value pair passed"})
 	}
 
 	if e != nil {
-		return &ABCProof{}, &errorProof{"ABCProof", "disjunctiveProve within ABCProve failed to generate"}
+		return &ABCProof{}, zkpcp.traceError(&ProofError{Type: ABCProofType, Op: "NewABCProof", Err: e, Msg: "disjunctiveProve within ABCProve failed to generate"})
 	}
 
 	// CMTok is Ta for the rest of the proof
 	// T1 = u1G + u2Ta
 	// u1G
-	u1G := zkpcp.Mult(zkpcp.G, u1)
+	u1G := zkpcp.MultSecret(zkpcp.G, u1)
 	// u2Ta
-	u2Ta := zkpcp.Mult(CMTok, u2)
+	u2Ta := zkpcp.MultSecret(CMTok, u2)
 	// Sum the above two
 	T1 := zkpcp.Add(u1G, u2Ta)
 
 	// T2 = u1B + u3H
 	// u1B
-	u1B := zkpcp.Mult(B, u1)
+	u1B := zkpcp.MultSecret(B, u1)
 	// u3H
-	u3H := zkpcp.Mult(zkpcp.H, u3)
+	u3H := zkpcp.MultSecret(zkpcp.H, u3)
 	// Sum of the above two
 	T2 := zkpcp.Add(u1B, u3H)
 
 	// chal = HASH(G,H,CM,CMTok,B,C,T1,T2)
-	Challenge := GenerateChallenge(zkpcp, zkpcp.G.Bytes(), zkpcp.H.Bytes(),
-		CM.Bytes(), CMTok.Bytes(),
-		B.Bytes(), C.Bytes(),
-		T1.Bytes(), T2.Bytes())
+	Challenge := GenerateChallenge(zkpcp, zkpcp.Bytes(zkpcp.G), zkpcp.Bytes(zkpcp.H),
+		zkpcp.Bytes(CM), zkpcp.Bytes(CMTok),
+		zkpcp.Bytes(B), zkpcp.Bytes(C),
+		zkpcp.Bytes(T1), zkpcp.Bytes(T2))
+	zkpcp.trace(ABCProofType, "prover commitment", "B", zkpcp.logPoint(B), "C", zkpcp.logPoint(C),
+		"T1", zkpcp.logPoint(T1), "T2", zkpcp.logPoint(T2), "challenge", Challenge)
 
 	// j = u1 + v * chal
-	j := new(big.Int).Add(u1, new(big.Int).Mul(value, Challenge))
-	j = new(big.Int).Mod(j, zkpcp.C.Params().N)
+	j := zkpcp.C.AddScalars(u1, zkpcp.C.MulScalars(value, Challenge))
 
 	// k = u2 + inv(sk) * chal
 	// inv(sk)
-	isk := new(big.Int).ModInverse(sk, zkpcp.C.Params().N)
-	k := new(big.Int).Add(u2, new(big.Int).Mul(isk, Challenge))
-	k = new(big.Int).Mod(k, zkpcp.C.Params().N)
+	isk := zkpcp.C.InvertScalar(sk)
+	defer WipeScalars(isk)
+	k := zkpcp.C.AddScalars(u2, zkpcp.C.MulScalars(isk, Challenge))
 
 	// l = u3 + (uc - v * ub) * chal
-	temp1 := new(big.Int).Sub(uc, new(big.Int).Mul(value, ub))
-	l := new(big.Int).Add(u3, new(big.Int).Mul(temp1, Challenge))
+	temp1 := zkpcp.C.SubScalars(uc, zkpcp.C.MulScalars(value, ub))
+	defer WipeScalars(temp1)
+	l := zkpcp.C.AddScalars(u3, zkpcp.C.MulScalars(temp1, Challenge))
 
 	return &ABCProof{
 		B,
@@ -165,30 +179,54 @@ func NewABCProof(zkpcp ZKPCurveParams, CM, CMTok ECPoint, value, sk *big.Int, op
 		T2,
 		Challenge,
 		j, k, l, CToken,
-		disjuncAC}, nil
+		disjuncAC,
+		zkpcp.C,
+		zkpcp.Hash}, nil
 
 }
 
 // Verify checks if ABCProof aProof with appropriate commits CM and CMTok is correct
 func (aProof *ABCProof) Verify(zkpcp ZKPCurveParams, CM, CMTok ECPoint) (bool, error) {
 
+	if aProof == nil {
+		return false, zkpcp.traceError(&ProofError{Type: ABCProofType, Op: "ABCProof.Verify", Err: ErrMalformedProof, Msg: "passed proof is nil"})
+	}
+
+	// DisjuncAC checks its own inputs
+	v := zkpcp.validateInputs(ABCProofType, "ABCProof.Verify")
+	v.hash(aProof.hash)
+	v.point("CM", CM)
+	v.point("CMTok", CMTok)
+	v.point("B", aProof.B)
+	v.point("C", aProof.C)
+	v.point("T1", aProof.T1)
+	v.point("T2", aProof.T2)
+	v.scalar("Challenge", aProof.Challenge)
+	v.scalar("J", aProof.J)
+	v.scalar("K", aProof.K)
+	v.scalar("L", aProof.L)
+	if err := v.done(); err != nil {
+		return false, err
+	}
+
 	// Notes in ABCProof talk about why the Disjunc takes in this specific input even though it looks non-intuitive
 	// Here it is important that you subtract exactly 1 G from the aProof.C because that only allows for you to prove c = 1!
-	_, status := aProof.disjuncAC.Verify(zkpcp, CM, CMTok, zkpcp.H, zkpcp.Sub(aProof.C, zkpcp.G))
+	_, status := aProof.DisjuncAC.Verify(zkpcp, CM, CMTok, zkpcp.H, zkpcp.Sub(aProof.C, zkpcp.G))
 
 	if status != nil {
-		return false, &errorProof{"ABCVerify", "ABCProof for disjuncAC is false or not generated properly"}
+		return false, zkpcp.traceError(&ProofError{Type: ABCProofType, Op: "ABCProof.Verify", Err: status, Msg: "ABCProof for disjuncAC is false or not generated properly"})
 	}
 
-	Challenge := GenerateChallenge(zkpcp, zkpcp.G.Bytes(), zkpcp.H.Bytes(),
-		CM.Bytes(), CMTok.Bytes(),
-		aProof.B.Bytes(), aProof.C.Bytes(),
-		aProof.T1.Bytes(), aProof.T2.Bytes())
+	Challenge := GenerateChallenge(zkpcp, zkpcp.Bytes(zkpcp.G), zkpcp.Bytes(zkpcp.H),
+		zkpcp.Bytes(CM), zkpcp.Bytes(CMTok),
+		zkpcp.Bytes(aProof.B), zkpcp.Bytes(aProof.C),
+		zkpcp.Bytes(aProof.T1), zkpcp.Bytes(aProof.T2))
 
 	// chal = HASH(G,H,CM,CMTok,B,C,T1,T2)
 	if Challenge.Cmp(aProof.Challenge) != 0 {
-		return false, &errorProof{"ABCVerify", "proof contains incorrect challenge"}
+		return false, zkpcp.traceError(&ProofError{Type: ABCProofType, Op: "ABCProof.Verify", Err: ErrChallengeMismatch, Msg: "proof contains incorrect challenge"})
 	}
+	zkpcp.trace(ABCProofType, "verifier challenge", "challenge", Challenge)
 
 	// chalCM + T1 ?= jG + kCMTok
 	// chalCM
@@ -196,98 +234,221 @@ func (aProof *ABCProof) Verify(zkpcp ZKPCurveParams, CM, CMTok ECPoint) (bool, e
 	// + T1
 	lhs1 := zkpcp.Add(chalA, aProof.T1)
 	//jG
-	jG := zkpcp.Mult(zkpcp.G, aProof.j)
+	jG := zkpcp.Mult(zkpcp.G, aProof.J)
 	// kCMTok
-	kCMTok := zkpcp.Mult(CMTok, aProof.k)
+	kCMTok := zkpcp.Mult(CMTok, aProof.K)
 	// jG + kCMTok
 	rhs1 := zkpcp.Add(jG, kCMTok)
 
 	if !lhs1.Equal(rhs1) {
-		return false, &errorProof{"ABCProof", "cCM + T1 != jG + kCMTok"}
+		return false, zkpcp.traceError(&ProofError{Type: ABCProofType, Op: "ABCProof.Verify", Err: ErrEquationFailed, Equation: 1, Msg: "cCM + T1 != jG + kCMTok"})
 	}
 
 	// cC + T2 ?= jB + lH
 	chalC := zkpcp.Mult(aProof.C, Challenge)
 	lhs2 := zkpcp.Add(chalC, aProof.T2)
 
-	jB := zkpcp.Mult(aProof.B, aProof.j)
-	lH := zkpcp.Mult(zkpcp.H, aProof.l)
+	jB := zkpcp.Mult(aProof.B, aProof.J)
+	lH := zkpcp.Mult(zkpcp.H, aProof.L)
 	rhs2 := zkpcp.Add(jB, lH)
 
 	if !lhs2.Equal(rhs2) {
-		return false, &errorProof{"ABCVerify", "cC + T2 != jB + lH"}
+		return false, zkpcp.traceError(&ProofError{Type: ABCProofType, Op: "ABCProof.Verify", Err: ErrEquationFailed, Equation: 2, Msg: "cC + T2 != jB + lH"})
 	}
 
+	zkpcp.trace(ABCProofType, "verified")
 	return true, nil
 }
 
-// Bytes returns a byte slice with a serialized representation of ABCProof proof
-func (proof *ABCProof) Bytes() []byte {
-	var buf bytes.Buffer
-
-	WriteECPoint(&buf, proof.B)
-	WriteECPoint(&buf, proof.C)
-	WriteECPoint(&buf, proof.T1)
-	WriteECPoint(&buf, proof.T2)
-	WriteBigInt(&buf, proof.Challenge)
-	WriteBigInt(&buf, proof.j)
-	WriteBigInt(&buf, proof.k)
-	WriteBigInt(&buf, proof.l)
-	WriteECPoint(&buf, proof.CToken)
-	wire.WriteVarBytes(&buf, proof.disjuncAC.Bytes())
-
-	return buf.Bytes()
+// ChallengeHash returns the hash the challenges of aProof were computed with
+func (aProof *ABCProof) ChallengeHash() ChallengeHash {
+	return aProof.hash
 }
 
-// NewABCProofFromBytes returns an ABCProof generated from the deserialization of
-// byte slice b
-func NewABCProofFromBytes(b []byte) (*ABCProof, error) {
-	proof := new(ABCProof)
-	buf := bytes.NewBuffer(b)
-	var err error
-	proof.B, err = ReadECPoint(buf)
-	if err != nil {
-		return nil, err
+// Type returns ABCProofType
+func (aProof *ABCProof) Type() ProofType {
+	return ABCProofType
+}
+
+// VerifyStatement checks aProof against a ABCStatement, see Verify
+func (aProof *ABCProof) VerifyStatement(s Statement) (bool, error) {
+	st, ok := s.(ABCStatement)
+	if !ok {
+		return false, wrongStatement(ABCProofType, s)
 	}
-	proof.C, err = ReadECPoint(buf)
-	if err != nil {
-		return nil, err
+	return aProof.Verify(st.Params, st.CM, st.CMTok)
+}
+
+// Diagnose runs every check of Verify and reports the outcome of each, with
+// the report of DisjuncAC in Inner, see VerificationReport
+func (aProof *ABCProof) Diagnose(zkpcp ZKPCurveParams, CM, CMTok ECPoint) *VerificationReport {
+	r := newReport(ABCProofType, zkpcp)
+	if aProof == nil || aProof.DisjuncAC == nil {
+		return r.malformed()
 	}
-	proof.T1, err = ReadECPoint(buf)
-	if err != nil {
-		return nil, err
+	if !r.wellFormed([]ECPoint{CM, CMTok, aProof.B, aProof.C, aProof.T1, aProof.T2},
+		[]*big.Int{aProof.Challenge, aProof.J, aProof.K, aProof.L}) {
+		return r
 	}
-	proof.T2, err = ReadECPoint(buf)
-	if err != nil {
-		return nil, err
+	r.hash(aProof.hash)
+
+	r.Inner = append(r.Inner, aProof.DisjuncAC.Diagnose(zkpcp, CM, CMTok, zkpcp.H, zkpcp.Sub(aProof.C, zkpcp.G)))
+
+	Challenge := GenerateChallenge(zkpcp, zkpcp.Bytes(zkpcp.G), zkpcp.Bytes(zkpcp.H),
+		zkpcp.Bytes(CM), zkpcp.Bytes(CMTok),
+		zkpcp.Bytes(aProof.B), zkpcp.Bytes(aProof.C),
+		zkpcp.Bytes(aProof.T1), zkpcp.Bytes(aProof.T2))
+	r.scalar("HASH(G, H, CM, CMTok, B, C, T1, T2) == Challenge", Challenge, aProof.Challenge)
+
+	r.point("Challenge*CM + T1 == J*G + K*CMTok", 1,
+		zkpcp.Add(zkpcp.Mult(CM, aProof.Challenge), aProof.T1),
+		zkpcp.Add(zkpcp.Mult(zkpcp.G, aProof.J), zkpcp.Mult(CMTok, aProof.K)))
+	r.point("Challenge*C + T2 == J*B + L*H", 2,
+		zkpcp.Add(zkpcp.Mult(aProof.C, aProof.Challenge), aProof.T2),
+		zkpcp.Add(zkpcp.Mult(aProof.B, aProof.J), zkpcp.Mult(zkpcp.H, aProof.L)))
+	return r
+}
+
+// DiagnoseStatement diagnoses aProof against an ABCStatement, see Diagnose
+func (aProof *ABCProof) DiagnoseStatement(s Statement) (*VerificationReport, error) {
+	st, ok := s.(ABCStatement)
+	if !ok {
+		return nil, wrongStatement(ABCProofType, s)
 	}
-	proof.Challenge, err = ReadBigInt(buf)
-	if err != nil {
-		return nil, err
+	return aProof.Diagnose(st.Params, st.CM, st.CMTok), nil
+}
+
+// WriteTo writes the serialized representation of ABCProof aProof to w
+func (aProof *ABCProof) WriteTo(w io.Writer) (int64, error) {
+	pw := newProofWriter(w, proofCurve(aProof.curve), aProof.hash)
+	pw.point(aProof.B)
+	pw.point(aProof.C)
+	pw.point(aProof.T1)
+	pw.point(aProof.T2)
+	pw.scalar(aProof.Challenge)
+	pw.scalar(aProof.J)
+	pw.scalar(aProof.K)
+	pw.scalar(aProof.L)
+	pw.point(aProof.CToken)
+	if aProof.DisjuncAC == nil {
+		return pw.n, &ProofError{Type: ABCProofType, Op: "ABCProof.WriteTo", Err: ErrMalformedProof, Msg: "missing disjuncAC"}
 	}
-	proof.j, err = ReadBigInt(buf)
+	pw.proof(aProof.DisjuncAC)
+	return pw.done()
+}
+
+// ReadFrom reads one ABCProof written by WriteTo from r into aProof.  It reads no
+// further than the end of the proof, so proofs can be read back to back from
+// a stream; io.EOF is returned if r ends before the next proof starts.
+func (aProof *ABCProof) ReadFrom(r io.Reader) (int64, error) {
+	var p ABCProof
+	pr := newProofReader("ABCProof.ReadFrom", r)
+	p.curve, p.hash = pr.curve, pr.hash
+	p.B = pr.point("B")
+	p.C = pr.point("C")
+	p.T1 = pr.point("T1")
+	p.T2 = pr.point("T2")
+	p.Challenge = pr.scalar("Challenge")
+	p.J = pr.scalar("j")
+	p.K = pr.scalar("k")
+	p.L = pr.scalar("l")
+	p.CToken = pr.point("CToken")
+	p.DisjuncAC = new(DisjunctiveProof)
+	pr.proof("disjuncAC", p.DisjuncAC)
+	n, err := pr.done()
 	if err != nil {
-		return nil, err
+		return n, err
 	}
-	proof.k, err = ReadBigInt(buf)
-	if err != nil {
-		return nil, err
+	if p.DisjuncAC.curve != p.curve {
+		return n, &ProofError{Type: ABCProofType, Op: "ABCProof.ReadFrom", Err: ErrMalformedProof, Msg: fmt.Sprintf("disjunctive proof is over %s, not %s",
+			p.DisjuncAC.curve.Name(), p.curve.Name())}
 	}
-	proof.l, err = ReadBigInt(buf)
-	if err != nil {
-		return nil, err
+	if p.DisjuncAC.hash != p.hash {
+		return n, &ProofError{Type: ABCProofType, Op: "ABCProof.ReadFrom", Err: ErrMalformedProof, Msg: fmt.Sprintf("disjunctive proof is hashed with %v, not %v",
+			p.DisjuncAC.hash, p.hash)}
 	}
-	proof.CToken, err = ReadECPoint(buf)
-	if err != nil {
+	*aProof = p
+	return n, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (aProof *ABCProof) MarshalBinary() ([]byte, error) {
+	return marshalProof(aProof)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.  b must hold exactly
+// one proof.
+func (aProof *ABCProof) UnmarshalBinary(b []byte) error {
+	return unmarshalProof("ABCProof.UnmarshalBinary", b, aProof)
+}
+
+// Bytes returns a byte slice with a serialized representation of ABCProof aProof, or
+// nil if aProof can not be serialized
+func (aProof *ABCProof) Bytes() []byte {
+	b, _ := aProof.MarshalBinary()
+	return b
+}
+
+// NewABCProofFromBytes returns a ABCProof generated from the
+// deserialization of byte slice b
+func NewABCProofFromBytes(b []byte) (*ABCProof, error) {
+	proof := new(ABCProof)
+	if err := proof.UnmarshalBinary(b); err != nil {
 		return nil, err
 	}
-	disjuncBytes, err := wire.ReadVarBytes(buf, 100000, "disjunctProof")
-	if err != nil {
-		return nil, err
+	return proof, nil
+}
+
+// MarshalJSON encodes aProof as a JSON object in its group, see Group.Encode.  J, K,
+// L and DisjuncAC keep the lower case keys "j", "k", "l" and "disjuncAC".
+func (aProof *ABCProof) MarshalJSON() ([]byte, error) {
+	w := newJSONWriter(proofCurve(aProof.curve))
+	w.group()
+	w.hash(aProof.hash)
+	w.point("B", aProof.B)
+	w.point("C", aProof.C)
+	w.point("T1", aProof.T1)
+	w.point("T2", aProof.T2)
+	w.scalar("Challenge", aProof.Challenge)
+	w.scalar("j", aProof.J)
+	w.scalar("k", aProof.K)
+	w.scalar("l", aProof.L)
+	w.point("CToken", aProof.CToken)
+	w.value("disjuncAC", aProof.DisjuncAC)
+	return w.marshal()
+}
+
+// UnmarshalJSON decodes a proof written by MarshalJSON
+func (aProof *ABCProof) UnmarshalJSON(b []byte) error {
+	var p ABCProof
+	r := newJSONReader("ABCProof.UnmarshalJSON", b)
+	r.group()
+	p.hash = r.hash()
+	p.B = r.point("B")
+	p.C = r.point("C")
+	p.T1 = r.point("T1")
+	p.T2 = r.point("T2")
+	p.Challenge = r.scalar("Challenge")
+	p.J = r.scalar("j")
+	p.K = r.scalar("k")
+	p.L = r.scalar("l")
+	p.CToken = r.point("CToken")
+	r.value("disjuncAC", &p.DisjuncAC)
+	if err := r.done(); err != nil {
+		return err
 	}
-	proof.disjuncAC, err = NewDisjunctiveProofFromBytes(disjuncBytes)
-	if err != nil {
-		return nil, err
+	if p.DisjuncAC == nil {
+		return &ProofError{Type: ABCProofType, Op: "ABCProof.UnmarshalJSON", Err: ErrMalformedProof, Msg: "disjuncAC: missing"}
 	}
-	return proof, nil
+	if p.DisjuncAC.curve != r.curve {
+		return &ProofError{Type: ABCProofType, Op: "ABCProof.UnmarshalJSON", Err: ErrMalformedProof, Msg: fmt.Sprintf("disjunctive proof is over %s, not %s",
+			p.DisjuncAC.curve.Name(), r.curve.Name())}
+	}
+	if p.DisjuncAC.hash != p.hash {
+		return &ProofError{Type: ABCProofType, Op: "ABCProof.UnmarshalJSON", Err: ErrMalformedProof, Msg: fmt.Sprintf("disjunctive proof is hashed with %v, not %v",
+			p.DisjuncAC.hash, p.hash)}
+	}
+	p.curve = r.curve
+	*aProof = p
+	return nil
 }
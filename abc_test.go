@@ -8,55 +8,59 @@ import (
 
 // TestABCProof tests if the ABC Proof can generate and verify.
 func TestABCProof(t *testing.T) {
-	sk, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	forEachCurve(t, testABCProof)
+}
+
+func testABCProof(t *testing.T, zkpcp ZKPCurveParams) {
+	sk, _ := rand.Int(rand.Reader, zkpcp.C.Order())
 	value, _ := rand.Int(rand.Reader, big.NewInt(10000000000)) // "realistic rarnge"
-	ua, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	ua, _ := rand.Int(rand.Reader, zkpcp.C.Order())
 
-	PK := TestCurve.Mult(TestCurve.H, sk)
-	A := TestCurve.Mult(TestCurve.H, ua)       // uaH
-	temp := TestCurve.Mult(TestCurve.G, value) // value(G)
+	PK := zkpcp.Mult(zkpcp.H, sk)
+	A := zkpcp.Mult(zkpcp.H, ua)       // uaH
+	temp := zkpcp.Mult(zkpcp.G, value) // value(G)
 
 	// A = vG + uaH
-	A = TestCurve.Add(A, temp)
-	AToken := TestCurve.Mult(PK, ua)
+	A = zkpcp.Add(A, temp)
+	AToken := zkpcp.Mult(PK, ua)
 
-	aProof, status := NewABCProof(TestCurve, A, AToken, value, sk, Right)
+	aProof, status := NewABCProof(zkpcp, A, AToken, value, sk, Right)
 
 	if status != nil {
-		proofStatus(status.(*errorProof))
+		proofStatus(status.(*ProofError))
 		t.Logf("ABCProof RIGHT failed to generate!\n")
 		t.Fatalf("ABCProof RIGHT failed\n")
 	}
 
-	check, err := aProof.Verify(TestCurve, A, AToken)
+	check, err := aProof.Verify(zkpcp, A, AToken)
 	if !check || err != nil {
 		t.Logf("ABCProof RIGHT Failed to verify!\n")
 		t.Fatalf("ABCVerify RIGHT failed\n")
 	}
 
-	A = TestCurve.Mult(TestCurve.H, ua)
-	aProof, status = NewABCProof(TestCurve, A, AToken, big.NewInt(0), sk, Left)
+	A = zkpcp.Mult(zkpcp.H, ua)
+	aProof, status = NewABCProof(zkpcp, A, AToken, big.NewInt(0), sk, Left)
 
 	if status != nil {
-		proofStatus(status.(*errorProof))
+		proofStatus(status.(*ProofError))
 		t.Logf("ABCProof LEFT failed to generate!\n")
 		t.Fatalf("ABCProof LEFT failed\n")
 	}
 
-	check, err = aProof.Verify(TestCurve, A, AToken)
+	check, err = aProof.Verify(zkpcp, A, AToken)
 	if !check || err != nil {
 		t.Logf("ABCProof LEFT Failed to verify!\n")
 		t.Fatalf("ABCVerify LEFT failed\n")
 	}
 
-	A, ua, err = PedCommit(TestCurve, big.NewInt(1000))
+	A, ua, err = PedCommit(zkpcp, big.NewInt(1000))
 	if err != nil {
 		t.Fatalf("%v\n", err)
 	}
 
-	AToken = TestCurve.Mult(PK, ua)
+	AToken = zkpcp.Mult(PK, ua)
 
-	aProof, status = NewABCProof(TestCurve, A, AToken, big.NewInt(1001), sk, Right)
+	aProof, status = NewABCProof(zkpcp, A, AToken, big.NewInt(1001), sk, Right)
 
 	if status != nil {
 		t.Logf("False proof generation succeeded! (bad)\n")
@@ -65,7 +69,7 @@ func TestABCProof(t *testing.T) {
 
 	t.Logf("Next ABCVerify should catch false proof\n")
 
-	check, err = aProof.Verify(TestCurve, A, AToken)
+	check, err = aProof.Verify(zkpcp, A, AToken)
 	if check || err == nil {
 		t.Logf("ABCVerify: should have failed on false proof check!\n")
 		t.Fatalf("ABCVerify: not working...\n")
@@ -74,105 +78,156 @@ func TestABCProof(t *testing.T) {
 
 // TestABCProofSerialization tests if the ABC Proof can generate, serialize, deserialize, and then verify.
 func TestABCProofSerialization(t *testing.T) {
+	forEachCurve(t, testABCProofSerialization)
+}
 
-	sk, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+func testABCProofSerialization(t *testing.T, zkpcp ZKPCurveParams) {
+
+	sk, _ := rand.Int(rand.Reader, zkpcp.C.Order())
 	value, _ := rand.Int(rand.Reader, big.NewInt(10000000000)) // "realistic rarnge"
-	ua, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	ua, _ := rand.Int(rand.Reader, zkpcp.C.Order())
 
-	PK := TestCurve.Mult(TestCurve.H, sk)
-	A := TestCurve.Mult(TestCurve.H, ua)       // uaH
-	temp := TestCurve.Mult(TestCurve.G, value) // value(G)
+	PK := zkpcp.Mult(zkpcp.H, sk)
+	A := zkpcp.Mult(zkpcp.H, ua)       // uaH
+	temp := zkpcp.Mult(zkpcp.G, value) // value(G)
 
 	// A = vG + uaH
-	A = TestCurve.Add(A, temp)
-	AToken := TestCurve.Mult(PK, ua)
+	A = zkpcp.Add(A, temp)
+	AToken := zkpcp.Mult(PK, ua)
 
-	aProof, status := NewABCProof(TestCurve, A, AToken, value, sk, Right)
+	aProof, status := NewABCProof(zkpcp, A, AToken, value, sk, Right)
 
 	if status != nil {
-		proofStatus(status.(*errorProof))
+		proofStatus(status.(*ProofError))
 		t.Logf("ABCProof RIGHT failed to generate!\n")
 		t.Fatalf("ABCProof RIGHT failed\n")
 	}
 	aProof, status = NewABCProofFromBytes(aProof.Bytes())
 
 	if status != nil {
-		proofStatus(status.(*errorProof))
+		proofStatus(status.(*ProofError))
 		t.Fatalf("ABCProof failed to deserialize!\n")
 	}
 
-	check, err := aProof.Verify(TestCurve, A, AToken)
+	check, err := aProof.Verify(zkpcp, A, AToken)
 	if !check || err != nil {
 		t.Fatalf("ABCVerify failed: %s\n", err.Error())
 	}
 }
 
+// TestABCProofFields tests that an ABCProof rebuilt from its exported fields,
+// as a verifier outside the package would, still verifies.
+func TestABCProofFields(t *testing.T) {
+	forEachCurve(t, testABCProofFields)
+}
+
+func testABCProofFields(t *testing.T, zkpcp ZKPCurveParams) {
+	PK, sk := KeyGen(zkpcp.C, zkpcp.H)
+	value := big.NewInt(42)
+	CM, u, err := PedCommit(zkpcp, value)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	CMTok := zkpcp.Mult(PK, u)
+
+	aProof, err := NewABCProof(zkpcp, CM, CMTok, value, sk, Right)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	dj := aProof.DisjuncAC
+	rebuilt := &ABCProof{
+		B:         aProof.B,
+		C:         aProof.C,
+		T1:        aProof.T1,
+		T2:        aProof.T2,
+		Challenge: aProof.Challenge,
+		J:         aProof.J,
+		K:         aProof.K,
+		L:         aProof.L,
+		CToken:    aProof.CToken,
+		DisjuncAC: &DisjunctiveProof{T1: dj.T1, T2: dj.T2, C: dj.C, C1: dj.C1, C2: dj.C2, S1: dj.S1, S2: dj.S2},
+	}
+	check, err := rebuilt.VerifyStatement(ABCStatement{zkpcp, CM, CMTok})
+	if !check || err != nil {
+		t.Fatalf("rebuilt ABCProof should verify: %v\n", err)
+	}
+
+	rebuilt.DisjuncAC = nil
+	if check, err := rebuilt.Verify(zkpcp, CM, CMTok); check || err == nil {
+		t.Fatalf("ABCProof without DisjuncAC should not verify\n")
+	}
+}
+
 // TestBreakABCProve tests if the ABC Proof can will catch invalid proofs.
 func TestBreakABCProve(t *testing.T) {
-	sk, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	forEachCurve(t, testBreakABCProve)
+}
+
+func testBreakABCProve(t *testing.T, zkpcp ZKPCurveParams) {
+	sk, _ := rand.Int(rand.Reader, zkpcp.C.Order())
 	value, _ := rand.Int(rand.Reader, big.NewInt(10000000000)) // "realistic rarnge"
-	ua, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	ua, _ := rand.Int(rand.Reader, zkpcp.C.Order())
 
-	PK := TestCurve.Mult(TestCurve.H, sk)
-	CM := TestCurve.Mult(TestCurve.H, ua)      // uaH
-	temp := TestCurve.Mult(TestCurve.G, value) // value(G)
+	PK := zkpcp.Mult(zkpcp.H, sk)
+	CM := zkpcp.Mult(zkpcp.H, ua)      // uaH
+	temp := zkpcp.Mult(zkpcp.G, value) // value(G)
 
 	// A = vG + uaH
-	CM = TestCurve.Add(CM, temp)
-	CMTok := TestCurve.Mult(PK, ua)
+	CM = zkpcp.Add(CM, temp)
+	CMTok := zkpcp.Mult(PK, ua)
 
-	u1, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
-	u2, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
-	u3, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
-	ub, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
-	uc, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	u1, _ := rand.Int(rand.Reader, zkpcp.C.Order())
+	u2, _ := rand.Int(rand.Reader, zkpcp.C.Order())
+	u3, _ := rand.Int(rand.Reader, zkpcp.C.Order())
+	ub, _ := rand.Int(rand.Reader, zkpcp.C.Order())
+	uc, _ := rand.Int(rand.Reader, zkpcp.C.Order())
 
 	B := ECPoint{}
 	C := ECPoint{}
-	CToken := TestCurve.Mult(TestCurve.H, uc)
+	CToken := zkpcp.Mult(zkpcp.H, uc)
 
 	// B = 2/v
-	x := new(big.Int).ModInverse(value, TestCurve.C.Params().N)
-	B = PedCommitR(TestCurve, new(big.Int).Mul(big.NewInt(2), x), ub)
+	x := new(big.Int).ModInverse(value, zkpcp.C.Order())
+	B = PedCommitR(zkpcp, new(big.Int).Mul(big.NewInt(2), x), ub)
 
 	// C = 2G + ucH, the 2 here is the big deal
-	C = PedCommitR(TestCurve, big.NewInt(2), uc)
+	C = PedCommitR(zkpcp, big.NewInt(2), uc)
 
-	disjuncAC, _ := NewDisjunctiveProof(TestCurve, CMTok, CM, TestCurve.H, TestCurve.Sub(C, TestCurve.Mult(TestCurve.G, big.NewInt(2))), uc, Right)
+	disjuncAC, _ := NewDisjunctiveProof(zkpcp, CMTok, CM, zkpcp.H, zkpcp.Sub(C, zkpcp.Mult(zkpcp.G, big.NewInt(2))), uc, Right)
 
 	// CMTok is Ta for the rest of the proof
 	// T1 = u1G + u2Ta
 	// u1G
-	u1G := TestCurve.Mult(TestCurve.G, u1)
+	u1G := zkpcp.Mult(zkpcp.G, u1)
 	// u2Ta
-	u2Ta := TestCurve.Mult(CMTok, u2)
+	u2Ta := zkpcp.Mult(CMTok, u2)
 	// Sum the above two
-	T1X, T1Y := TestCurve.C.Add(u1G.X, u1G.Y, u2Ta.X, u2Ta.Y)
+	T1 := zkpcp.Add(u1G, u2Ta)
 
 	// T2 = u1B + u3H
 	// u1B
-	u1B := TestCurve.Mult(B, u1)
+	u1B := zkpcp.Mult(B, u1)
 	// u3H
-	u3H := TestCurve.Mult(TestCurve.H, u3)
+	u3H := zkpcp.Mult(zkpcp.H, u3)
 	// Sum of the above two
-	T2X, T2Y := TestCurve.C.Add(u1B.X, u1B.Y, u3H.X, u3H.Y)
+	T2 := zkpcp.Add(u1B, u3H)
 
 	// c = HASH(G,H,CM,CMTok,B,C,T1,T2)
-	Challenge := GenerateChallenge(TestCurve, TestCurve.G.Bytes(), TestCurve.H.Bytes(),
-		CM.Bytes(), CMTok.Bytes(),
-		B.Bytes(), C.Bytes(),
-		T1X.Bytes(), T1Y.Bytes(),
-		T2X.Bytes(), T2Y.Bytes())
+	Challenge := GenerateChallenge(zkpcp, zkpcp.Bytes(zkpcp.G), zkpcp.Bytes(zkpcp.H),
+		zkpcp.Bytes(CM), zkpcp.Bytes(CMTok),
+		zkpcp.Bytes(B), zkpcp.Bytes(C),
+		zkpcp.Bytes(T1), zkpcp.Bytes(T2))
 
 	// j = u1 + v * c , can be though of as s1
 	j := new(big.Int).Add(u1, new(big.Int).Mul(value, Challenge))
-	j = new(big.Int).Mod(j, TestCurve.C.Params().N)
+	j = new(big.Int).Mod(j, zkpcp.C.Order())
 
 	// k = u2 + inv(sk) * c
 	// inv(sk)
-	isk := new(big.Int).ModInverse(sk, TestCurve.C.Params().N)
+	isk := new(big.Int).ModInverse(sk, zkpcp.C.Order())
 	k := new(big.Int).Add(u2, new(big.Int).Mul(isk, Challenge))
-	k = new(big.Int).Mod(k, TestCurve.C.Params().N)
+	k = new(big.Int).Mod(k, zkpcp.C.Order())
 
 	// l = u3 + (uc - v * ub) * c
 	temp1 := new(big.Int).Sub(uc, new(big.Int).Mul(value, ub))
@@ -181,16 +236,18 @@ func TestBreakABCProve(t *testing.T) {
 	evilProof := &ABCProof{
 		B,
 		C,
-		ECPoint{T1X, T1Y},
-		ECPoint{T2X, T2Y},
+		T1,
+		T2,
 		Challenge,
 		j, k, l, CToken,
-		disjuncAC}
+		disjuncAC,
+		zkpcp.C,
+		zkpcp.Hash}
 
 	t.Logf("Attempting to pass malicious true proof into verification function\n")
 	t.Logf("This test should throw a couple error messages in debug\n")
 
-	check, err := evilProof.Verify(TestCurve, CM, CMTok)
+	check, err := evilProof.Verify(zkpcp, CM, CMTok)
 	if check || err == nil {
 		t.Logf("ABCVerify - EVIL: accepted attack input! c = 2, should fail...\n")
 		t.Fatalf("ABCVerify - EVIL: failed to catch attack!\n")
@@ -200,7 +257,7 @@ func TestBreakABCProve(t *testing.T) {
 func BenchmarkABCProve_0(b *testing.B) {
 	value := big.NewInt(0)
 
-	sk, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	sk, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 	PK := TestCurve.Mult(TestCurve.H, sk)
 
 	CM, randVal, err := PedCommit(TestCurve, value)
@@ -217,9 +274,9 @@ func BenchmarkABCProve_0(b *testing.B) {
 }
 
 func BenchmarkABCProve_1(b *testing.B) {
-	value, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	value, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 
-	sk, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	sk, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 	PK := TestCurve.Mult(TestCurve.H, sk)
 
 	CM, randVal, err := PedCommit(TestCurve, value)
@@ -238,7 +295,7 @@ func BenchmarkABCProve_1(b *testing.B) {
 func BenchmarkABCVerify_0(b *testing.B) {
 	value := big.NewInt(0)
 
-	sk, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	sk, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 	PK := TestCurve.Mult(TestCurve.H, sk)
 
 	CM, randVal, err := PedCommit(TestCurve, value)
@@ -255,9 +312,9 @@ func BenchmarkABCVerify_0(b *testing.B) {
 }
 
 func BenchmarkABCVerify_1(b *testing.B) {
-	value, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	value, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 
-	sk, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	sk, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 	PK := TestCurve.Mult(TestCurve.H, sk)
 
 	CM, randVal, err := PedCommit(TestCurve, value)
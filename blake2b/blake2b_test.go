@@ -0,0 +1,30 @@
+package blake2b
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSum512(t *testing.T) {
+	long := bytes.Repeat([]byte{0}, 768)
+	for i := range long {
+		long[i] = byte(i)
+	}
+	tests := []struct {
+		in   []byte
+		want string
+	}{
+		{nil, "786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419d25e1031afee585313896444934eb04b903a685b1448b755d56f701afe9be2ce"},
+		{[]byte("abc"), "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"},
+		{long, "323e97a7a859ee63c9013debb0ca995811e73117a2f574723416e596ebc184e37a59b66d2f597df4a7c1b0d1d41a1a7f28774f46a6864d56c57b9d6c5f7302fb"},
+		{bytes.Repeat([]byte("x"), BlockSize), "082b91ea2e15d1556d2ceefdd5af5d64d31b4e01aff1959724578876293825b236ee8079173a0a38160d7d6685d6bca0bfb62c177b3599b8727d9173e2115b91"},
+		{bytes.Repeat([]byte("x"), BlockSize+1), "362a53bbe2ec08097b2f358a41d0e153aeed4c132af928400872413650e7bf22f9ae428ff73770170bbd95f935e5dd1953c17de8c7264c72d1f99303bf22dfaa"},
+	}
+	for _, test := range tests {
+		got := Sum512(test.in)
+		if hex.EncodeToString(got[:]) != test.want {
+			t.Fatalf("Sum512 of %d bytes: expected %s, got %x\n", len(test.in), test.want, got)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package btcec
+
+import (
+	"crypto/subtle"
+	"math/big"
+)
+
+// References:
+//   [RCB]: Complete addition formulas for prime order elliptic curves
+//     (Renes, Costello, Batina), https://eprint.iacr.org/2015/1060
+
+// ScalarMultConstantTime is ScalarMult for secret k: its running time and
+// memory accesses do not depend on k.  It is several times slower.
+//
+// ScalarMult and ScalarBaseMult use NAF, the endomorphism split of k and
+// table lookups indexed by k, and their Jacobian addition branches on its
+// inputs.  This instead uses a fixed 4-bit window over all 256 bits of k,
+// reads every table entry for each window, and adds with the complete
+// projective formulas of [RCB], which need no special cases for doubling or
+// for the point at infinity.
+func (curve *KoblitzCurve) ScalarMultConstantTime(Bx, By *big.Int, k *[32]byte) (*big.Int, *big.Int) {
+	// table[i] = i * B, table[0] is the point at infinity (0:1:0).
+	var table [16]projectivePoint
+	table[0].y.SetInt(1)
+	if Bx.Sign() != 0 || By.Sign() != 0 {
+		fx, fy := curve.bigAffineToField(Bx, By)
+		table[1] = projectivePoint{*fx, *fy, *new(fieldVal).SetInt(1)}
+	} else {
+		table[1] = table[0]
+	}
+	for i := 2; i < len(table); i++ {
+		table[i].add(&table[i-1], &table[1])
+	}
+
+	var q, t projectivePoint
+	q.y.SetInt(1)
+	for i := 0; i < 64; i++ {
+		for j := 0; j < 4; j++ {
+			q.add(&q, &q)
+		}
+		w := k[i/2] >> 4
+		if i%2 == 1 {
+			w = k[i/2] & 0x0f
+		}
+		t.lookup(&table, w)
+		q.add(&q, &t)
+	}
+
+	if q.z.Normalize().IsZero() {
+		return new(big.Int), new(big.Int)
+	}
+	var zInv fieldVal
+	zInv.Set(&q.z).Inverse()
+	q.x.Mul(&zInv).Normalize()
+	q.y.Mul(&zInv).Normalize()
+	return new(big.Int).SetBytes(q.x.Bytes()[:]), new(big.Int).SetBytes(q.y.Bytes()[:])
+}
+
+// projectivePoint is a point in homogeneous projective coordinates, with
+// x = X/Z and y = Y/Z.  The fields are kept normalized.
+type projectivePoint struct {
+	x, y, z fieldVal
+}
+
+// lookup sets p to table[idx], reading every entry of table.
+func (p *projectivePoint) lookup(table *[16]projectivePoint, idx byte) {
+	*p = projectivePoint{}
+	for i := range table {
+		mask := uint32(-subtle.ConstantTimeByteEq(byte(i), idx))
+		for j := range p.x.n {
+			p.x.n[j] |= table[i].x.n[j] & mask
+			p.y.n[j] |= table[i].y.n[j] & mask
+			p.z.n[j] |= table[i].z.n[j] & mask
+		}
+	}
+}
+
+// curveB3 is 3 * b for the curve equation y^2 = x^3 + 7.
+const curveB3 = 21
+
+// add sets p to p1 + p2 with Algorithm 7 of [RCB], the complete addition
+// formula for a = 0.  p may alias p1 or p2.
+func (p *projectivePoint) add(p1, p2 *projectivePoint) {
+	var t0, t1, t2, t3, t4, x3, y3, z3 fieldVal
+	mul := func(r, a, b *fieldVal) { r.Mul2(a, b).Normalize() }
+	add := func(r, a, b *fieldVal) { r.Add2(a, b).Normalize() }
+	sub := func(r, a, b *fieldVal) {
+		var nb fieldVal
+		nb.NegateVal(b, 1)
+		r.Add2(a, &nb).Normalize()
+	}
+	mulB3 := func(r, a *fieldVal) { r.Set(a).MulInt(curveB3).Normalize() }
+
+	mul(&t0, &p1.x, &p2.x)
+	mul(&t1, &p1.y, &p2.y)
+	mul(&t2, &p1.z, &p2.z)
+	add(&t3, &p1.x, &p1.y)
+	add(&t4, &p2.x, &p2.y)
+	mul(&t3, &t3, &t4)
+	add(&t4, &t0, &t1)
+	sub(&t3, &t3, &t4)
+	add(&t4, &p1.y, &p1.z)
+	add(&x3, &p2.y, &p2.z)
+	mul(&t4, &t4, &x3)
+	add(&x3, &t1, &t2)
+	sub(&t4, &t4, &x3)
+	add(&x3, &p1.x, &p1.z)
+	add(&y3, &p2.x, &p2.z)
+	mul(&x3, &x3, &y3)
+	add(&y3, &t0, &t2)
+	sub(&y3, &x3, &y3)
+	add(&x3, &t0, &t0)
+	add(&t0, &x3, &t0)
+	mulB3(&t2, &t2)
+	add(&z3, &t1, &t2)
+	sub(&t1, &t1, &t2)
+	mulB3(&y3, &y3)
+	mul(&x3, &t4, &y3)
+	mul(&t2, &t3, &t1)
+	sub(&x3, &t2, &x3)
+	mul(&y3, &y3, &t0)
+	mul(&t1, &t1, &z3)
+	add(&y3, &t1, &y3)
+	mul(&t0, &t0, &t3)
+	mul(&z3, &z3, &t4)
+	add(&z3, &z3, &t0)
+
+	p.x, p.y, p.z = x3, y3, z3
+}
@@ -454,10 +454,40 @@ func nonceRFC6979(privkey *big.Int, hash []byte) *big.Int {
 	alg := sha256.New
 
 	qlen := q.BitLen()
-	holen := alg().Size()
 	rolen := (qlen + 7) >> 3
 	bx := append(int2octets(x, rolen), bits2octets(hash, curve, rolen)...)
 
+	// Steps B to G
+	drbg := NewHMACDRBG(alg, bx)
+
+	// Step H
+	for {
+		// Steps H1 and H2
+		t := drbg.Generate(qlen)
+
+		// Step H3
+		secret := hashToInt(t, curve)
+		if secret.Cmp(one) >= 0 && secret.Cmp(q) < 0 {
+			return secret
+		}
+		drbg.Update()
+	}
+}
+
+// HMACDRBG is the HMAC based generator RFC 6979 derives nonces from, split
+// out of nonceRFC6979 so that other deterministic nonce schemes can use it.
+type HMACDRBG struct {
+	alg func() hash.Hash
+	k   []byte
+	v   []byte
+}
+
+// NewHMACDRBG runs steps B to G of RFC 6979 section 3.2, with seed in place
+// of int2octets(x) || bits2octets(h1).  Seeds longer than that are fine,
+// e.g. with extra entropy appended as in section 3.6.
+func NewHMACDRBG(alg func() hash.Hash, seed []byte) *HMACDRBG {
+	holen := alg().Size()
+
 	// Step B
 	v := bytes.Repeat(oneInitializer, holen)
 
@@ -465,35 +495,45 @@ func nonceRFC6979(privkey *big.Int, hash []byte) *big.Int {
 	k := make([]byte, holen)
 
 	// Step D
-	k = mac(alg, k, append(append(v, 0x00), bx...))
+	k = mac(alg, k, append(append(v, 0x00), seed...))
 
 	// Step E
 	v = mac(alg, k, v)
 
 	// Step F
-	k = mac(alg, k, append(append(v, 0x01), bx...))
+	k = mac(alg, k, append(append(v, 0x01), seed...))
 
 	// Step G
 	v = mac(alg, k, v)
 
-	// Step H
-	for {
-		// Step H1
-		var t []byte
+	return &HMACDRBG{alg, k, v}
+}
 
-		// Step H2
-		for len(t)*8 < qlen {
-			v = mac(alg, k, v)
-			t = append(t, v...)
-		}
+// Generate returns the next candidate T of at least qlen bits (steps H1 and
+// H2).  The caller turns T into a nonce with bits2int and checks its range.
+func (d *HMACDRBG) Generate(qlen int) []byte {
+	var t []byte
+	for len(t)*8 < qlen {
+		d.v = mac(d.alg, d.k, d.v)
+		t = append(t, d.v...)
+	}
+	return t
+}
 
-		// Step H3
-		secret := hashToInt(t, curve)
-		if secret.Cmp(one) >= 0 && secret.Cmp(q) < 0 {
-			return secret
-		}
-		k = mac(alg, k, append(v, 0x00))
-		v = mac(alg, k, v)
+// Update rekeys the generator as in step H3.  Call it after rejecting a
+// candidate and before generating another nonce.
+func (d *HMACDRBG) Update() {
+	d.k = mac(d.alg, d.k, append(d.v, 0x00))
+	d.v = mac(d.alg, d.k, d.v)
+}
+
+// Destroy overwrites the state of d with zeros.  d must not be used after.
+func (d *HMACDRBG) Destroy() {
+	for i := range d.k {
+		d.k[i] = 0
+	}
+	for i := range d.v {
+		d.v[i] = 0
 	}
 }
 
@@ -0,0 +1,93 @@
+package zksigma
+
+import (
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"fmt"
+
+	"github.com/mit-dci/zksigma/blake2b"
+)
+
+// ChallengeHash is the hash function Fiat-Shamir challenges are computed with,
+// chosen by ZKPCurveParams.Hash.  A proof records the hash it was made with,
+// and its binary and JSON encodings carry it next to the group.
+//
+// Every hash gives challengeHashSize bytes of output over the transcript, see
+// wide, which GenerateChallenge reduces modulo the group order with a bias of
+// at most 2^-256 and compact proofs take the first 16 bytes of.  SHAKE256 is
+// read to 512 bits and BLAKE2b-512 is used as is.  The 256-bit hashes are run
+// in counter mode, H(0x00 || transcript) || H(0x01 || transcript), so they are
+// not the plain digests of the transcript; their names end in "-wide" to say
+// so.  This construction is part of the proof format.
+type ChallengeHash byte
+
+// The challenge hashes, to match the transcript hashing of other systems.
+// HashSHAKE256, the zero value, is the default.
+const (
+	HashSHAKE256   ChallengeHash = iota // SHAKE256 with 512 bits of output
+	HashSHA256                          // SHA-256 in counter mode, 512 bits
+	HashSHA512_256                      // SHA-512/256 in counter mode, 512 bits
+	HashSHA3_256                        // SHA3-256 in counter mode, 512 bits
+	HashBLAKE2b                         // BLAKE2b-512
+)
+
+var challengeHashNames = map[ChallengeHash]string{
+	HashSHAKE256:   "SHAKE256",
+	HashSHA256:     "SHA-256-wide",
+	HashSHA512_256: "SHA-512/256-wide",
+	HashSHA3_256:   "SHA3-256-wide",
+	HashBLAKE2b:    "BLAKE2b-512",
+}
+
+func (h ChallengeHash) String() string {
+	if name, ok := challengeHashNames[h]; ok {
+		return name
+	}
+	return fmt.Sprintf("ChallengeHash(%d)", byte(h))
+}
+
+// ParseChallengeHash returns the ChallengeHash whose String is name.
+func ParseChallengeHash(name string) (ChallengeHash, error) {
+	for h, n := range challengeHashNames {
+		if n == name {
+			return h, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown challenge hash %q", name)
+}
+
+// Valid reports whether h is one of the hashes above.
+func (h ChallengeHash) Valid() bool {
+	_, ok := challengeHashNames[h]
+	return ok
+}
+
+// wide returns challengeHashSize bytes of h over data.  Hashes with 256 bits
+// of output are run twice, over 0x00 || data and 0x01 || data, so that every
+// hash gives challenges within 2^-256 of uniform, see GenerateChallenge.
+func (h ChallengeHash) wide(data []byte) []byte {
+	var sum256 func([]byte) [32]byte
+	switch h {
+	case HashSHAKE256:
+		return sha3.SumSHAKE256(data, challengeHashSize)
+	case HashBLAKE2b:
+		out := blake2b.Sum512(data)
+		return out[:]
+	case HashSHA256:
+		sum256 = sha256.Sum256
+	case HashSHA512_256:
+		sum256 = sha512.Sum512_256
+	case HashSHA3_256:
+		sum256 = sha3.Sum256
+	default:
+		panic("zksigma: unknown challenge hash " + h.String())
+	}
+
+	out := make([]byte, 0, challengeHashSize)
+	for ctr := byte(0); len(out) < challengeHashSize; ctr++ {
+		block := sum256(append([]byte{ctr}, data...))
+		out = append(out, block[:]...)
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+package zksigma
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+var challengeHashes = []ChallengeHash{HashSHAKE256, HashSHA256, HashSHA512_256, HashSHA3_256, HashBLAKE2b}
+
+func TestChallengeHash(t *testing.T) {
+	forEachCurve(t, testChallengeHash)
+}
+
+func testChallengeHash(t *testing.T, zkpcp ZKPCurveParams) {
+	for _, h := range challengeHashes {
+		hzkpcp := zkpcp.WithHash(h)
+		other := zkpcp.WithHash((h + 1) % ChallengeHash(len(challengeHashes)))
+		for _, ps := range sampleProofs(t, hzkpcp) {
+			if got := recordedHash(ps.proof); got != h {
+				t.Fatalf("%v: made with %v, records %v\n", ps.proof.Type(), h, got)
+			}
+			if ok, err := ps.proof.VerifyStatement(ps.st); !ok || err != nil {
+				t.Fatalf("%v with %v: %v\n", ps.proof.Type(), h, err)
+			}
+
+			// Every encoding carries the hash, so a decoded proof verifies
+			// under the parameters it was made with.
+			p, err := DecodeProof(EncodeProof(ps.proof))
+			if err != nil || recordedHash(p) != h {
+				t.Fatalf("%v: encoded with %v, decoded %v, %v\n", ps.proof.Type(), h, recordedHash(p), err)
+			}
+			if ok, err := p.VerifyStatement(ps.st); !ok || err != nil {
+				t.Fatalf("%v: decoded proof made with %v should verify: %v\n", p.Type(), h, err)
+			}
+			var buf bytes.Buffer
+			if _, err := WriteProof(&buf, ps.proof); err != nil {
+				t.Fatalf("%v: %v\n", ps.proof.Type(), err)
+			}
+			if p, err = ReadProof(&buf); err != nil || recordedHash(p) != h {
+				t.Fatalf("%v: read back %v, %v\n", ps.proof.Type(), recordedHash(p), err)
+			}
+			p = reflect.New(reflect.TypeOf(ps.proof).Elem()).Interface().(Proof)
+			if err := p.UnmarshalBinary(ps.proof.Bytes()); err != nil || recordedHash(p) != h {
+				t.Fatalf("%v: unmarshaled %v, %v\n", ps.proof.Type(), recordedHash(p), err)
+			}
+			p = reflect.New(reflect.TypeOf(ps.proof).Elem()).Interface().(Proof)
+			b, err := json.Marshal(ps.proof)
+			if err == nil {
+				err = json.Unmarshal(b, p)
+			}
+			if err != nil || recordedHash(p) != h {
+				t.Fatalf("%v: JSON decoded %v, %v\n", ps.proof.Type(), recordedHash(p), err)
+			}
+
+			// Verifiers using another hash say so instead of failing on
+			// the challenge.
+			ok, err := p.VerifyStatement(withCurveParams(ps.st, other))
+			if ok || !errors.Is(err, ErrHashMismatch) {
+				t.Fatalf("%v: made with %v, verified with %v: %v\n", p.Type(), h, other.Hash, err)
+			}
+			report, err := p.DiagnoseStatement(withCurveParams(ps.st, other))
+			if err != nil || report.OK() {
+				t.Fatalf("%v: made with %v, diagnosed with %v: %v\n", p.Type(), h, other.Hash, err)
+			}
+		}
+	}
+}
+
+// recordedHash returns the hash p records.
+func recordedHash(p Proof) ChallengeHash {
+	return p.(interface{ ChallengeHash() ChallengeHash }).ChallengeHash()
+}
+
+func TestProofWithUnknownHash(t *testing.T) {
+	x := big.NewInt(7)
+	proof, _ := NewGSPFSProof(TestCurve, TestCurve.Mult(TestCurve.G, x), x)
+	proof.hash = 0xff
+	if _, err := proof.MarshalBinary(); err == nil {
+		t.Fatalf("a proof with an unknown hash should not encode\n")
+	}
+	if _, err := json.Marshal(proof); err == nil {
+		t.Fatalf("a proof with an unknown hash should not encode to JSON\n")
+	}
+
+	proof.hash = HashSHA256
+	b, _ := json.Marshal(proof)
+	var fields map[string]interface{}
+	json.Unmarshal(b, &fields)
+	fields["hash"] = "SHA-1"
+	b, _ = json.Marshal(fields)
+	if err := json.Unmarshal(b, new(GSPFSProof)); !errors.Is(err, ErrMalformedProof) {
+		t.Fatalf("an unknown hash name should not decode: %v\n", err)
+	}
+}
+
+func TestChallengeHashWide(t *testing.T) {
+	data := []byte("zksigma")
+	b0 := sha256.Sum256(append([]byte{0}, data...))
+	b1 := sha256.Sum256(append([]byte{1}, data...))
+	want := append(b0[:], b1[:]...)
+	if got := HashSHA256.wide(data); !bytes.Equal(got, want) {
+		t.Fatalf("SHA-256 should run in counter mode, got %x\n", got)
+	}
+	N := TestCurve.C.Order()
+	wantC := new(big.Int).Mod(new(big.Int).SetBytes(want), N)
+	if c := GenerateChallenge(TestCurve.WithHash(HashSHA256), data); c.Cmp(wantC) != 0 {
+		t.Fatalf("SHA-256 challenge should be the counter mode output mod N, got %v\n", c)
+	}
+	for _, h := range challengeHashes {
+		if len(h.wide(data)) != challengeHashSize {
+			t.Fatalf("%v gives %d bytes\n", h, len(h.wide(data)))
+		}
+	}
+	if HashSHA256.String() != "SHA-256-wide" {
+		t.Fatalf("unexpected name %q\n", HashSHA256.String())
+	}
+	if ChallengeHash(0xff).Valid() || ChallengeHash(0xff).String() != "ChallengeHash(255)" || HashBLAKE2b.String() != "BLAKE2b-512" {
+		t.Fatalf("unexpected ChallengeHash names\n")
+	}
+}
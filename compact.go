@@ -0,0 +1,1057 @@
+package zksigma
+
+import (
+	"io"
+	"math/big"
+)
+
+// Compact proofs are the GSPFS, Equivalence, Disjunctive and Consistency
+// proofs with 128-bit challenges, encoded as just their challenges and
+// responses.  The verifier recomputes the prover's commitments from the
+// statement, the challenge and the responses and checks that they hash to
+// the challenge, so no commitment has to be sent:
+//
+//	T1 = u1G                            c, s1 ------------------------->
+//	c = HASH128(..., T1)                T1' = s1G - cA
+//	s1 = u1 + c * x                     c ?= HASH128(..., T1')
+//
+// A 128-bit challenge keeps the soundness error at 2^-128, the security level
+// of the 256-bit groups, while the responses stay full scalars.  A compact
+// proof of a single secret is one scalar and 16 bytes, against two points and
+// two scalars for a GSPFSProof.
+//
+// Since every equation is folded into the challenge, a wrong response shows
+// up as ErrChallengeMismatch rather than ErrEquationFailed.
+
+// compactChallengeSize is the size of the challenges of compact proofs.
+const compactChallengeSize = 16
+
+// compactChallengeMod is 2^128, the modulus the challenges of a
+// CompactDisjunctiveProof add up under.
+var compactChallengeMod = new(big.Int).Lsh(big.NewInt(1), 8*compactChallengeSize)
+
+// compactChallenge is the 128-bit challenge of a compact proof: the first
+// compactChallengeSize bytes of zkpcp.Hash over the encodings of points.
+func compactChallenge(zkpcp ZKPCurveParams, points ...ECPoint) *big.Int {
+	var b []byte
+	for _, p := range points {
+		b = append(b, zkpcp.Bytes(p)...)
+	}
+	return new(big.Int).SetBytes(zkpcp.Hash.wide(b)[:compactChallengeSize])
+}
+
+// ============ GSPFS ==================
+
+// CompactGSPFSProof is a GSPFSProof with a 128-bit challenge that carries
+// neither its base point nor its commitment.
+//
+//	Prover                              Verifier
+//	======                              ========
+//	know x
+//	A = xBase                           learns Base, A
+//	selects random u
+//	T = uBase
+//	c = HASH128(Base, A, T)
+//	s = u - c * x
+//
+//	c, s ------------------------------>
+//	                                    T' = sBase + cA
+//	                                    c ?= HASH128(Base, A, T')
+type CompactGSPFSProof struct {
+	Challenge   *big.Int      // c, below 2^128
+	HiddenValue *big.Int      // s = u - c * x
+	curve       Group         // group the proof was computed in
+	hash        ChallengeHash // hash the challenges were computed with
+}
+
+// NewCompactGSPFSProof generates a compact Schnorr proof for the value x using
+// the first ZKCurve base point.  It checks if the passed A is indeed value x
+// multiplied by the generator point.
+func NewCompactGSPFSProof(zkpcp ZKPCurveParams, A ECPoint, x *big.Int) (*CompactGSPFSProof, error) {
+	return NewCompactGSPFSProofBase(zkpcp, zkpcp.G, A, x)
+}
+
+// NewCompactGSPFSProofBase is the same as NewCompactGSPFSProof, except it
+// allows you to specify your own base point.  The verifier must be given the
+// same base, see CompactGSPFSStatement.
+func NewCompactGSPFSProofBase(zkpcp ZKPCurveParams, base, A ECPoint, x *big.Int) (*CompactGSPFSProof, error) {
+	in := zkpcp.validateInputs(CompactGSPFSProofType, "NewCompactGSPFSProof")
+	in.point("base", base)
+	in.point("A", A)
+	if err := in.done(); err != nil {
+		return nil, err
+	}
+
+	modValue := zkpcp.C.ReduceScalar(x)
+	defer WipeScalars(modValue)
+
+	if !zkpcp.MultSecret(base, modValue).Equal(A) {
+		return nil, zkpcp.traceError(&ProofError{Type: CompactGSPFSProofType, Op: "NewCompactGSPFSProof", Err: ErrInvalidWitness, Msg: "the point given is not xG"})
+	}
+
+	nonces, err := zkpcp.newNonceSource(CompactGSPFSProofType, []*big.Int{x}, base, A)
+	if err != nil {
+		return nil, err
+	}
+	defer nonces.destroy()
+	u, err := nonces.scalar()
+	if err != nil {
+		return nil, err
+	}
+
+	T := zkpcp.MultSecret(base, u)
+	c := compactChallenge(zkpcp, base, A, T)
+	zkpcp.trace(CompactGSPFSProofType, "prover commitment", "T", zkpcp.logPoint(T), "challenge", c)
+
+	// s = u - c * x
+	s := zkpcp.C.SubScalars(u, zkpcp.C.MulScalars(c, modValue))
+
+	return &CompactGSPFSProof{c, s, zkpcp.C, zkpcp.Hash}, nil
+}
+
+// commitment recomputes T = s * Base + c * A.
+func (proof *CompactGSPFSProof) commitment(zkpcp ZKPCurveParams, base, A ECPoint) ECPoint {
+	return zkpcp.Add(zkpcp.Mult(base, proof.HiddenValue), zkpcp.Mult(A, proof.Challenge))
+}
+
+// Verify checks if CompactGSPFSProof proof is a valid proof that A = x * base
+func (proof *CompactGSPFSProof) Verify(zkpcp ZKPCurveParams, base, A ECPoint) (bool, error) {
+	if proof == nil {
+		return false, zkpcp.traceError(&ProofError{Type: CompactGSPFSProofType, Op: "CompactGSPFSProof.Verify", Err: ErrMalformedProof, Msg: "passed proof is nil"})
+	}
+
+	v := zkpcp.validateInputs(CompactGSPFSProofType, "CompactGSPFSProof.Verify")
+	v.hash(proof.hash)
+	v.point("Base", base)
+	v.point("A", A)
+	v.challenge("Challenge", proof.Challenge)
+	v.scalar("HiddenValue", proof.HiddenValue)
+	if err := v.done(); err != nil {
+		return false, err
+	}
+
+	c := compactChallenge(zkpcp, base, A, proof.commitment(zkpcp, base, A))
+	if c.Cmp(proof.Challenge) != 0 {
+		return false, zkpcp.traceError(&ProofError{Type: CompactGSPFSProofType, Op: "CompactGSPFSProof.Verify", Err: ErrChallengeMismatch, Msg: "calculated challenge and proof's challenge do not agree!"})
+	}
+	zkpcp.trace(CompactGSPFSProofType, "verifier challenge", "challenge", c)
+	zkpcp.trace(CompactGSPFSProofType, "verified")
+	return true, nil
+}
+
+// ChallengeHash returns the hash the challenges of proof were computed with
+func (proof *CompactGSPFSProof) ChallengeHash() ChallengeHash {
+	return proof.hash
+}
+
+// Type returns CompactGSPFSProofType
+func (proof *CompactGSPFSProof) Type() ProofType {
+	return CompactGSPFSProofType
+}
+
+// VerifyStatement checks proof against a CompactGSPFSStatement, see Verify
+func (proof *CompactGSPFSProof) VerifyStatement(s Statement) (bool, error) {
+	st, ok := s.(CompactGSPFSStatement)
+	if !ok {
+		return false, wrongStatement(CompactGSPFSProofType, s)
+	}
+	return proof.Verify(st.Params, st.Base, st.A)
+}
+
+// Diagnose runs every check of Verify and reports the outcome of each, see
+// VerificationReport
+func (proof *CompactGSPFSProof) Diagnose(zkpcp ZKPCurveParams, base, A ECPoint) *VerificationReport {
+	r := newReport(CompactGSPFSProofType, zkpcp)
+	if proof == nil {
+		return r.malformed()
+	}
+	if !r.wellFormed([]ECPoint{base, A}, []*big.Int{proof.HiddenValue}, proof.Challenge) {
+		return r
+	}
+	r.hash(proof.hash)
+
+	c := compactChallenge(zkpcp, base, A, proof.commitment(zkpcp, base, A))
+	r.scalar("HASH128(Base, A, HiddenValue*Base + Challenge*A) == Challenge", c, proof.Challenge)
+	return r
+}
+
+// DiagnoseStatement diagnoses proof against a CompactGSPFSStatement, see
+// Diagnose
+func (proof *CompactGSPFSProof) DiagnoseStatement(s Statement) (*VerificationReport, error) {
+	st, ok := s.(CompactGSPFSStatement)
+	if !ok {
+		return nil, wrongStatement(CompactGSPFSProofType, s)
+	}
+	return proof.Diagnose(st.Params, st.Base, st.A), nil
+}
+
+// WriteTo writes the serialized representation of CompactGSPFSProof proof to w
+func (proof *CompactGSPFSProof) WriteTo(w io.Writer) (int64, error) {
+	pw := newProofWriter(w, proofCurve(proof.curve), proof.hash)
+	pw.challenge(proof.Challenge)
+	pw.scalar(proof.HiddenValue)
+	return pw.done()
+}
+
+// ReadFrom reads one CompactGSPFSProof written by WriteTo from r into proof,
+// see GSPFSProof.ReadFrom.
+func (proof *CompactGSPFSProof) ReadFrom(r io.Reader) (int64, error) {
+	var p CompactGSPFSProof
+	pr := newProofReader("CompactGSPFSProof.ReadFrom", r)
+	p.curve, p.hash = pr.curve, pr.hash
+	p.Challenge = pr.challenge("Challenge")
+	p.HiddenValue = pr.scalar("HiddenValue")
+	n, err := pr.done()
+	if err != nil {
+		return n, err
+	}
+	*proof = p
+	return n, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (proof *CompactGSPFSProof) MarshalBinary() ([]byte, error) {
+	return marshalProof(proof)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.  b must hold exactly
+// one proof.
+func (proof *CompactGSPFSProof) UnmarshalBinary(b []byte) error {
+	return unmarshalProof("CompactGSPFSProof.UnmarshalBinary", b, proof)
+}
+
+// Bytes returns a byte slice with a serialized representation of
+// CompactGSPFSProof proof, or nil if proof can not be serialized
+func (proof *CompactGSPFSProof) Bytes() []byte {
+	b, _ := proof.MarshalBinary()
+	return b
+}
+
+// NewCompactGSPFSProofFromBytes returns a CompactGSPFSProof generated from the
+// deserialization of byte slice b
+func NewCompactGSPFSProofFromBytes(b []byte) (*CompactGSPFSProof, error) {
+	proof := new(CompactGSPFSProof)
+	if err := proof.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// MarshalJSON encodes proof as a JSON object in its group, see Group.Encode
+func (proof *CompactGSPFSProof) MarshalJSON() ([]byte, error) {
+	w := newJSONWriter(proofCurve(proof.curve))
+	w.group()
+	w.hash(proof.hash)
+	w.challenge("Challenge", proof.Challenge)
+	w.scalar("HiddenValue", proof.HiddenValue)
+	return w.marshal()
+}
+
+// UnmarshalJSON decodes a proof written by MarshalJSON
+func (proof *CompactGSPFSProof) UnmarshalJSON(b []byte) error {
+	var p CompactGSPFSProof
+	r := newJSONReader("CompactGSPFSProof.UnmarshalJSON", b)
+	r.group()
+	p.hash = r.hash()
+	p.Challenge = r.challenge("Challenge")
+	p.HiddenValue = r.scalar("HiddenValue")
+	if err := r.done(); err != nil {
+		return err
+	}
+	p.curve = r.curve
+	*proof = p
+	return nil
+}
+
+// ============ EQUIVALENCE ==================
+
+// CompactEquivalenceProof is an EquivalenceProof with a 128-bit challenge
+// that carries neither UG nor UH.
+//
+//	Prover                              Verifier
+//	======                              ========
+//	know x
+//	A = xG ; B = xH                     learns A, B
+//	selects random u
+//	T1 = uG
+//	T2 = uH
+//	c = HASH128(G, A, H, B, T1, T2)
+//	s = u + c * x
+//
+//	c, s ------------------------------>
+//	                                    T1' = sG - cA
+//	                                    T2' = sH - cB
+//	                                    c ?= HASH128(G, A, H, B, T1', T2')
+type CompactEquivalenceProof struct {
+	Challenge   *big.Int      // c, below 2^128
+	HiddenValue *big.Int      // s = u + c * x
+	curve       Group         // group the proof was computed in
+	hash        ChallengeHash // hash the challenges were computed with
+}
+
+// NewCompactEquivalenceProof generates a compact proof that Result1 = x * Base1
+// and Result2 = x * Base2 for the same x, see NewEquivalenceProof.
+func NewCompactEquivalenceProof(
+	zkpcp ZKPCurveParams, Base1, Result1, Base2, Result2 ECPoint, x *big.Int) (*CompactEquivalenceProof, error) {
+
+	in := zkpcp.validateInputs(CompactEquivalenceProofType, "NewCompactEquivalenceProof")
+	in.point("Base1", Base1)
+	in.point("Result1", Result1)
+	in.point("Base2", Base2)
+	in.point("Result2", Result2)
+	if err := in.done(); err != nil {
+		return nil, err
+	}
+
+	modValue := zkpcp.C.ReduceScalar(x)
+	defer WipeScalars(modValue)
+
+	if !zkpcp.MultSecret(Base1, modValue).Equal(Result1) {
+		return nil, zkpcp.traceError(&ProofError{Type: CompactEquivalenceProofType, Op: "NewCompactEquivalenceProof", Err: ErrInvalidWitness, Msg: "Base1 and Result1 are not related by x"})
+	}
+	if !zkpcp.MultSecret(Base2, modValue).Equal(Result2) {
+		return nil, zkpcp.traceError(&ProofError{Type: CompactEquivalenceProofType, Op: "NewCompactEquivalenceProof", Err: ErrInvalidWitness, Msg: "Base2 and Result2 are not related by x"})
+	}
+
+	nonces, err := zkpcp.newNonceSource(CompactEquivalenceProofType, []*big.Int{x}, Base1, Result1, Base2, Result2)
+	if err != nil {
+		return nil, err
+	}
+	defer nonces.destroy()
+	u, err := nonces.scalar()
+	if err != nil {
+		return nil, err
+	}
+
+	T1 := zkpcp.MultSecret(Base1, u)
+	T2 := zkpcp.MultSecret(Base2, u)
+	c := compactChallenge(zkpcp, Base1, Result1, Base2, Result2, T1, T2)
+	zkpcp.trace(CompactEquivalenceProofType, "prover commitment",
+		"T1", zkpcp.logPoint(T1), "T2", zkpcp.logPoint(T2), "challenge", c)
+
+	// s = u + c * x
+	s := zkpcp.C.AddScalars(u, zkpcp.C.MulScalars(c, modValue))
+
+	return &CompactEquivalenceProof{c, s, zkpcp.C, zkpcp.Hash}, nil
+}
+
+// commitments recomputes T1 = s * Base1 - c * Result1 and
+// T2 = s * Base2 - c * Result2.
+func (eqProof *CompactEquivalenceProof) commitments(
+	zkpcp ZKPCurveParams, Base1, Result1, Base2, Result2 ECPoint) (ECPoint, ECPoint) {
+
+	T1 := zkpcp.Sub(zkpcp.Mult(Base1, eqProof.HiddenValue), zkpcp.Mult(Result1, eqProof.Challenge))
+	T2 := zkpcp.Sub(zkpcp.Mult(Base2, eqProof.HiddenValue), zkpcp.Mult(Result2, eqProof.Challenge))
+	return T1, T2
+}
+
+// Verify checks if CompactEquivalenceProof eqProof is a valid proof that
+// Result1 = x * Base1 and Result2 = x * Base2 for the same x.
+func (eqProof *CompactEquivalenceProof) Verify(
+	zkpcp ZKPCurveParams, Base1, Result1, Base2, Result2 ECPoint) (bool, error) {
+
+	if eqProof == nil {
+		return false, zkpcp.traceError(&ProofError{Type: CompactEquivalenceProofType, Op: "CompactEquivalenceProof.Verify", Err: ErrMalformedProof, Msg: "passed proof is nil"})
+	}
+
+	v := zkpcp.validateInputs(CompactEquivalenceProofType, "CompactEquivalenceProof.Verify")
+	v.hash(eqProof.hash)
+	v.point("Base1", Base1)
+	v.point("Result1", Result1)
+	v.point("Base2", Base2)
+	v.point("Result2", Result2)
+	v.challenge("Challenge", eqProof.Challenge)
+	v.scalar("HiddenValue", eqProof.HiddenValue)
+	if err := v.done(); err != nil {
+		return false, err
+	}
+
+	T1, T2 := eqProof.commitments(zkpcp, Base1, Result1, Base2, Result2)
+	c := compactChallenge(zkpcp, Base1, Result1, Base2, Result2, T1, T2)
+	if c.Cmp(eqProof.Challenge) != 0 {
+		return false, zkpcp.traceError(&ProofError{Type: CompactEquivalenceProofType, Op: "CompactEquivalenceProof.Verify", Err: ErrChallengeMismatch, Msg: "calculated challenge and proof's challenge do not agree!"})
+	}
+	zkpcp.trace(CompactEquivalenceProofType, "verifier challenge", "challenge", c)
+	zkpcp.trace(CompactEquivalenceProofType, "verified")
+	return true, nil
+}
+
+// ChallengeHash returns the hash the challenges of eqProof were computed with
+func (eqProof *CompactEquivalenceProof) ChallengeHash() ChallengeHash {
+	return eqProof.hash
+}
+
+// Type returns CompactEquivalenceProofType
+func (eqProof *CompactEquivalenceProof) Type() ProofType {
+	return CompactEquivalenceProofType
+}
+
+// VerifyStatement checks eqProof against a CompactEquivalenceStatement, see
+// Verify
+func (eqProof *CompactEquivalenceProof) VerifyStatement(s Statement) (bool, error) {
+	st, ok := s.(CompactEquivalenceStatement)
+	if !ok {
+		return false, wrongStatement(CompactEquivalenceProofType, s)
+	}
+	return eqProof.Verify(st.Params, st.Base1, st.Result1, st.Base2, st.Result2)
+}
+
+// Diagnose runs every check of Verify and reports the outcome of each, see
+// VerificationReport
+func (eqProof *CompactEquivalenceProof) Diagnose(
+	zkpcp ZKPCurveParams, Base1, Result1, Base2, Result2 ECPoint) *VerificationReport {
+
+	r := newReport(CompactEquivalenceProofType, zkpcp)
+	if eqProof == nil {
+		return r.malformed()
+	}
+	if !r.wellFormed([]ECPoint{Base1, Result1, Base2, Result2}, []*big.Int{eqProof.HiddenValue}, eqProof.Challenge) {
+		return r
+	}
+	r.hash(eqProof.hash)
+
+	T1, T2 := eqProof.commitments(zkpcp, Base1, Result1, Base2, Result2)
+	c := compactChallenge(zkpcp, Base1, Result1, Base2, Result2, T1, T2)
+	r.scalar("HASH128(Base1, Result1, Base2, Result2, T1, T2) == Challenge", c, eqProof.Challenge)
+	return r
+}
+
+// DiagnoseStatement diagnoses eqProof against a CompactEquivalenceStatement,
+// see Diagnose
+func (eqProof *CompactEquivalenceProof) DiagnoseStatement(s Statement) (*VerificationReport, error) {
+	st, ok := s.(CompactEquivalenceStatement)
+	if !ok {
+		return nil, wrongStatement(CompactEquivalenceProofType, s)
+	}
+	return eqProof.Diagnose(st.Params, st.Base1, st.Result1, st.Base2, st.Result2), nil
+}
+
+// WriteTo writes the serialized representation of CompactEquivalenceProof
+// eqProof to w
+func (eqProof *CompactEquivalenceProof) WriteTo(w io.Writer) (int64, error) {
+	pw := newProofWriter(w, proofCurve(eqProof.curve), eqProof.hash)
+	pw.challenge(eqProof.Challenge)
+	pw.scalar(eqProof.HiddenValue)
+	return pw.done()
+}
+
+// ReadFrom reads one CompactEquivalenceProof written by WriteTo from r into
+// eqProof, see GSPFSProof.ReadFrom.
+func (eqProof *CompactEquivalenceProof) ReadFrom(r io.Reader) (int64, error) {
+	var p CompactEquivalenceProof
+	pr := newProofReader("CompactEquivalenceProof.ReadFrom", r)
+	p.curve, p.hash = pr.curve, pr.hash
+	p.Challenge = pr.challenge("Challenge")
+	p.HiddenValue = pr.scalar("HiddenValue")
+	n, err := pr.done()
+	if err != nil {
+		return n, err
+	}
+	*eqProof = p
+	return n, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (eqProof *CompactEquivalenceProof) MarshalBinary() ([]byte, error) {
+	return marshalProof(eqProof)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.  b must hold exactly
+// one proof.
+func (eqProof *CompactEquivalenceProof) UnmarshalBinary(b []byte) error {
+	return unmarshalProof("CompactEquivalenceProof.UnmarshalBinary", b, eqProof)
+}
+
+// Bytes returns a byte slice with a serialized representation of
+// CompactEquivalenceProof eqProof, or nil if eqProof can not be serialized
+func (eqProof *CompactEquivalenceProof) Bytes() []byte {
+	b, _ := eqProof.MarshalBinary()
+	return b
+}
+
+// NewCompactEquivalenceProofFromBytes returns a CompactEquivalenceProof
+// generated from the deserialization of byte slice b
+func NewCompactEquivalenceProofFromBytes(b []byte) (*CompactEquivalenceProof, error) {
+	proof := new(CompactEquivalenceProof)
+	if err := proof.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// MarshalJSON encodes eqProof as a JSON object in its group, see Group.Encode
+func (eqProof *CompactEquivalenceProof) MarshalJSON() ([]byte, error) {
+	w := newJSONWriter(proofCurve(eqProof.curve))
+	w.group()
+	w.hash(eqProof.hash)
+	w.challenge("Challenge", eqProof.Challenge)
+	w.scalar("HiddenValue", eqProof.HiddenValue)
+	return w.marshal()
+}
+
+// UnmarshalJSON decodes a proof written by MarshalJSON
+func (eqProof *CompactEquivalenceProof) UnmarshalJSON(b []byte) error {
+	var p CompactEquivalenceProof
+	r := newJSONReader("CompactEquivalenceProof.UnmarshalJSON", b)
+	r.group()
+	p.hash = r.hash()
+	p.Challenge = r.challenge("Challenge")
+	p.HiddenValue = r.scalar("HiddenValue")
+	if err := r.done(); err != nil {
+		return err
+	}
+	p.curve = r.curve
+	*eqProof = p
+	return nil
+}
+
+// ============ DISJUNCTIVE ==================
+
+// CompactDisjunctiveProof is a DisjunctiveProof with 128-bit challenges that
+// carries neither T1, T2 nor the challenge C.  The challenges of the two
+// sides add up to the hash modulo 2^128 instead of modulo N.
+//
+//	Prover                              Verifier
+//	======                              ========
+//	(proving x)
+//	A = xG; B = yH                      learns A, B
+//	selects random u1, u2
+//	selects random u3 < 2^128
+//	T1 = u1G
+//	T2 = u2H - u3B
+//	c = HASH128(G, A, H, B, T1, T2)
+//	deltaC = c - u3 mod 2^128
+//	s = u1 + deltaC * x
+//
+//	deltaC, u3, s, u2 -------------MAP-> c1, c2, s1, s2
+//	                                    T1' = s1G - c1A
+//	                                    T2' = s2H - c2B
+//	                                    c1 + c2 ?= HASH128(G, A, H, B, T1', T2') mod 2^128
+//
+// To prove y instead the sides are swapped, as for a DisjunctiveProof.
+type CompactDisjunctiveProof struct {
+	C1 *big.Int // challenge of the left side, below 2^128
+	C2 *big.Int // challenge of the right side, below 2^128
+	S1 *big.Int
+	S2 *big.Int
+
+	curve Group         // group the proof was computed in
+	hash  ChallengeHash // hash the challenges were computed with
+}
+
+// NewCompactDisjunctiveProof generates a compact proof that Result1 = x * Base1
+// or Result2 = x * Base2, proving the side given by option, see
+// NewDisjunctiveProof.
+func NewCompactDisjunctiveProof(
+	zkpcp ZKPCurveParams, Base1, Result1, Base2, Result2 ECPoint, x *big.Int, option Side) (*CompactDisjunctiveProof, error) {
+
+	in := zkpcp.validateInputs(CompactDisjunctiveProofType, "NewCompactDisjunctiveProof")
+	in.point("Base1", Base1)
+	in.point("Result1", Result1)
+	in.point("Base2", Base2)
+	in.point("Result2", Result2)
+	if err := in.done(); err != nil {
+		return nil, err
+	}
+
+	modValue := zkpcp.C.ReduceScalar(x)
+	defer WipeScalars(modValue)
+
+	var ProveBase, ProveResult, OtherBase, OtherResult ECPoint
+	switch option {
+	case Left:
+		ProveBase, ProveResult, OtherBase, OtherResult = Base1, Result1, Base2, Result2
+	case Right:
+		ProveBase, ProveResult, OtherBase, OtherResult = Base2, Result2, Base1, Result1
+	default:
+		return nil, zkpcp.traceError(&ProofError{Type: CompactDisjunctiveProofType, Op: "NewCompactDisjunctiveProof", Err: ErrInvalidWitness, Msg: "invalid side provided"})
+	}
+
+	if !zkpcp.MultSecret(ProveBase, modValue).Equal(ProveResult) {
+		return nil, zkpcp.traceError(&ProofError{Type: CompactDisjunctiveProofType, Op: "NewCompactDisjunctiveProof", Err: ErrInvalidWitness, Msg: "Base and Result to be proved not related by x"})
+	}
+	nonces, err := zkpcp.newNonceSource(CompactDisjunctiveProofType, []*big.Int{x}, Base1, Result1, Base2, Result2)
+	if err != nil {
+		return nil, err
+	}
+	defer nonces.destroy()
+	u1, err := nonces.scalar()
+	if err != nil {
+		return nil, err
+	}
+	u2, err := nonces.scalar()
+	if err != nil {
+		return nil, err
+	}
+	u3, err := nonces.scalar()
+	if err != nil {
+		return nil, err
+	}
+	// u3 is the simulated challenge, public like the challenges it is drawn
+	// among, and must survive nonces.destroy
+	u3 = new(big.Int).Mod(u3, compactChallengeMod)
+
+	// T1 = u1G
+	T1 := zkpcp.MultSecret(ProveBase, u1)
+	// T2 = u2H - u3B
+	T2 := zkpcp.Sub(zkpcp.MultSecret(OtherBase, u2), zkpcp.MultSecret(OtherResult, u3))
+
+	// T1 and T2 are hashed in the order of the sides, not of the option
+	var c *big.Int
+	if option == Left {
+		c = compactChallenge(zkpcp, Base1, Result1, Base2, Result2, T1, T2)
+	} else {
+		c = compactChallenge(zkpcp, Base1, Result1, Base2, Result2, T2, T1)
+	}
+	// T1 and T2 are not logged, their order here gives away option
+	zkpcp.trace(CompactDisjunctiveProofType, "prover commitment", "challenge", c)
+
+	deltaC := new(big.Int).Sub(c, u3)
+	deltaC.Mod(deltaC, compactChallengeMod)
+
+	s := zkpcp.C.AddScalars(u1, zkpcp.C.MulScalars(deltaC, modValue))
+
+	// u2 is the simulated response and must survive nonces.destroy
+	u2 = new(big.Int).Set(u2)
+
+	if option == Left {
+		return &CompactDisjunctiveProof{deltaC, u3, s, u2, zkpcp.C, zkpcp.Hash}, nil
+	}
+	return &CompactDisjunctiveProof{u3, deltaC, u2, s, zkpcp.C, zkpcp.Hash}, nil
+}
+
+// commitments recomputes T1 = S1 * Base1 - C1 * Result1 and
+// T2 = S2 * Base2 - C2 * Result2.
+func (djProof *CompactDisjunctiveProof) commitments(
+	zkpcp ZKPCurveParams, Base1, Result1, Base2, Result2 ECPoint) (ECPoint, ECPoint) {
+
+	T1 := zkpcp.Sub(zkpcp.Mult(Base1, djProof.S1), zkpcp.Mult(Result1, djProof.C1))
+	T2 := zkpcp.Sub(zkpcp.Mult(Base2, djProof.S2), zkpcp.Mult(Result2, djProof.C2))
+	return T1, T2
+}
+
+// totalChallenge returns C1 + C2 mod 2^128.
+func (djProof *CompactDisjunctiveProof) totalChallenge() *big.Int {
+	total := new(big.Int).Add(djProof.C1, djProof.C2)
+	return total.Mod(total, compactChallengeMod)
+}
+
+// Verify checks if CompactDisjunctiveProof djProof is valid for the given
+// bases and results
+func (djProof *CompactDisjunctiveProof) Verify(
+	zkpcp ZKPCurveParams, Base1, Result1, Base2, Result2 ECPoint) (bool, error) {
+
+	if djProof == nil {
+		return false, zkpcp.traceError(&ProofError{Type: CompactDisjunctiveProofType, Op: "CompactDisjunctiveProof.Verify", Err: ErrMalformedProof, Msg: "passed proof is nil"})
+	}
+
+	v := zkpcp.validateInputs(CompactDisjunctiveProofType, "CompactDisjunctiveProof.Verify")
+	v.hash(djProof.hash)
+	v.point("Base1", Base1)
+	v.point("Result1", Result1)
+	v.point("Base2", Base2)
+	v.point("Result2", Result2)
+	v.challenge("C1", djProof.C1)
+	v.challenge("C2", djProof.C2)
+	v.scalar("S1", djProof.S1)
+	v.scalar("S2", djProof.S2)
+	if err := v.done(); err != nil {
+		return false, err
+	}
+
+	T1, T2 := djProof.commitments(zkpcp, Base1, Result1, Base2, Result2)
+	c := compactChallenge(zkpcp, Base1, Result1, Base2, Result2, T1, T2)
+	if c.Cmp(djProof.totalChallenge()) != 0 {
+		return false, zkpcp.traceError(&ProofError{Type: CompactDisjunctiveProofType, Op: "CompactDisjunctiveProof.Verify", Err: ErrChallengeMismatch, Msg: "C1 + C2 does not agree with the calculated challenge"})
+	}
+	zkpcp.trace(CompactDisjunctiveProofType, "verifier challenge", "challenge", c)
+	zkpcp.trace(CompactDisjunctiveProofType, "verified")
+	return true, nil
+}
+
+// ChallengeHash returns the hash the challenges of djProof were computed with
+func (djProof *CompactDisjunctiveProof) ChallengeHash() ChallengeHash {
+	return djProof.hash
+}
+
+// Type returns CompactDisjunctiveProofType
+func (djProof *CompactDisjunctiveProof) Type() ProofType {
+	return CompactDisjunctiveProofType
+}
+
+// VerifyStatement checks djProof against a CompactDisjunctiveStatement, see
+// Verify
+func (djProof *CompactDisjunctiveProof) VerifyStatement(s Statement) (bool, error) {
+	st, ok := s.(CompactDisjunctiveStatement)
+	if !ok {
+		return false, wrongStatement(CompactDisjunctiveProofType, s)
+	}
+	return djProof.Verify(st.Params, st.Base1, st.Result1, st.Base2, st.Result2)
+}
+
+// Diagnose runs every check of Verify and reports the outcome of each, see
+// VerificationReport
+func (djProof *CompactDisjunctiveProof) Diagnose(
+	zkpcp ZKPCurveParams, Base1, Result1, Base2, Result2 ECPoint) *VerificationReport {
+
+	r := newReport(CompactDisjunctiveProofType, zkpcp)
+	if djProof == nil {
+		return r.malformed()
+	}
+	if !r.wellFormed([]ECPoint{Base1, Result1, Base2, Result2}, []*big.Int{djProof.S1, djProof.S2}, djProof.C1, djProof.C2) {
+		return r
+	}
+	r.hash(djProof.hash)
+
+	T1, T2 := djProof.commitments(zkpcp, Base1, Result1, Base2, Result2)
+	c := compactChallenge(zkpcp, Base1, Result1, Base2, Result2, T1, T2)
+	r.scalar("HASH128(Base1, Result1, Base2, Result2, T1, T2) == C1 + C2 mod 2^128", c, djProof.totalChallenge())
+	return r
+}
+
+// DiagnoseStatement diagnoses djProof against a CompactDisjunctiveStatement,
+// see Diagnose
+func (djProof *CompactDisjunctiveProof) DiagnoseStatement(s Statement) (*VerificationReport, error) {
+	st, ok := s.(CompactDisjunctiveStatement)
+	if !ok {
+		return nil, wrongStatement(CompactDisjunctiveProofType, s)
+	}
+	return djProof.Diagnose(st.Params, st.Base1, st.Result1, st.Base2, st.Result2), nil
+}
+
+// WriteTo writes the serialized representation of CompactDisjunctiveProof
+// djProof to w
+func (djProof *CompactDisjunctiveProof) WriteTo(w io.Writer) (int64, error) {
+	pw := newProofWriter(w, proofCurve(djProof.curve), djProof.hash)
+	pw.challenge(djProof.C1)
+	pw.challenge(djProof.C2)
+	pw.scalar(djProof.S1)
+	pw.scalar(djProof.S2)
+	return pw.done()
+}
+
+// ReadFrom reads one CompactDisjunctiveProof written by WriteTo from r into
+// djProof, see GSPFSProof.ReadFrom.
+func (djProof *CompactDisjunctiveProof) ReadFrom(r io.Reader) (int64, error) {
+	var p CompactDisjunctiveProof
+	pr := newProofReader("CompactDisjunctiveProof.ReadFrom", r)
+	p.curve, p.hash = pr.curve, pr.hash
+	p.C1 = pr.challenge("C1")
+	p.C2 = pr.challenge("C2")
+	p.S1 = pr.scalar("S1")
+	p.S2 = pr.scalar("S2")
+	n, err := pr.done()
+	if err != nil {
+		return n, err
+	}
+	*djProof = p
+	return n, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (djProof *CompactDisjunctiveProof) MarshalBinary() ([]byte, error) {
+	return marshalProof(djProof)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.  b must hold exactly
+// one proof.
+func (djProof *CompactDisjunctiveProof) UnmarshalBinary(b []byte) error {
+	return unmarshalProof("CompactDisjunctiveProof.UnmarshalBinary", b, djProof)
+}
+
+// Bytes returns a byte slice with a serialized representation of
+// CompactDisjunctiveProof djProof, or nil if djProof can not be serialized
+func (djProof *CompactDisjunctiveProof) Bytes() []byte {
+	b, _ := djProof.MarshalBinary()
+	return b
+}
+
+// NewCompactDisjunctiveProofFromBytes returns a CompactDisjunctiveProof
+// generated from the deserialization of byte slice b
+func NewCompactDisjunctiveProofFromBytes(b []byte) (*CompactDisjunctiveProof, error) {
+	proof := new(CompactDisjunctiveProof)
+	if err := proof.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// MarshalJSON encodes djProof as a JSON object in its group, see Group.Encode
+func (djProof *CompactDisjunctiveProof) MarshalJSON() ([]byte, error) {
+	w := newJSONWriter(proofCurve(djProof.curve))
+	w.group()
+	w.hash(djProof.hash)
+	w.challenge("C1", djProof.C1)
+	w.challenge("C2", djProof.C2)
+	w.scalar("S1", djProof.S1)
+	w.scalar("S2", djProof.S2)
+	return w.marshal()
+}
+
+// UnmarshalJSON decodes a proof written by MarshalJSON
+func (djProof *CompactDisjunctiveProof) UnmarshalJSON(b []byte) error {
+	var p CompactDisjunctiveProof
+	r := newJSONReader("CompactDisjunctiveProof.UnmarshalJSON", b)
+	r.group()
+	p.hash = r.hash()
+	p.C1 = r.challenge("C1")
+	p.C2 = r.challenge("C2")
+	p.S1 = r.scalar("S1")
+	p.S2 = r.scalar("S2")
+	if err := r.done(); err != nil {
+		return err
+	}
+	p.curve = r.curve
+	*djProof = p
+	return nil
+}
+
+// ============ CONSISTENCY ==================
+
+// CompactConsistencyProof is a ConsistencyProof with a 128-bit challenge
+// that carries neither T1 nor T2.
+//
+//	Prover                              Verifier
+//	======                              ========
+//	CM = vG + rH; CMTok = rPK           learns CM, CMTok
+//	selects random u1, u2
+//	T1 = u1G + u2H
+//	T2 = u2PK
+//	c = HASH128(G, H, CM, CMTok, PK, T1, T2)
+//	s1 = u1 + c * v
+//	s2 = u2 + c * r
+//
+//	c, s1, s2 ------------------------->
+//	                                    T1' = s1G + s2H - cCM
+//	                                    T2' = s2PK - cCMTok
+//	                                    c ?= HASH128(G, H, CM, CMTok, PK, T1', T2')
+type CompactConsistencyProof struct {
+	Challenge *big.Int      // c, below 2^128
+	S1        *big.Int      // s1 = u1 + c * v
+	S2        *big.Int      // s2 = u2 + c * r
+	curve     Group         // group the proof was computed in
+	hash      ChallengeHash // hash the challenges were computed with
+}
+
+// NewCompactConsistencyProof generates a compact proof that the r used in
+// CM(=xG+rH) and CMTok(=r(sk*H)) are the same, see NewConsistencyProof.
+func NewCompactConsistencyProof(zkpcp ZKPCurveParams,
+	CM, CMTok, PubKey ECPoint, value, randomness *big.Int) (*CompactConsistencyProof, error) {
+
+	in := zkpcp.validateInputs(CompactConsistencyProofType, "NewCompactConsistencyProof")
+	in.point("CM", CM)
+	in.point("CMTok", CMTok)
+	in.point("PubKey", PubKey)
+	if err := in.done(); err != nil {
+		return nil, err
+	}
+
+	modValue := zkpcp.C.ReduceScalar(value)
+	defer WipeScalars(modValue)
+
+	if !CM.Equal(PedCommitR(zkpcp, value, randomness)) {
+		return nil, zkpcp.traceError(&ProofError{Type: CompactConsistencyProofType, Op: "NewCompactConsistencyProof", Err: ErrInvalidWitness, Msg: "value and randomVal does not produce CM"})
+	}
+	if !CMTok.Equal(zkpcp.MultSecret(PubKey, randomness)) {
+		return nil, zkpcp.traceError(&ProofError{Type: CompactConsistencyProofType, Op: "NewCompactConsistencyProof", Err: ErrInvalidWitness, Msg: "Pubkey and randomVal does not produce CMTok"})
+	}
+
+	nonces, err := zkpcp.newNonceSource(CompactConsistencyProofType, []*big.Int{value, randomness}, CM, CMTok, PubKey)
+	if err != nil {
+		return nil, err
+	}
+	defer nonces.destroy()
+	u1, err := nonces.scalar()
+	if err != nil {
+		return nil, err
+	}
+	u2, err := nonces.scalar()
+	if err != nil {
+		return nil, err
+	}
+
+	T1 := PedCommitR(zkpcp, u1, u2)
+	T2 := zkpcp.MultSecret(PubKey, u2)
+	c := compactChallenge(zkpcp, zkpcp.G, zkpcp.H, CM, CMTok, PubKey, T1, T2)
+	zkpcp.trace(CompactConsistencyProofType, "prover commitment",
+		"T1", zkpcp.logPoint(T1), "T2", zkpcp.logPoint(T2), "challenge", c)
+
+	s1 := zkpcp.C.AddScalars(u1, zkpcp.C.MulScalars(modValue, c))
+	s2 := zkpcp.C.AddScalars(u2, zkpcp.C.MulScalars(randomness, c))
+
+	return &CompactConsistencyProof{c, s1, s2, zkpcp.C, zkpcp.Hash}, nil
+}
+
+// commitments recomputes T1 = S1 * G + S2 * H - c * CM and
+// T2 = S2 * PubKey - c * CMTok.
+func (conProof *CompactConsistencyProof) commitments(
+	zkpcp ZKPCurveParams, CM, CMTok, PubKey ECPoint) (ECPoint, ECPoint) {
+
+	T1 := zkpcp.Sub(zkpcp.Add(zkpcp.Mult(zkpcp.G, conProof.S1), zkpcp.Mult(zkpcp.H, conProof.S2)),
+		zkpcp.Mult(CM, conProof.Challenge))
+	T2 := zkpcp.Sub(zkpcp.Mult(PubKey, conProof.S2), zkpcp.Mult(CMTok, conProof.Challenge))
+	return T1, T2
+}
+
+// Verify checks if a CompactConsistencyProof conProof is valid
+func (conProof *CompactConsistencyProof) Verify(
+	zkpcp ZKPCurveParams, CM, CMTok, PubKey ECPoint) (bool, error) {
+
+	if conProof == nil {
+		return false, zkpcp.traceError(&ProofError{Type: CompactConsistencyProofType, Op: "CompactConsistencyProof.Verify", Err: ErrMalformedProof, Msg: "passed proof is nil"})
+	}
+
+	v := zkpcp.validateInputs(CompactConsistencyProofType, "CompactConsistencyProof.Verify")
+	v.hash(conProof.hash)
+	v.point("CM", CM)
+	v.point("CMTok", CMTok)
+	v.point("PubKey", PubKey)
+	v.challenge("Challenge", conProof.Challenge)
+	v.scalar("S1", conProof.S1)
+	v.scalar("S2", conProof.S2)
+	if err := v.done(); err != nil {
+		return false, err
+	}
+
+	T1, T2 := conProof.commitments(zkpcp, CM, CMTok, PubKey)
+	c := compactChallenge(zkpcp, zkpcp.G, zkpcp.H, CM, CMTok, PubKey, T1, T2)
+	if c.Cmp(conProof.Challenge) != 0 {
+		return false, zkpcp.traceError(&ProofError{Type: CompactConsistencyProofType, Op: "CompactConsistencyProof.Verify", Err: ErrChallengeMismatch, Msg: "calculated challenge and proof's challenge do not agree!"})
+	}
+	zkpcp.trace(CompactConsistencyProofType, "verifier challenge", "challenge", c)
+	zkpcp.trace(CompactConsistencyProofType, "verified")
+	return true, nil
+}
+
+// ChallengeHash returns the hash the challenges of conProof were computed with
+func (conProof *CompactConsistencyProof) ChallengeHash() ChallengeHash {
+	return conProof.hash
+}
+
+// Type returns CompactConsistencyProofType
+func (conProof *CompactConsistencyProof) Type() ProofType {
+	return CompactConsistencyProofType
+}
+
+// VerifyStatement checks conProof against a CompactConsistencyStatement, see
+// Verify
+func (conProof *CompactConsistencyProof) VerifyStatement(s Statement) (bool, error) {
+	st, ok := s.(CompactConsistencyStatement)
+	if !ok {
+		return false, wrongStatement(CompactConsistencyProofType, s)
+	}
+	return conProof.Verify(st.Params, st.CM, st.CMTok, st.PubKey)
+}
+
+// Diagnose runs every check of Verify and reports the outcome of each, see
+// VerificationReport
+func (conProof *CompactConsistencyProof) Diagnose(
+	zkpcp ZKPCurveParams, CM, CMTok, PubKey ECPoint) *VerificationReport {
+
+	r := newReport(CompactConsistencyProofType, zkpcp)
+	if conProof == nil {
+		return r.malformed()
+	}
+	if !r.wellFormed([]ECPoint{CM, CMTok, PubKey}, []*big.Int{conProof.S1, conProof.S2}, conProof.Challenge) {
+		return r
+	}
+	r.hash(conProof.hash)
+
+	T1, T2 := conProof.commitments(zkpcp, CM, CMTok, PubKey)
+	c := compactChallenge(zkpcp, zkpcp.G, zkpcp.H, CM, CMTok, PubKey, T1, T2)
+	r.scalar("HASH128(G, H, CM, CMTok, PubKey, T1, T2) == Challenge", c, conProof.Challenge)
+	return r
+}
+
+// DiagnoseStatement diagnoses conProof against a CompactConsistencyStatement,
+// see Diagnose
+func (conProof *CompactConsistencyProof) DiagnoseStatement(s Statement) (*VerificationReport, error) {
+	st, ok := s.(CompactConsistencyStatement)
+	if !ok {
+		return nil, wrongStatement(CompactConsistencyProofType, s)
+	}
+	return conProof.Diagnose(st.Params, st.CM, st.CMTok, st.PubKey), nil
+}
+
+// WriteTo writes the serialized representation of CompactConsistencyProof
+// conProof to w
+func (conProof *CompactConsistencyProof) WriteTo(w io.Writer) (int64, error) {
+	pw := newProofWriter(w, proofCurve(conProof.curve), conProof.hash)
+	pw.challenge(conProof.Challenge)
+	pw.scalar(conProof.S1)
+	pw.scalar(conProof.S2)
+	return pw.done()
+}
+
+// ReadFrom reads one CompactConsistencyProof written by WriteTo from r into
+// conProof, see GSPFSProof.ReadFrom.
+func (conProof *CompactConsistencyProof) ReadFrom(r io.Reader) (int64, error) {
+	var p CompactConsistencyProof
+	pr := newProofReader("CompactConsistencyProof.ReadFrom", r)
+	p.curve, p.hash = pr.curve, pr.hash
+	p.Challenge = pr.challenge("Challenge")
+	p.S1 = pr.scalar("S1")
+	p.S2 = pr.scalar("S2")
+	n, err := pr.done()
+	if err != nil {
+		return n, err
+	}
+	*conProof = p
+	return n, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (conProof *CompactConsistencyProof) MarshalBinary() ([]byte, error) {
+	return marshalProof(conProof)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.  b must hold exactly
+// one proof.
+func (conProof *CompactConsistencyProof) UnmarshalBinary(b []byte) error {
+	return unmarshalProof("CompactConsistencyProof.UnmarshalBinary", b, conProof)
+}
+
+// Bytes returns a byte slice with a serialized representation of
+// CompactConsistencyProof conProof, or nil if conProof can not be serialized
+func (conProof *CompactConsistencyProof) Bytes() []byte {
+	b, _ := conProof.MarshalBinary()
+	return b
+}
+
+// NewCompactConsistencyProofFromBytes returns a CompactConsistencyProof
+// generated from the deserialization of byte slice b
+func NewCompactConsistencyProofFromBytes(b []byte) (*CompactConsistencyProof, error) {
+	proof := new(CompactConsistencyProof)
+	if err := proof.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// MarshalJSON encodes conProof as a JSON object in its group, see Group.Encode
+func (conProof *CompactConsistencyProof) MarshalJSON() ([]byte, error) {
+	w := newJSONWriter(proofCurve(conProof.curve))
+	w.group()
+	w.hash(conProof.hash)
+	w.challenge("Challenge", conProof.Challenge)
+	w.scalar("S1", conProof.S1)
+	w.scalar("S2", conProof.S2)
+	return w.marshal()
+}
+
+// UnmarshalJSON decodes a proof written by MarshalJSON
+func (conProof *CompactConsistencyProof) UnmarshalJSON(b []byte) error {
+	var p CompactConsistencyProof
+	r := newJSONReader("CompactConsistencyProof.UnmarshalJSON", b)
+	r.group()
+	p.hash = r.hash()
+	p.Challenge = r.challenge("Challenge")
+	p.S1 = r.scalar("S1")
+	p.S2 = r.scalar("S2")
+	if err := r.done(); err != nil {
+		return err
+	}
+	p.curve = r.curve
+	*conProof = p
+	return nil
+}
@@ -0,0 +1,108 @@
+package zksigma
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestCompactProofs(t *testing.T) {
+	forEachCurve(t, testCompactProofs)
+}
+
+func testCompactProofs(t *testing.T, zkpcp ZKPCurveParams) {
+	x, y := big.NewInt(100), big.NewInt(101)
+	X := zkpcp.Mult(zkpcp.G, x)
+	XH := zkpcp.Mult(zkpcp.H, x)
+	YH := zkpcp.Mult(zkpcp.H, y)
+
+	// Both sides of a disjunction verify.
+	for _, side := range []Side{Left, Right} {
+		witness := x
+		if side == Right {
+			witness = y
+		}
+		dj, err := NewCompactDisjunctiveProof(zkpcp, zkpcp.G, X, zkpcp.H, YH, witness, side)
+		if err != nil {
+			t.Fatalf("side %d: %v\n", side, err)
+		}
+		if ok, err := dj.Verify(zkpcp, zkpcp.G, X, zkpcp.H, YH); !ok || err != nil {
+			t.Fatalf("side %d: %v\n", side, err)
+		}
+	}
+	if _, err := NewCompactDisjunctiveProof(zkpcp, zkpcp.G, X, zkpcp.H, YH, y, Left); !errors.Is(err, ErrInvalidWitness) {
+		t.Fatalf("proving the wrong side should fail, got %v\n", err)
+	}
+	if _, err := NewCompactGSPFSProof(zkpcp, XH, x); !errors.Is(err, ErrInvalidWitness) {
+		t.Fatalf("proving the wrong base should fail, got %v\n", err)
+	}
+
+	// Compact proofs are smaller than the proofs they replace, and a wrong
+	// response is caught by the challenge.
+	for _, ps := range sampleProofs(t, zkpcp) {
+		var full Proof
+		switch p := ps.proof.(type) {
+		case *CompactGSPFSProof:
+			full, _ = NewGSPFSProofBase(zkpcp, zkpcp.H, XH, x)
+			bad := *p
+			bad.HiddenValue = zkpcp.C.AddScalars(p.HiddenValue, big.NewInt(1))
+			ps.proof = &bad
+		case *CompactEquivalenceProof:
+			full, _ = NewEquivalenceProof(zkpcp, zkpcp.G, X, zkpcp.H, XH, x)
+			bad := *p
+			bad.HiddenValue = zkpcp.C.AddScalars(p.HiddenValue, big.NewInt(1))
+			ps.proof = &bad
+		case *CompactDisjunctiveProof:
+			full, _ = NewDisjunctiveProof(zkpcp, zkpcp.H, YH, zkpcp.G, X, x, Right)
+			bad := *p
+			bad.C1 = new(big.Int).Add(p.C1, compactChallengeMod)
+			if _, err := bad.VerifyStatement(ps.st); !errors.Is(err, ErrInvalidScalar) {
+				t.Fatalf("challenge above 2^128 should be rejected, got %v\n", err)
+			}
+			bad.C1, bad.S2 = p.C1, zkpcp.C.AddScalars(p.S2, big.NewInt(1))
+			ps.proof = &bad
+		case *CompactConsistencyProof:
+			// only the size of full matters, so it can prove another commitment
+			PK, _ := KeyGen(zkpcp.C, zkpcp.H)
+			CM, u, err := PedCommit(zkpcp, x)
+			if err != nil {
+				t.Fatalf("%v\n", err)
+			}
+			full, _ = NewConsistencyProof(zkpcp, CM, zkpcp.Mult(PK, u), PK, x, u)
+			bad := *p
+			bad.S1 = zkpcp.C.AddScalars(p.S1, big.NewInt(1))
+			ps.proof = &bad
+		default:
+			continue
+		}
+		if len(EncodeProof(ps.proof)) >= len(EncodeProof(full)) {
+			t.Fatalf("%v is %d bytes, %v is %d\n", ps.proof.Type(), len(EncodeProof(ps.proof)), full.Type(), len(EncodeProof(full)))
+		}
+		if _, err := ps.proof.VerifyStatement(ps.st); !errors.Is(err, ErrChallengeMismatch) {
+			t.Fatalf("%v: wrong response should fail the challenge, got %v\n", ps.proof.Type(), err)
+		}
+		if r, _ := ps.proof.DiagnoseStatement(ps.st); r.OK() || len(r.Failed()) != 1 {
+			t.Fatalf("%v: diagnosis should fail the challenge only: %+v\n", ps.proof.Type(), r.Checks)
+		}
+	}
+
+	// Challenges are 128 bits and cannot be serialized otherwise.
+	gspfs, err := NewCompactGSPFSProof(zkpcp, X, x)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	if gspfs.Challenge.BitLen() > 128 {
+		t.Fatalf("challenge %x has more than 128 bits\n", gspfs.Challenge)
+	}
+	bad := *gspfs
+	bad.Challenge = new(big.Int).Set(compactChallengeMod)
+	if bad.Bytes() != nil {
+		t.Fatalf("a challenge of 2^128 should not serialize\n")
+	}
+	if _, err := bad.Verify(zkpcp, zkpcp.G, X); !errors.Is(err, ErrInvalidScalar) {
+		t.Fatalf("expected %v, got %v\n", ErrInvalidScalar, err)
+	}
+	if ok, err := gspfs.Verify(zkpcp, zkpcp.H, X); ok || err == nil {
+		t.Fatalf("proof should not verify against another base\n")
+	}
+}
@@ -1,9 +1,8 @@
 package zksigma
 
 import (
-	"bytes"
-	"crypto/rand"
 	"fmt"
+	"io"
 	"math/big"
 )
 
@@ -36,8 +35,10 @@ type ConsistencyProof struct {
 	T1        ECPoint
 	T2        ECPoint
 	Challenge *big.Int
-	S1        *big.Int // s1 - but capitalized to allow access from outside of zksigma
-	S2        *big.Int // s2 - but capitalized to allow access from outside of zksigma
+	S1        *big.Int      // s1 - but capitalized to allow access from outside of zksigma
+	S2        *big.Int      // s2 - but capitalized to allow access from outside of zksigma
+	curve     Group         // group the proof was computed in
+	hash      ChallengeHash // hash the challenges were computed with
 }
 
 // NewConsistencyProof generates a proof that the r used in CM(=xG+rH)
@@ -45,43 +46,55 @@ type ConsistencyProof struct {
 func NewConsistencyProof(zkpcp ZKPCurveParams,
 	CM, CMTok, PubKey ECPoint, value, randomness *big.Int) (*ConsistencyProof, error) {
 
-	modValue := new(big.Int).Mod(value, zkpcp.C.Params().N)
-	//modRandom := new(big.Int).Mod(randomness, zkpcp.C.Params().N)
+	in := zkpcp.validateInputs(ConsistencyProofType, "NewConsistencyProof")
+	in.point("CM", CM)
+	in.point("CMTok", CMTok)
+	in.point("PubKey", PubKey)
+	if err := in.done(); err != nil {
+		return &ConsistencyProof{}, err
+	}
+
+	modValue := zkpcp.C.ReduceScalar(value)
+	defer WipeScalars(modValue)
 
 	// do a quick correctness check to ensure the value we are testing and the
 	// randomness are correct
 	if !CM.Equal(PedCommitR(zkpcp, value, randomness)) {
-		return &ConsistencyProof{}, &errorProof{"ConsistencyProve", "value and randomVal does not produce CM"}
+		return &ConsistencyProof{}, zkpcp.traceError(&ProofError{Type: ConsistencyProofType, Op: "NewConsistencyProof", Err: ErrInvalidWitness, Msg: "value and randomVal does not produce CM"})
 	}
 
-	if !CMTok.Equal(zkpcp.Mult(PubKey, randomness)) {
-		return &ConsistencyProof{}, &errorProof{"ConsistencyProve", "Pubkey and randomVal does not produce CMTok"}
+	if !CMTok.Equal(zkpcp.MultSecret(PubKey, randomness)) {
+		return &ConsistencyProof{}, zkpcp.traceError(&ProofError{Type: ConsistencyProofType, Op: "NewConsistencyProof", Err: ErrInvalidWitness, Msg: "Pubkey and randomVal does not produce CMTok"})
 	}
 
-	u1, err := rand.Int(rand.Reader, zkpcp.C.Params().N)
+	nonces, err := zkpcp.newNonceSource(ConsistencyProofType, []*big.Int{value, randomness}, CM, CMTok, PubKey)
+	if err != nil {
+		return nil, err
+	}
+	defer nonces.destroy()
+	u1, err := nonces.scalar()
 	if err != nil {
 		return nil, err
 	}
-	u2, err := rand.Int(rand.Reader, zkpcp.C.Params().N)
+	u2, err := nonces.scalar()
 	if err != nil {
 		return nil, err
 	}
 
 	T1 := PedCommitR(zkpcp, u1, u2)
-	T2 := zkpcp.Mult(PubKey, u2)
-
-	Challenge := GenerateChallenge(zkpcp, zkpcp.G.Bytes(), zkpcp.H.Bytes(),
-		CM.Bytes(), CMTok.Bytes(),
-		PubKey.Bytes(),
-		T1.Bytes(), T2.Bytes())
+	T2 := zkpcp.MultSecret(PubKey, u2)
 
-	s1 := new(big.Int).Add(u1, new(big.Int).Mul(modValue, Challenge))
-	s2 := new(big.Int).Add(u2, new(big.Int).Mul(randomness, Challenge))
+	Challenge := GenerateChallenge(zkpcp, zkpcp.Bytes(zkpcp.G), zkpcp.Bytes(zkpcp.H),
+		zkpcp.Bytes(CM), zkpcp.Bytes(CMTok),
+		zkpcp.Bytes(PubKey),
+		zkpcp.Bytes(T1), zkpcp.Bytes(T2))
+	zkpcp.trace(ConsistencyProofType, "prover commitment",
+		"T1", zkpcp.logPoint(T1), "T2", zkpcp.logPoint(T2), "challenge", Challenge)
 
-	s1.Mod(s1, zkpcp.C.Params().N)
-	s2.Mod(s2, zkpcp.C.Params().N)
+	s1 := zkpcp.C.AddScalars(u1, zkpcp.C.MulScalars(modValue, Challenge))
+	s2 := zkpcp.C.AddScalars(u2, zkpcp.C.MulScalars(randomness, Challenge))
 
-	conProof := &ConsistencyProof{T1, T2, Challenge, s1, s2}
+	conProof := &ConsistencyProof{T1, T2, Challenge, s1, s2, zkpcp.C, zkpcp.Hash}
 
 	return conProof, nil
 
@@ -92,31 +105,47 @@ func (conProof *ConsistencyProof) Verify(
 	zkpcp ZKPCurveParams, CM, CMTok, PubKey ECPoint) (bool, error) {
 
 	if conProof == nil {
-		return false, &errorProof{"ConsistencyProof.Verify", fmt.Sprintf("passed proof is nil")}
+		return false, zkpcp.traceError(&ProofError{Type: ConsistencyProofType, Op: "ConsistencyProof.Verify", Err: ErrMalformedProof, Msg: "passed proof is nil"})
+	}
+
+	v := zkpcp.validateInputs(ConsistencyProofType, "ConsistencyProof.Verify")
+	v.hash(conProof.hash)
+	v.point("CM", CM)
+	v.point("CMTok", CMTok)
+	v.point("PubKey", PubKey)
+	v.point("T1", conProof.T1)
+	v.point("T2", conProof.T2)
+	v.scalar("Challenge", conProof.Challenge)
+	v.scalar("S1", conProof.S1)
+	v.scalar("S2", conProof.S2)
+	if err := v.done(); err != nil {
+		return false, err
 	}
 
 	// Regenerate challenge string
-	Challenge := GenerateChallenge(zkpcp, zkpcp.G.Bytes(), zkpcp.H.Bytes(),
-		CM.Bytes(), CMTok.Bytes(),
-		PubKey.Bytes(),
-		conProof.T1.Bytes(), conProof.T2.Bytes())
+	Challenge := GenerateChallenge(zkpcp, zkpcp.Bytes(zkpcp.G), zkpcp.Bytes(zkpcp.H),
+		zkpcp.Bytes(CM), zkpcp.Bytes(CMTok),
+		zkpcp.Bytes(PubKey),
+		zkpcp.Bytes(conProof.T1), zkpcp.Bytes(conProof.T2))
 
 	// c ?= HASH(G, H, T1, T2, PK, CM, Y)
 	if Challenge.Cmp(conProof.Challenge) != 0 {
-		return false, &errorProof{"ConsistencyVerify", fmt.Sprintf("c comparison failed. proof: %v calculated: %v",
-			conProof.Challenge, Challenge)}
+		return false, zkpcp.traceError(&ProofError{Type: ConsistencyProofType, Op: "ConsistencyProof.Verify", Err: ErrChallengeMismatch, Msg: fmt.Sprintf("c comparison failed. proof: %v calculated: %v",
+			conProof.Challenge, Challenge)})
 	}
+	zkpcp.trace(ConsistencyProofType, "verifier challenge", "challenge", Challenge)
 	// lhs :: left hand side, rhs :: right hand side
 	// s1G + s2H ?= T1 + cCM, CM should be point1
 	// s1G + s2H from how PedCommitR works
-	lhs := PedCommitR(zkpcp, conProof.S1, conProof.S2)
+	// PedCommitR is for secrets, S1 and S2 are public
+	lhs := zkpcp.Add(zkpcp.Mult(zkpcp.G, conProof.S1), zkpcp.Mult(zkpcp.H, conProof.S2))
 	// cCM
 	temp1 := zkpcp.Mult(CM, Challenge)
 	// T1 + cCM
 	rhs := zkpcp.Add(conProof.T1, temp1)
 
 	if !lhs.Equal(rhs) {
-		return false, &errorProof{"ConsistencyVerify", "CM check is failing"}
+		return false, zkpcp.traceError(&ProofError{Type: ConsistencyProofType, Op: "ConsistencyProof.Verify", Err: ErrEquationFailed, Equation: 1, Msg: "CM check is failing"})
 	}
 
 	// s2PK ?= T2 + cY
@@ -125,35 +154,160 @@ func (conProof *ConsistencyProof) Verify(
 	rhs = zkpcp.Add(conProof.T2, temp1)
 
 	if !lhs.Equal(rhs) {
-		return false, &errorProof{"ConsistencyVerify", "CMTok check is failing"}
+		return false, zkpcp.traceError(&ProofError{Type: ConsistencyProofType, Op: "ConsistencyProof.Verify", Err: ErrEquationFailed, Equation: 2, Msg: "CMTok check is failing"})
 	}
 
 	// All three checks passed, proof must be correct
+	zkpcp.trace(ConsistencyProofType, "verified")
 	return true, nil
 }
 
-// Bytes returns a byte slice with a serialized representation of ConsistencyProof proof
-func (proof *ConsistencyProof) Bytes() []byte {
-	var buf bytes.Buffer
+// ChallengeHash returns the hash the challenges of conProof were computed with
+func (conProof *ConsistencyProof) ChallengeHash() ChallengeHash {
+	return conProof.hash
+}
 
-	WriteECPoint(&buf, proof.T1)
-	WriteECPoint(&buf, proof.T2)
-	WriteBigInt(&buf, proof.Challenge)
-	WriteBigInt(&buf, proof.S1)
-	WriteBigInt(&buf, proof.S2)
+// Type returns ConsistencyProofType
+func (conProof *ConsistencyProof) Type() ProofType {
+	return ConsistencyProofType
+}
 
-	return buf.Bytes()
+// VerifyStatement checks conProof against a ConsistencyStatement, see Verify
+func (conProof *ConsistencyProof) VerifyStatement(s Statement) (bool, error) {
+	st, ok := s.(ConsistencyStatement)
+	if !ok {
+		return false, wrongStatement(ConsistencyProofType, s)
+	}
+	return conProof.Verify(st.Params, st.CM, st.CMTok, st.PubKey)
+}
+
+// Diagnose runs every check of Verify and reports the outcome of each, see
+// VerificationReport
+func (conProof *ConsistencyProof) Diagnose(
+	zkpcp ZKPCurveParams, CM, CMTok, PubKey ECPoint) *VerificationReport {
+
+	r := newReport(ConsistencyProofType, zkpcp)
+	if conProof == nil {
+		return r.malformed()
+	}
+	if !r.wellFormed([]ECPoint{CM, CMTok, PubKey, conProof.T1, conProof.T2},
+		[]*big.Int{conProof.Challenge, conProof.S1, conProof.S2}) {
+		return r
+	}
+	r.hash(conProof.hash)
+
+	Challenge := GenerateChallenge(zkpcp, zkpcp.Bytes(zkpcp.G), zkpcp.Bytes(zkpcp.H),
+		zkpcp.Bytes(CM), zkpcp.Bytes(CMTok),
+		zkpcp.Bytes(PubKey),
+		zkpcp.Bytes(conProof.T1), zkpcp.Bytes(conProof.T2))
+	r.scalar("HASH(G, H, CM, CMTok, PubKey, T1, T2) == Challenge", Challenge, conProof.Challenge)
+
+	r.point("S1*G + S2*H == T1 + Challenge*CM", 1,
+		zkpcp.Add(zkpcp.Mult(zkpcp.G, conProof.S1), zkpcp.Mult(zkpcp.H, conProof.S2)),
+		zkpcp.Add(conProof.T1, zkpcp.Mult(CM, conProof.Challenge)))
+	r.point("S2*PubKey == T2 + Challenge*CMTok", 2,
+		zkpcp.Mult(PubKey, conProof.S2),
+		zkpcp.Add(conProof.T2, zkpcp.Mult(CMTok, conProof.Challenge)))
+	return r
+}
+
+// DiagnoseStatement diagnoses conProof against a ConsistencyStatement, see
+// Diagnose
+func (conProof *ConsistencyProof) DiagnoseStatement(s Statement) (*VerificationReport, error) {
+	st, ok := s.(ConsistencyStatement)
+	if !ok {
+		return nil, wrongStatement(ConsistencyProofType, s)
+	}
+	return conProof.Diagnose(st.Params, st.CM, st.CMTok, st.PubKey), nil
+}
+
+// WriteTo writes the serialized representation of ConsistencyProof conProof to w
+func (conProof *ConsistencyProof) WriteTo(w io.Writer) (int64, error) {
+	pw := newProofWriter(w, proofCurve(conProof.curve), conProof.hash)
+	pw.point(conProof.T1)
+	pw.point(conProof.T2)
+	pw.scalar(conProof.Challenge)
+	pw.scalar(conProof.S1)
+	pw.scalar(conProof.S2)
+	return pw.done()
+}
+
+// ReadFrom reads one ConsistencyProof written by WriteTo from r into conProof.  It reads no
+// further than the end of the proof, so proofs can be read back to back from
+// a stream; io.EOF is returned if r ends before the next proof starts.
+func (conProof *ConsistencyProof) ReadFrom(r io.Reader) (int64, error) {
+	var p ConsistencyProof
+	pr := newProofReader("ConsistencyProof.ReadFrom", r)
+	p.curve, p.hash = pr.curve, pr.hash
+	p.T1 = pr.point("T1")
+	p.T2 = pr.point("T2")
+	p.Challenge = pr.scalar("Challenge")
+	p.S1 = pr.scalar("S1")
+	p.S2 = pr.scalar("S2")
+	n, err := pr.done()
+	if err != nil {
+		return n, err
+	}
+	*conProof = p
+	return n, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (conProof *ConsistencyProof) MarshalBinary() ([]byte, error) {
+	return marshalProof(conProof)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.  b must hold exactly
+// one proof.
+func (conProof *ConsistencyProof) UnmarshalBinary(b []byte) error {
+	return unmarshalProof("ConsistencyProof.UnmarshalBinary", b, conProof)
+}
+
+// Bytes returns a byte slice with a serialized representation of ConsistencyProof conProof, or
+// nil if conProof can not be serialized
+func (conProof *ConsistencyProof) Bytes() []byte {
+	b, _ := conProof.MarshalBinary()
+	return b
 }
 
 // NewConsistencyProofFromBytes returns a ConsistencyProof generated from the
 // deserialization of byte slice b
 func NewConsistencyProofFromBytes(b []byte) (*ConsistencyProof, error) {
 	proof := new(ConsistencyProof)
-	buf := bytes.NewBuffer(b)
-	proof.T1, _ = ReadECPoint(buf)
-	proof.T2, _ = ReadECPoint(buf)
-	proof.Challenge, _ = ReadBigInt(buf)
-	proof.S1, _ = ReadBigInt(buf)
-	proof.S2, _ = ReadBigInt(buf)
+	if err := proof.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
 	return proof, nil
 }
+
+// MarshalJSON encodes conProof as a JSON object in its group, see Group.Encode
+func (conProof *ConsistencyProof) MarshalJSON() ([]byte, error) {
+	w := newJSONWriter(proofCurve(conProof.curve))
+	w.group()
+	w.hash(conProof.hash)
+	w.point("T1", conProof.T1)
+	w.point("T2", conProof.T2)
+	w.scalar("Challenge", conProof.Challenge)
+	w.scalar("S1", conProof.S1)
+	w.scalar("S2", conProof.S2)
+	return w.marshal()
+}
+
+// UnmarshalJSON decodes a proof written by MarshalJSON
+func (conProof *ConsistencyProof) UnmarshalJSON(b []byte) error {
+	var p ConsistencyProof
+	r := newJSONReader("ConsistencyProof.UnmarshalJSON", b)
+	r.group()
+	p.hash = r.hash()
+	p.T1 = r.point("T1")
+	p.T2 = r.point("T2")
+	p.Challenge = r.scalar("Challenge")
+	p.S1 = r.scalar("S1")
+	p.S2 = r.scalar("S2")
+	if err := r.done(); err != nil {
+		return err
+	}
+	p.curve = r.curve
+	*conProof = p
+	return nil
+}
@@ -6,40 +6,44 @@ import (
 )
 
 func TestConsistency(t *testing.T) {
-	x, err := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	forEachCurve(t, testConsistency)
+}
+
+func testConsistency(t *testing.T, zkpcp ZKPCurveParams) {
+	x, err := rand.Int(rand.Reader, zkpcp.C.Order())
 	if err != nil {
 		t.Fatalf("%v\n", err)
 	}
 
-	sk, err := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	sk, err := rand.Int(rand.Reader, zkpcp.C.Order())
 	if err != nil {
 		t.Fatalf("%v\n", err)
 	}
 
-	pk := TestCurve.Mult(TestCurve.H, sk)
+	pk := zkpcp.Mult(zkpcp.H, sk)
 
-	comm, u, err := PedCommit(TestCurve, x)
+	comm, u, err := PedCommit(zkpcp, x)
 	if err != nil {
 		t.Fatalf("%v\n", err)
 	}
 
-	y := TestCurve.Mult(pk, u)
+	y := zkpcp.Mult(pk, u)
 
-	conProof, status1 := NewConsistencyProof(TestCurve, comm, y, pk, x, u)
+	conProof, status1 := NewConsistencyProof(zkpcp, comm, y, pk, x, u)
 
 	if status1 != nil {
 		t.Fatalf("TestConsistency - incorrect error message for correct proof, case 1\n")
 	}
 
 	t.Logf(" [testing] Testing correct consistency proof\n")
-	check, err := conProof.Verify(TestCurve, comm, y, pk)
+	check, err := conProof.Verify(zkpcp, comm, y, pk)
 	if !check || err != nil {
 		t.Fatalf("Error -- Proof should be correct\n")
 	}
 
 	t.Logf(" [testing] Next proof should fail\n")
 
-	_, status2 := NewConsistencyProof(TestCurve, y, comm, pk, x, u)
+	_, status2 := NewConsistencyProof(zkpcp, y, comm, pk, x, u)
 
 	if status2 == nil {
 		t.Fatalf("TestConsistency - incorrect error message for correct proof, case 2\n")
@@ -47,26 +51,30 @@ func TestConsistency(t *testing.T) {
 }
 
 func TestConsistencySerialization(t *testing.T) {
-	x, err := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	forEachCurve(t, testConsistencySerialization)
+}
+
+func testConsistencySerialization(t *testing.T, zkpcp ZKPCurveParams) {
+	x, err := rand.Int(rand.Reader, zkpcp.C.Order())
 	if err != nil {
 		t.Fatalf("%v\n", err)
 	}
 
-	sk, err := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	sk, err := rand.Int(rand.Reader, zkpcp.C.Order())
 	if err != nil {
 		t.Fatalf("%v\n", err)
 	}
 
-	pk := TestCurve.Mult(TestCurve.H, sk)
+	pk := zkpcp.Mult(zkpcp.H, sk)
 
-	comm, u, err := PedCommit(TestCurve, x)
+	comm, u, err := PedCommit(zkpcp, x)
 	if err != nil {
 		t.Fatalf("%v\n", err)
 	}
 
-	y := TestCurve.Mult(pk, u)
+	y := zkpcp.Mult(pk, u)
 
-	conProof, status1 := NewConsistencyProof(TestCurve, comm, y, pk, x, u)
+	conProof, status1 := NewConsistencyProof(zkpcp, comm, y, pk, x, u)
 
 	if status1 != nil {
 		t.Fatalf("TestConsistency - incorrect error message for correct proof, case 1\n")
@@ -77,16 +85,16 @@ func TestConsistencySerialization(t *testing.T) {
 		t.Fatalf("TestConsistency - failed to deserialize \n")
 	}
 	t.Logf(" [testing] Testing correct consistency proof\n")
-	check, err := conProof.Verify(TestCurve, comm, y, pk)
+	check, err := conProof.Verify(zkpcp, comm, y, pk)
 	if !check || err != nil {
 		t.Fatalf("Error -- Proof should be correct\n")
 	}
 }
 
 func BenchmarkConsistencyProve(b *testing.B) {
-	value, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	value, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 
-	sk, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	sk, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 	PK := TestCurve.Mult(TestCurve.H, sk)
 
 	CM, randVal, err := PedCommit(TestCurve, value)
@@ -103,9 +111,9 @@ func BenchmarkConsistencyProve(b *testing.B) {
 }
 
 func BenchmarkConsistencyVerify(b *testing.B) {
-	value, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	value, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 
-	sk, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	sk, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 	PK := TestCurve.Mult(TestCurve.H, sk)
 
 	CM, randVal, err := PedCommit(TestCurve, value)
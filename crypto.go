@@ -1,65 +1,150 @@
 package zksigma
 
 import (
-	"crypto/elliptic"
+	"bytes"
 	"crypto/rand"
-	"crypto/sha256"
-	"flag"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"math/big"
 
-	"github.com/mit-dci/zksigma/btcec"
 	"github.com/mit-dci/zksigma/wire"
 )
 
 // ZKPCurveParams is zero knowledge proof curve and params struct, only one instance should be used
 type ZKPCurveParams struct {
-	C       elliptic.Curve // Curve
-	G       ECPoint        // generator 1
-	H       ECPoint        // generator 2
-	HPoints []ECPoint      // HPoints should be initialized with a pre-populated array of the ZKCurve's generator point H multiplied by 2^x where x = [0...63]
+	C       Group     // Group the proofs are computed in
+	G       ECPoint   // generator 1
+	H       ECPoint   // generator 2
+	HPoints []ECPoint // HPoints[i] is 2^i * G for i in [0...63], see NewZKPCurveParams and Validate
+
+	// Rand is the source of randomness for provers and PedCommit.  If nil,
+	// crypto/rand.Reader is used.  Verifiers never read it.
+	Rand io.Reader
+
+	// DeterministicNonces makes provers derive their nonces from the witness,
+	// the statement and entropy from Rand, RFC 6979 style, instead of reading
+	// them from Rand directly.  Proofs verify the same either way.
+	DeterministicNonces bool
+
+	// Logger, if not nil, receives a debug level event for every step of
+	// making and verifying proofs, and for every failure.  Events carry public
+	// values only.
+	Logger *slog.Logger
+
+	// Hash is the hash challenges are computed with, HashSHAKE256 by
+	// default.  Provers and verifiers must agree on it: verifiers reject
+	// proofs that record another hash with ErrHashMismatch.
+	Hash ChallengeHash
 }
 
-// DEBUG Indicates whether we output debug information while running the tests. Default off.
-var DEBUG = flag.Bool("debug1", false, "Debug output")
+// WithRand returns a copy of zkpcp whose provers read randomness from r.
+func (zkpcp ZKPCurveParams) WithRand(r io.Reader) ZKPCurveParams {
+	zkpcp.Rand = r
+	return zkpcp
+}
 
-type errorProof struct {
-	t string // proof type that failed
-	s string // error message
+// WithHash returns a copy of zkpcp that computes challenges with h.
+func (zkpcp ZKPCurveParams) WithHash(h ChallengeHash) ZKPCurveParams {
+	zkpcp.Hash = h
+	return zkpcp
 }
 
-func (e *errorProof) Error() string {
-	return fmt.Sprintf("%v - %v\n", e.t, e.s)
+// randomScalar returns a random scalar read from zkpcp.Rand.
+func (zkpcp ZKPCurveParams) randomScalar() (*big.Int, error) {
+	r := zkpcp.Rand
+	if r == nil {
+		r = rand.Reader
+	}
+	return zkpcp.C.RandomScalar(r)
 }
 
-func proofStatus(e *errorProof) int {
-	if *DEBUG && e != nil {
-		fmt.Printf("ERROR: %v \n", e.Error())
-		return -1
+// numHPoints is the number of entries Validate expects in HPoints.
+const numHPoints = 64
+
+// NewZKPCurveParams returns the parameters for curve, with G set to the
+// group's generator, H hashed from seed and HPoints filled in. The same curve
+// and seed always produce the same parameters, so provers and verifiers only
+// need to agree on those two values.
+//
+// HPoints holds 2^i * G rather than 2^i * H because the range proof swaps the
+// roles of G and H.
+func NewZKPCurveParams(curve Group, seed []byte) (ZKPCurveParams, error) {
+	if curve == nil {
+		return ZKPCurveParams{}, errors.New("NewZKPCurveParams: nil group")
+	}
+	G := curve.Generator()
+	zkpcp := ZKPCurveParams{
+		C:       curve,
+		G:       G,
+		H:       curve.HashToPoint(seed),
+		HPoints: generateH2tothe(curve, G),
 	}
-	return 0
+	if err := zkpcp.Validate(); err != nil {
+		return ZKPCurveParams{}, err
+	}
+	return zkpcp, nil
 }
 
-func logStuff(format string, args ...interface{}) {
-	if *DEBUG {
-		log.SetFlags(log.Lshortfile)
-		log.Printf(format, args...)
+// Validate checks that G and H are distinct, non-identity elements of C, that
+// HPoints[i] is 2^i * G for every i in [0...63] and that Hash is known.
+func (zkpcp ZKPCurveParams) Validate() error {
+	if zkpcp.C == nil {
+		return errors.New("ZKPCurveParams: no group")
+	}
+	for _, gen := range []struct {
+		name string
+		P    ECPoint
+	}{{"G", zkpcp.G}, {"H", zkpcp.H}} {
+		if !zkpcp.C.Contains(gen.P) {
+			return fmt.Errorf("ZKPCurveParams: %s is not in %s", gen.name, zkpcp.C.Name())
+		}
+		if zkpcp.C.IsIdentity(gen.P) {
+			return fmt.Errorf("ZKPCurveParams: %s is the identity", gen.name)
+		}
+	}
+	if zkpcp.G.Equal(zkpcp.H) {
+		return errors.New("ZKPCurveParams: G and H are the same point")
+	}
+	if !zkpcp.Hash.Valid() {
+		return fmt.Errorf("ZKPCurveParams: unknown challenge hash %v", zkpcp.Hash)
+	}
+
+	if len(zkpcp.HPoints) != numHPoints {
+		return fmt.Errorf("ZKPCurveParams: expected %d HPoints, got %d", numHPoints, len(zkpcp.HPoints))
+	}
+	// Walk up by doubling rather than multiplying so this stays cheap.
+	expected := zkpcp.G
+	for i, P := range zkpcp.HPoints {
+		if !P.Equal(expected) {
+			return fmt.Errorf("ZKPCurveParams: HPoints[%d] is not 2^%d * G", i, i)
+		}
+		expected = zkpcp.C.Add(expected, expected)
 	}
+	return nil
 }
 
 // == Keygen ==
 
-func KeyGen(curve elliptic.Curve, base ECPoint) (ECPoint, *big.Int) {
-
-	sk, err := rand.Int(rand.Reader, curve.Params().N)
+// KeyGen generates a secret key sk and returns sk * base with it.  It panics
+// if crypto/rand.Reader fails, see GenerateKey.
+func KeyGen(curve Group, base ECPoint) (ECPoint, *big.Int) {
+	pk, sk, err := GenerateKey(curve, base, rand.Reader)
 	if err != nil {
 		panic(err)
 	}
-	pkX, pkY := curve.ScalarMult(base.X, base.Y, sk.Bytes())
+	return pk, sk
+}
 
-	return ECPoint{pkX, pkY}, sk
+// GenerateKey generates a secret key sk read from r and returns sk * base
+// with it.  GenerateSecretKey returns sk as a SecretScalar that can be wiped.
+func GenerateKey(curve Group, base ECPoint, r io.Reader) (ECPoint, *big.Int, error) {
+	sk, err := curve.RandomScalar(r)
+	if err != nil {
+		return Zero, nil, err
+	}
+	return secretScalarMult(curve, base, sk), sk, nil
 }
 
 // BigZero contains a cached instance of big.Int with value 0
@@ -67,143 +152,475 @@ var BigZero *big.Int
 
 // ============ ECPoint OPERATIONS ==================
 
+// ECPoint is an element of a Group, stored as affine coordinates.  The point
+// at infinity (the identity) is always (0, 0), see Zero.  An ECPoint with nil
+// coordinates, such as the zero value ECPoint{}, is not a point at all: the
+// arithmetic below passes it through unchanged and Equal never matches it, so
+// a missing point can not make a proof verify.
 type ECPoint struct {
 	X, Y *big.Int
 }
 
-// Zero is a cached variable containing ECPoint{big.NewInt(0), big.NewInt(0)}
+// Zero is a cached variable containing ECPoint{big.NewInt(0), big.NewInt(0)},
+// the identity element of every Group.
 var Zero ECPoint // initialized in init()
 
 // Equal returns true if points p (self) and p2 (arg) are the same.
 func (p ECPoint) Equal(p2 ECPoint) bool {
-	if p.X.Cmp(p2.X) == 0 && p2.Y.Cmp(p2.Y) == 0 {
-		return true
+	if p.isNil() || p2.isNil() {
+		return false
 	}
-	return false
+	return p.X.Cmp(p2.X) == 0 && p.Y.Cmp(p2.Y) == 0
+}
+
+// IsIdentity returns true if p is the point at infinity.
+func (p ECPoint) IsIdentity() bool {
+	return !p.isNil() && p.X.Sign() == 0 && p.Y.Sign() == 0
+}
+
+// isNil returns true if p is missing a coordinate.
+func (p ECPoint) isNil() bool {
+	return p.X == nil || p.Y == nil
 }
 
 // Mult multiplies point p by scalar s and returns the resulting point
 func (zkpcp ZKPCurveParams) Mult(p ECPoint, s *big.Int) ECPoint {
-
-	if p.X == nil && p.Y == nil { // Multiplying a nil point is "pointless". ha.
-		return ECPoint{nil, nil}
+	if p.isNil() || s == nil { // Multiplying a nil point is "pointless". ha.
+		return ECPoint{}
 	}
-
-	modS := new(big.Int).Mod(s, zkpcp.C.Params().N)
-
-	// if p.Equal(Zero) {
-	// 	logStuff("Mult: Trying to multiple with zero-point!\n")
-	// 	return p
-	// } else
-	if p.Equal(zkpcp.G) {
-		X, Y := zkpcp.C.ScalarBaseMult(modS.Bytes())
-		return ECPoint{X, Y}
+	if p.IsIdentity() {
+		return Zero
 	}
+	return zkpcp.C.ScalarMult(p, s)
+}
 
-	if p.Equal(zkpcp.H) {
-		X, Y := zkpcp.C.(*btcec.KoblitzCurve).ScalarBaseMultH(modS.Bytes())
-		return ECPoint{X, Y}
+// MultSecret is Mult for a secret s, such as a key, a witness or a nonce.  If
+// zkpcp.C implements SecretScalarMultiplier it runs in time independent of s.
+func (zkpcp ZKPCurveParams) MultSecret(p ECPoint, s *big.Int) ECPoint {
+	if p.isNil() || s == nil {
+		return ECPoint{}
 	}
-
-	X, Y := zkpcp.C.ScalarMult(p.X, p.Y, modS.Bytes())
-	return ECPoint{X, Y}
+	if p.IsIdentity() {
+		return Zero
+	}
+	return secretScalarMult(zkpcp.C, p, s)
 }
 
 // Add adds points p and p2 and returns the resulting point
 func (zkpcp ZKPCurveParams) Add(p, p2 ECPoint) ECPoint {
-	// if p.Equal(Zero) && p2.Equal(Zero) {
-	// 	return Zero
-	// } else
-	if p.Equal(Zero) && zkpcp.C.IsOnCurve(p2.X, p2.Y) {
+	switch {
+	case p.isNil() || p2.isNil():
+		return ECPoint{}
+	case p.IsIdentity():
 		return p2
-	} else if p2.Equal(Zero) && zkpcp.C.IsOnCurve(p.X, p.Y) {
+	case p2.IsIdentity():
 		return p
 	}
-
-	X, Y := zkpcp.C.Add(p.X, p.Y, p2.X, p2.Y)
-
-	return ECPoint{X, Y}
+	return zkpcp.C.Add(p, p2)
 }
 
+// Sub subtracts point p2 from p and returns the resulting point
 func (zkpcp ZKPCurveParams) Sub(p, p2 ECPoint) ECPoint {
-	// if p.Equal(Zero) && p2.Equal(Zero) {
-	// 	return Zero
-	// } else
-	if p.Equal(Zero) && zkpcp.C.IsOnCurve(p2.X, p2.Y) {
-		return zkpcp.Neg(p2)
-	} else if p2.Equal(Zero) && zkpcp.C.IsOnCurve(p.X, p.Y) {
-		return p
-	}
-
-	temp := zkpcp.Neg(p2)
-	X, Y := zkpcp.C.Add(p.X, p.Y, temp.X, temp.Y)
-
-	return ECPoint{X, Y}
+	return zkpcp.Add(p, zkpcp.Neg(p2))
 }
 
 // Neg returns the additive inverse of point p
 func (zkpcp ZKPCurveParams) Neg(p ECPoint) ECPoint {
-	negY := new(big.Int).Neg(p.Y)
-	modValue := new(big.Int).Mod(negY, zkpcp.C.Params().P)
-	return ECPoint{p.X, modValue}
+	switch {
+	case p.isNil():
+		return ECPoint{}
+	case p.IsIdentity():
+		return Zero
+	}
+	return zkpcp.C.Neg(p)
 }
 
 func (p ECPoint) Bytes() []byte {
 	return append(p.X.Bytes(), p.Y.Bytes()...)
 }
 
-// WriteECPoint write an ECPoint to io.Writer w
+// Bytes returns the canonical encoding of point p in zkpcp's group.  This is
+// what the proofs hash into their challenges.
+func (zkpcp ZKPCurveParams) Bytes(p ECPoint) []byte {
+	return zkpcp.C.Encode(p)
+}
+
+// WriteECPoint writes p to io.Writer w in the 33-byte compressed SEC1 form of
+// secp256k1 points.  It only handles secp256k1, the one group of the original
+// serialization: points of other groups, and the identity, are refused.  Use
+// Group.Encode for points of any group.
 func WriteECPoint(w io.Writer, p ECPoint) error {
-	err := wire.WriteVarBytes(w, p.X.Bytes())
+	return writePoint(w, Secp256k1(), p)
+}
+
+// ReadECPoint reads a point written by WriteECPoint from io.Reader r,
+// rejecting anything that is not a non-identity point on secp256k1.  Like
+// WriteECPoint it only handles secp256k1; use Group.Decode for other groups.
+func ReadECPoint(r io.Reader) (ECPoint, error) {
+	return readPoint(r, Secp256k1())
+}
+
+// proofFormatVersion is the first byte of every serialized proof.  Points are
+// written as fixed length encodings, see Group.PointSize, scalars as fixed
+// length canonical encodings, see writeScalar, and nested proofs inline.
+const proofFormatVersion = 1
+
+// writeProofHeader writes the format version, the name of the group a proof
+// was computed in and the hash its challenges were computed with, so that it
+// can be decoded and verified without being told.
+func writeProofHeader(w io.Writer, curve Group, hash ChallengeHash) error {
+	if !hash.Valid() {
+		return fmt.Errorf("unknown challenge hash %v", hash)
+	}
+	_, err := w.Write([]byte{proofFormatVersion})
 	if err != nil {
 		return err
 	}
-	err = wire.WriteVarBytes(w, p.Y.Bytes())
+	if err := wire.WriteVarBytes(w, []byte(curve.Name())); err != nil {
+		return err
+	}
+	_, err = w.Write([]byte{byte(hash)})
 	return err
 }
 
-// ReadECPoint reads an ECPoint from io.Reader r
-func ReadECPoint(r io.Reader) (ECPoint, error) {
-	xBytes, err := wire.ReadVarBytes(r, 32, "x")
+// readProofHeader reads a header written by writeProofHeader and returns the
+// group and the challenge hash of the proof.
+func readProofHeader(r io.Reader) (Group, ChallengeHash, error) {
+	var version [1]byte
+	if _, err := io.ReadFull(r, version[:]); err != nil {
+		return nil, 0, err
+	}
+	if version[0] != proofFormatVersion {
+		return nil, 0, fmt.Errorf("unsupported proof format version %d", version[0])
+	}
+	name, err := wire.ReadVarBytes(r, 64, "group")
 	if err != nil {
+		return nil, 0, err
+	}
+	curve, err := GroupByName(string(name))
+	if err != nil {
+		return nil, 0, err
+	}
+	var hash [1]byte
+	if _, err := io.ReadFull(r, hash[:]); err != nil {
+		return nil, 0, err
+	}
+	if h := ChallengeHash(hash[0]); !h.Valid() {
+		return nil, 0, fmt.Errorf("unknown challenge hash %d", hash[0])
+	}
+	return curve, ChallengeHash(hash[0]), nil
+}
+
+// proofCurve returns the group a proof was computed in.  Proofs assembled by
+// hand instead of by a prover or decoder carry none and are secp256k1 proofs.
+func proofCurve(curve Group) Group {
+	if curve == nil {
+		return Secp256k1()
+	}
+	return curve
+}
+
+// writePoint writes the curve.PointSize() byte encoding of p to w.  No honest
+// proof contains the identity, so it is refused rather than written.
+func writePoint(w io.Writer, curve Group, p ECPoint) error {
+	if p.isNil() || !curve.Contains(p) {
+		return fmt.Errorf("%s: cannot serialize a point that is not on the curve", curve.Name())
+	}
+	if p.IsIdentity() {
+		return fmt.Errorf("%s: cannot serialize the identity", curve.Name())
+	}
+	_, err := w.Write(curve.Encode(p))
+	return err
+}
+
+// readPoint reads a point written by writePoint from r, rejecting encodings of
+// points that are off the curve or the identity.
+func readPoint(r io.Reader, curve Group) (ECPoint, error) {
+	b := make([]byte, curve.PointSize())
+	if _, err := io.ReadFull(r, b); err != nil {
 		return Zero, err
 	}
-	yBytes, err := wire.ReadVarBytes(r, 32, "y")
+	p, err := curve.Decode(b)
 	if err != nil {
 		return Zero, err
 	}
-	return ECPoint{X: big.NewInt(0).SetBytes(xBytes), Y: big.NewInt(0).SetBytes(yBytes)}, nil
+	if p.IsIdentity() {
+		return Zero, fmt.Errorf("%s: unexpected identity point", curve.Name())
+	}
+	return p, nil
+}
+
+// countingReader counts the bytes read through it and remembers the last
+// error other than io.EOF that r returned.
+type countingReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	if err != nil && err != io.EOF {
+		c.err = err
+	}
+	return n, err
 }
 
-// WriteBigInt write a big.Int to io.Writer w
+// proofReader decodes the fields of a serialized proof from a stream.  The
+// first error is kept and every later read becomes a no-op, so a decoder can
+// read all of its fields and check the error once at the end with done.
+type proofReader struct {
+	name  string // proof type, for error messages
+	r     *countingReader
+	curve Group
+	hash  ChallengeHash
+	err   error
+}
+
+// newProofReader starts decoding a proof of type name from r by reading its
+// header.
+func newProofReader(name string, r io.Reader) *proofReader {
+	pr := &proofReader{name: name, r: &countingReader{r: r}}
+	curve, hash, err := readProofHeader(pr.r)
+	if err == io.EOF && pr.r.n == 0 {
+		// A clean end of stream between proofs, not a truncated proof.
+		pr.err = io.EOF
+		return pr
+	}
+	pr.fail("header", err)
+	pr.curve, pr.hash = curve, hash
+	return pr
+}
+
+// fail records err, if it is the first error, as an error reading field.
+func (pr *proofReader) fail(field string, err error) {
+	if err == nil || pr.err != nil {
+		return
+	}
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		pr.err = &ProofError{Op: pr.name, Err: ErrMalformedProof, Msg: fmt.Sprintf("reading %s: truncated input", field)}
+	case err == pr.r.err:
+		// The stream failed, there is nothing wrong with the proof.
+		pr.err = &ProofError{Op: pr.name, Err: err, Msg: "reading " + field}
+	default:
+		pr.err = &ProofError{Op: pr.name, Err: ErrMalformedProof, Msg: fmt.Sprintf("reading %s: %v", field, err)}
+	}
+}
+
+func (pr *proofReader) point(field string) ECPoint {
+	if pr.err != nil {
+		return ECPoint{}
+	}
+	p, err := readPoint(pr.r, pr.curve)
+	pr.fail(field, err)
+	return p
+}
+
+func (pr *proofReader) scalar(field string) *big.Int {
+	if pr.err != nil {
+		return nil
+	}
+	s, err := readScalar(pr.r, pr.curve)
+	pr.fail(field, err)
+	return s
+}
+
+func (pr *proofReader) challenge(field string) *big.Int {
+	if pr.err != nil {
+		return nil
+	}
+	c, err := readChallenge(pr.r)
+	pr.fail(field, err)
+	return c
+}
+
+// count reads the length of a list, which must be at most max.
+func (pr *proofReader) count(field string, max uint64) uint64 {
+	if pr.err != nil {
+		return 0
+	}
+	n, err := wire.ReadVarInt(pr.r)
+	if err == nil && n > max {
+		err = fmt.Errorf("count %d is larger than %d", n, max)
+	}
+	pr.fail(field, err)
+	if pr.err != nil {
+		return 0
+	}
+	return n
+}
+
+// proof reads a nested proof into p.
+func (pr *proofReader) proof(field string, p io.ReaderFrom) {
+	if pr.err != nil {
+		return
+	}
+	_, err := p.ReadFrom(pr.r)
+	if _, ok := err.(*ProofError); ok {
+		pr.err = err
+		return
+	}
+	pr.fail(field, err)
+}
+
+// done returns the number of bytes read and the first error.
+func (pr *proofReader) done() (int64, error) {
+	return pr.r.n, pr.err
+}
+
+// proofWriter is the counterpart of proofReader.  It writes the proof header
+// straight away and keeps the first error.
+type proofWriter struct {
+	w     io.Writer
+	curve Group
+	n     int64
+	err   error
+}
+
+func newProofWriter(w io.Writer, curve Group, hash ChallengeHash) *proofWriter {
+	pw := &proofWriter{w: w, curve: curve}
+	pw.err = writeProofHeader(pw, curve, hash)
+	return pw
+}
+
+// Write writes p to the underlying writer, counting the bytes written.
+func (pw *proofWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	pw.n += int64(n)
+	return n, err
+}
+
+func (pw *proofWriter) point(p ECPoint) {
+	if pw.err == nil {
+		pw.err = writePoint(pw, pw.curve, p)
+	}
+}
+
+func (pw *proofWriter) scalar(s *big.Int) {
+	if pw.err == nil {
+		pw.err = writeScalar(pw, pw.curve, s)
+	}
+}
+
+func (pw *proofWriter) challenge(c *big.Int) {
+	if pw.err == nil {
+		pw.err = writeChallenge(pw, c)
+	}
+}
+
+func (pw *proofWriter) count(n int) {
+	if pw.err == nil {
+		pw.err = wire.WriteVarInt(pw, uint64(n))
+	}
+}
+
+// proof writes nested proof p.
+func (pw *proofWriter) proof(p io.WriterTo) {
+	if pw.err == nil {
+		_, pw.err = p.WriteTo(pw)
+	}
+}
+
+// done returns the number of bytes written and the first error.
+func (pw *proofWriter) done() (int64, error) {
+	return pw.n, pw.err
+}
+
+// marshalProof returns the bytes p.WriteTo writes.
+func marshalProof(p io.WriterTo) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := p.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalProof reads p from b with p.ReadFrom, which must consume all of b.
+func unmarshalProof(name string, b []byte, p io.ReaderFrom) error {
+	r := bytes.NewReader(b)
+	_, err := p.ReadFrom(r)
+	if err == io.EOF {
+		err = &ProofError{Op: name, Err: ErrMalformedProof, Msg: "reading header: truncated input"}
+	}
+	if err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return &ProofError{Op: name, Err: ErrMalformedProof, Msg: fmt.Sprintf("%d trailing bytes", r.Len())}
+	}
+	return nil
+}
+
+// WriteBigInt writes secp256k1 scalar b to io.Writer w as 32 big-endian
+// bytes.  b must already be reduced, i.e. in [0, N).
 func WriteBigInt(w io.Writer, b *big.Int) error {
-	neg := []byte{0x00}
-	if b.Sign() < 0 {
-		neg = []byte{0x01}
+	return writeScalar(w, Secp256k1(), b)
+}
+
+// ReadBigInt reads a scalar written by WriteBigInt from io.Reader r,
+// rejecting values that are not reduced mod N.
+func ReadBigInt(r io.Reader) (*big.Int, error) {
+	return readScalar(r, Secp256k1())
+}
+
+// scalarSize returns the length in bytes of curve's scalar encoding.
+func scalarSize(curve Group) int {
+	return (curve.Order().BitLen() + 7) / 8
+}
+
+// writeScalar writes s to w as scalarSize(curve) big-endian bytes.  Only the
+// reduced representative is accepted, so every scalar has exactly one
+// encoding and proofs can not be altered without invalidating them.
+func writeScalar(w io.Writer, curve Group, s *big.Int) error {
+	if s == nil || s.Sign() < 0 || s.Cmp(curve.Order()) >= 0 {
+		return fmt.Errorf("%s: scalar is not reduced mod N", curve.Name())
 	}
-	err := wire.WriteVarBytes(w, append(neg, b.Bytes()...))
+	b := make([]byte, scalarSize(curve))
+	s.FillBytes(b)
+	_, err := w.Write(b)
 	return err
 }
 
-// ReadBigInt reads a big.Int from io.Reader r
-func ReadBigInt(r io.Reader) (*big.Int, error) {
-	bBytes, err := wire.ReadVarBytes(r, 32, "")
-	if err != nil {
+// readScalar reads a scalar written by writeScalar from r.
+func readScalar(r io.Reader, curve Group) (*big.Int, error) {
+	b := make([]byte, scalarSize(curve))
+	if _, err := io.ReadFull(r, b); err != nil {
 		return nil, err
 	}
-	newInt := big.NewInt(0).SetBytes(bBytes[1:])
-	if bBytes[0] == 0x01 {
-		newInt.Neg(newInt)
+	s := new(big.Int).SetBytes(b)
+	if s.Cmp(curve.Order()) >= 0 {
+		return nil, fmt.Errorf("%s: scalar is not reduced mod N", curve.Name())
+	}
+	return s, nil
+}
+
+// writeChallenge writes the challenge c of a compact proof to w as
+// compactChallengeSize big-endian bytes.
+func writeChallenge(w io.Writer, c *big.Int) error {
+	if validChallenge(c) != nil {
+		return fmt.Errorf("challenge is not in [0, 2^%d)", 8*compactChallengeSize)
+	}
+	b := make([]byte, compactChallengeSize)
+	c.FillBytes(b)
+	_, err := w.Write(b)
+	return err
+}
+
+// readChallenge reads a challenge written by writeChallenge from r.
+func readChallenge(r io.Reader) (*big.Int, error) {
+	b := make([]byte, compactChallengeSize)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
 	}
-	return newInt, nil
+	return new(big.Int).SetBytes(b), nil
 }
 
 // CommitR uses the Public Key (pk) and a random number (r) to
 // generate a commitment of r as an ECPoint
 func CommitR(zkpcp ZKPCurveParams, pk ECPoint, r *big.Int) ECPoint {
-	newR := new(big.Int).Mod(r, zkpcp.C.Params().N)
-	X, Y := zkpcp.C.ScalarMult(pk.X, pk.Y, newR.Bytes()) // {commitR.X,commitR.Y} = newR * {pk.X, pk.Y}
-	return ECPoint{X, Y}
+	return zkpcp.MultSecret(pk, r) // commitR = r * pk
 }
 
 // VerifyR checks if the point in question is a valid commitment of r
@@ -219,7 +636,7 @@ func VerifyR(zkpcp ZKPCurveParams, rt ECPoint, pk ECPoint, r *big.Int) bool {
 // commitment.
 func PedCommit(zkpcp ZKPCurveParams, value *big.Int) (ECPoint, *big.Int, error) {
 	// randomValue = rand() mod N
-	randomValue, err := rand.Int(rand.Reader, zkpcp.C.Params().N)
+	randomValue, err := zkpcp.randomScalar()
 	if err != nil {
 		return Zero, nil, err
 	}
@@ -230,12 +647,12 @@ func PedCommit(zkpcp ZKPCurveParams, value *big.Int) (ECPoint, *big.Int, error)
 func PedCommitR(zkpcp ZKPCurveParams, value, randomValue *big.Int) ECPoint {
 
 	// modValue = value mod N
-	modValue := new(big.Int).Mod(value, zkpcp.C.Params().N)
-	modRandom := new(big.Int).Mod(randomValue, zkpcp.C.Params().N)
+	modValue := zkpcp.C.ReduceScalar(value)
+	modRandom := zkpcp.C.ReduceScalar(randomValue)
 
 	// mG, rH :: lhs, rhs
-	lhs := zkpcp.Mult(zkpcp.G, modValue)
-	rhs := zkpcp.Mult(zkpcp.H, modRandom)
+	lhs := zkpcp.MultSecret(zkpcp.G, modValue)
+	rhs := zkpcp.MultSecret(zkpcp.H, modRandom)
 
 	//mG + rH
 	return zkpcp.Add(lhs, rhs)
@@ -249,16 +666,28 @@ func Open(zkpcp ZKPCurveParams, value, randomValue *big.Int, pcomm ECPoint) bool
 
 // ====== Generalized Hash Function =========
 
-// GenerateChallenge hashes the passed byte arrays using SHA-256, and then returns
-// the resulting hash as a big.Int modulo the order of the curve base point
+// challengeHashSize is the number of bytes of hash output reduced into a
+// challenge: twice the size of every group order, so the reduction modulo N
+// is biased by at most 2^-256.
+const challengeHashSize = 64
+
+// GenerateChallenge hashes the concatenation of the passed byte arrays into a
+// scalar: it takes 512 bits of zkpcp.Hash output over them, see ChallengeHash,
+// and returns that modulo the order of the curve base point.  Reducing a
+// 256-bit hash instead would make challenges below 2^256 mod N noticeably
+// more likely on groups like ristretto255.
 func GenerateChallenge(zkpcp ZKPCurveParams, arr ...[]byte) *big.Int {
-	hasher := sha256.New()
-	for _, v := range arr {
-		hasher.Write(v)
+	out := zkpcp.Hash.wide(bytes.Join(arr, nil))
+	return zkpcp.C.ReduceScalar(new(big.Int).SetBytes(out))
+}
+
+// pointsChallenge is GenerateChallenge over the encodings of points.
+func pointsChallenge(zkpcp ZKPCurveParams, points ...ECPoint) *big.Int {
+	arr := make([][]byte, len(points))
+	for i, p := range points {
+		arr[i] = zkpcp.Bytes(p)
 	}
-	c := new(big.Int).SetBytes(hasher.Sum(nil))
-	c = new(big.Int).Mod(c, zkpcp.C.Params().N)
-	return c
+	return GenerateChallenge(zkpcp, arr...)
 }
 
 // ====== init =========
@@ -1,54 +1,115 @@
 package zksigma
 
 import (
+	"bytes"
 	"crypto/rand"
+	"crypto/sha3"
 	"math/big"
 	"testing"
+
+	"github.com/mit-dci/zksigma/btcec"
+	"github.com/mit-dci/zksigma/wire"
 )
 
 func TestECPointMethods(t *testing.T) {
+	forEachCurve(t, testECPointMethods)
+}
+
+func testECPointMethods(t *testing.T, zkpcp ZKPCurveParams) {
 	v := big.NewInt(3)
-	p := TestCurve.Mult(TestCurve.G, v)
-	negp := TestCurve.Neg(p)
-	sum := TestCurve.Add(p, negp)
+	p := zkpcp.Mult(zkpcp.G, v)
+	negp := zkpcp.Neg(p)
+	sum := zkpcp.Add(p, negp)
 	if !sum.Equal(Zero) {
 		t.Logf("p : %v\n", p)
 		t.Logf("negp : %v\n", negp)
 		t.Logf("sum : %v\n", sum)
 		t.Fatalf("p + -p should be 0\n")
 	}
-	negnegp := TestCurve.Neg(negp)
+	negnegp := zkpcp.Neg(negp)
 	if !negnegp.Equal(p) {
 		t.Logf("p : %v\n", p)
 		t.Logf("negnegp : %v\n", negnegp)
 		t.Fatalf("-(-p) should be p\n")
 	}
-	sum = TestCurve.Add(p, Zero)
+	sum = zkpcp.Add(p, Zero)
 	if !sum.Equal(p) {
 		t.Logf("p : %v\n", p)
 		t.Logf("sum : %v\n", sum)
 		t.Fatalf("p + 0 should be p\n")
 	}
+	if p.Equal(negp) {
+		t.Fatalf("p and -p share an X coordinate but should not be equal\n")
+	}
+}
+
+func TestIdentity(t *testing.T) {
+	forEachCurve(t, testIdentity)
+}
+
+func testIdentity(t *testing.T, zkpcp ZKPCurveParams) {
+	p := zkpcp.Mult(zkpcp.G, big.NewInt(5))
+
+	if !Zero.IsIdentity() || p.IsIdentity() || (ECPoint{}).IsIdentity() {
+		t.Fatalf("only Zero should be the identity\n")
+	}
+	if !zkpcp.C.IsIdentity(zkpcp.C.Identity()) || !zkpcp.C.Identity().Equal(Zero) {
+		t.Fatalf("the group identity should be Zero\n")
+	}
+
+	for name, P := range map[string]ECPoint{
+		"0 * p":   zkpcp.Mult(p, big.NewInt(0)),
+		"N * p":   zkpcp.Mult(p, zkpcp.C.Order()),
+		"k * 0":   zkpcp.Mult(Zero, big.NewInt(7)),
+		"0 + 0":   zkpcp.Add(Zero, Zero),
+		"-0":      zkpcp.Neg(Zero),
+		"p - p":   zkpcp.Sub(p, p),
+		"0 - 0":   zkpcp.Sub(Zero, Zero),
+		"commit0": PedCommitR(zkpcp, big.NewInt(0), big.NewInt(0)),
+	} {
+		if !P.IsIdentity() {
+			t.Fatalf("%s should be the identity, got %v\n", name, P)
+		}
+	}
+
+	if !zkpcp.Sub(Zero, p).Equal(zkpcp.Neg(p)) || !zkpcp.Sub(p, Zero).Equal(p) {
+		t.Fatalf("subtracting with the identity is broken\n")
+	}
+
+	// A point with nil coordinates is not a point and must never compare equal,
+	// not even to itself.
+	var missing ECPoint
+	if missing.Equal(missing) || missing.Equal(Zero) || Zero.Equal(missing) {
+		t.Fatalf("nil points should not be equal to anything\n")
+	}
+	if !zkpcp.Mult(missing, big.NewInt(2)).isNil() || !zkpcp.Add(missing, p).isNil() ||
+		!zkpcp.Add(p, missing).isNil() || !zkpcp.Neg(missing).isNil() {
+		t.Fatalf("arithmetic on nil points should give a nil point\n")
+	}
 }
 
 func TestZkpCryptoStuff(t *testing.T) {
+	forEachCurve(t, testZkpCryptoStuff)
+}
+
+func testZkpCryptoStuff(t *testing.T, zkpcp ZKPCurveParams) {
 	value := big.NewInt(-100)
 
-	testCommit, randomValue, err := PedCommit(TestCurve, value) // CM = xG + rH
+	testCommit, randomValue, err := PedCommit(zkpcp, value) // CM = xG + rH
 
 	if err != nil {
 		t.Fatalf("%v\n", err)
 	}
 
-	value = new(big.Int).Mod(value, TestCurve.C.Params().N) // v % p
+	value = new(big.Int).Mod(value, zkpcp.C.Order()) // v % p
 
-	ValEC := TestCurve.Mult(TestCurve.G, value) // vG
-	InvValEC := TestCurve.Neg(ValEC)            // 1/vG (actually mod operation but whatever you get it)
+	ValEC := zkpcp.Mult(zkpcp.G, value) // vG
+	InvValEC := zkpcp.Neg(ValEC)            // 1/vG (actually mod operation but whatever you get it)
 
 	t.Logf("  vG : %v --- value : %v \n", ValEC, value)
 	t.Logf("1/vG : %v\n", InvValEC)
 
-	temp := TestCurve.Add(ValEC, InvValEC)
+	temp := zkpcp.Add(ValEC, InvValEC)
 	t.Logf("TestZkpCrypto:")
 	t.Logf("Added the above: %v\n", temp)
 
@@ -58,8 +119,8 @@ func TestZkpCryptoStuff(t *testing.T) {
 		t.Fatalf("Failed Addition of inverse points failed")
 	}
 
-	testOpen := TestCurve.Add(InvValEC, testCommit)    // 1/vG + vG + rH ?= rH (1/vG + vG = 0, hopefully)
-	RandEC := TestCurve.Mult(TestCurve.H, randomValue) // rH
+	testOpen := zkpcp.Add(InvValEC, testCommit)    // 1/vG + vG + rH ?= rH (1/vG + vG = 0, hopefully)
+	RandEC := zkpcp.Mult(zkpcp.H, randomValue) // rH
 
 	if !RandEC.Equal(testOpen) {
 		t.Logf("RandEC : %v\n", RandEC)
@@ -69,33 +130,41 @@ func TestZkpCryptoStuff(t *testing.T) {
 }
 
 func TestZkpCryptoCommitR(t *testing.T) {
+	forEachCurve(t, testZkpCryptoCommitR)
+}
 
-	u, err := rand.Int(rand.Reader, TestCurve.C.Params().N)
+func testZkpCryptoCommitR(t *testing.T, zkpcp ZKPCurveParams) {
+
+	u, err := rand.Int(rand.Reader, zkpcp.C.Order())
 	if err != nil {
 		t.Fatalf("%v\n", err)
 	}
 
-	testCommit := CommitR(TestCurve, TestCurve.H, u)
+	testCommit := CommitR(zkpcp, zkpcp.H, u)
 
-	if !(VerifyR(TestCurve, testCommit, TestCurve.H, u)) {
+	if !(VerifyR(zkpcp, testCommit, zkpcp.H, u)) {
 		t.Logf("testCommit: %v\n", testCommit)
-		t.Logf("TestCurve.H: %v, \n", TestCurve.H)
+		t.Logf("zkpcp.H: %v, \n", zkpcp.H)
 		t.Logf("u : %v\n", u)
 		t.Fatalf("testCommit should have passed verification\n")
 	}
 }
 
 func TestPedersenCommit(t *testing.T) {
+	forEachCurve(t, testPedersenCommit)
+}
+
+func testPedersenCommit(t *testing.T, zkpcp ZKPCurveParams) {
 
 	x := big.NewInt(1000)
 	badx := big.NewInt(1234)
 
-	commit, u, err := PedCommit(TestCurve, x)
+	commit, u, err := PedCommit(zkpcp, x)
 	if err != nil {
 		t.Fatalf("%v\n", err)
 	}
 
-	commitR := PedCommitR(TestCurve, x, u)
+	commitR := PedCommitR(zkpcp, x, u)
 
 	if !commit.Equal(commitR) {
 		t.Logf("x : %v --- u : %v\n", x, u)
@@ -104,14 +173,14 @@ func TestPedersenCommit(t *testing.T) {
 		t.Fatalf("commit and commitR should be equal")
 	}
 
-	if !Open(TestCurve, x, u, commit) || !Open(TestCurve, x, u, commitR) {
+	if !Open(zkpcp, x, u, commit) || !Open(zkpcp, x, u, commitR) {
 		t.Logf("x : %v --- u : %v\n", x, u)
 		t.Logf("commit: %v\n", commit)
 		t.Logf("commitR: %v\n", commitR)
 		t.Fatalf("commit and/or commitR did not successfully open")
 	}
 
-	if Open(TestCurve, badx, u.Neg(u), commit) || Open(TestCurve, badx, u.Neg(u), commitR) {
+	if Open(zkpcp, badx, u.Neg(u), commit) || Open(zkpcp, badx, u.Neg(u), commitR) {
 		t.Logf("x : %v --- u : %v\n", x, u)
 		t.Logf("commit: %v\n", commit)
 		t.Logf("commitR: %v\n", commitR)
@@ -120,6 +189,213 @@ func TestPedersenCommit(t *testing.T) {
 
 }
 
+func TestECPointSerialization(t *testing.T) {
+	p := TestCurve.Mult(TestCurve.G, big.NewInt(42))
+
+	var buf bytes.Buffer
+	if err := WriteECPoint(&buf, p); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	if buf.Len() != 33 {
+		t.Fatalf("expected a 33 byte encoding, got %d bytes\n", buf.Len())
+	}
+	q, err := ReadECPoint(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	if !q.Equal(p) {
+		t.Fatalf("read %v, wrote %v\n", q, p)
+	}
+
+	if err := WriteECPoint(&buf, Zero); err == nil {
+		t.Fatalf("writing the identity should fail\n")
+	}
+	if err := WriteECPoint(&buf, ECPoint{big.NewInt(1), big.NewInt(1)}); err == nil {
+		t.Fatalf("writing an off-curve point should fail\n")
+	}
+
+	if _, err := ReadECPoint(bytes.NewReader(make([]byte, 33))); err == nil {
+		t.Fatalf("reading the identity should fail\n")
+	}
+	if _, err := ReadECPoint(bytes.NewReader(buf.Bytes()[:32])); err == nil {
+		t.Fatalf("reading a truncated point should fail\n")
+	}
+
+	// Find an x with no matching y; it must not decode to anything.
+	x := big.NewInt(1)
+	for {
+		y2 := new(big.Int).Exp(x, big.NewInt(3), btcec.S256().P)
+		y2.Add(y2, btcec.S256().B)
+		if new(big.Int).ModSqrt(y2.Mod(y2, btcec.S256().P), btcec.S256().P) == nil {
+			break
+		}
+		x.Add(x, big.NewInt(1))
+	}
+	offCurve := make([]byte, 33)
+	offCurve[0] = 0x02
+	x.FillBytes(offCurve[1:])
+	if _, err := ReadECPoint(bytes.NewReader(offCurve)); err == nil {
+		t.Fatalf("reading an x coordinate that is not on the curve should fail\n")
+	}
+}
+
+func TestScalarSerialization(t *testing.T) {
+	N := TestCurve.C.Order()
+
+	for _, s := range []*big.Int{big.NewInt(0), big.NewInt(1), new(big.Int).Sub(N, big.NewInt(1))} {
+		var buf bytes.Buffer
+		if err := WriteBigInt(&buf, s); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+		if buf.Len() != 32 {
+			t.Fatalf("expected a 32 byte encoding of %v, got %d bytes\n", s, buf.Len())
+		}
+		r, err := ReadBigInt(&buf)
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+		if r.Cmp(s) != 0 {
+			t.Fatalf("read %v, wrote %v\n", r, s)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, s := range []*big.Int{big.NewInt(-1), N, new(big.Int).Add(N, big.NewInt(1))} {
+		if err := WriteBigInt(&buf, s); err == nil {
+			t.Fatalf("writing unreduced scalar %v should fail\n", s)
+		}
+	}
+
+	// N + 1 fits in 32 bytes but is the same scalar as 1.
+	nPlusOne := make([]byte, 32)
+	new(big.Int).Add(N, big.NewInt(1)).FillBytes(nPlusOne)
+	if _, err := ReadBigInt(bytes.NewReader(nPlusOne)); err == nil {
+		t.Fatalf("reading an unreduced scalar should fail\n")
+	}
+	if _, err := ReadBigInt(bytes.NewReader(nPlusOne[:31])); err == nil {
+		t.Fatalf("reading a truncated scalar should fail\n")
+	}
+}
+
+func TestProofFormatVersion(t *testing.T) {
+	x := big.NewInt(7)
+	proof, err := NewGSPFSProof(TestCurve, TestCurve.Mult(TestCurve.G, x), x)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	b := proof.Bytes()
+	if b[0] != proofFormatVersion {
+		t.Fatalf("proof should start with format version %d\n", proofFormatVersion)
+	}
+	b[0] = proofFormatVersion + 1
+	if _, err := NewGSPFSProofFromBytes(b); err == nil {
+		t.Fatalf("unknown format versions should be rejected\n")
+	}
+}
+
+func TestMalformedProofBytes(t *testing.T) {
+	x := big.NewInt(7)
+	gspfs, _ := NewGSPFSProof(TestCurve, TestCurve.Mult(TestCurve.G, x), x)
+	rp, _, err := NewRangeProof(TestCurve, big.NewInt(5))
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	CM, _, _ := PedCommit(TestCurve, x)
+	PK, sk := KeyGen(TestCurve.C, TestCurve.H)
+	abc, _ := NewABCProof(TestCurve, CM, TestCurve.Mult(PK, x), x, sk, Right)
+
+	decoders := []struct {
+		name   string
+		b      []byte
+		decode func([]byte) error
+	}{
+		{"GSPFS", gspfs.Bytes(), func(b []byte) error { _, err := NewGSPFSProofFromBytes(b); return err }},
+		{"Range", rp.Bytes(), func(b []byte) error { _, err := NewRangeProofFromBytes(b); return err }},
+		{"ABC", abc.Bytes(), func(b []byte) error { _, err := NewABCProofFromBytes(b); return err }},
+	}
+	for _, d := range decoders {
+		if err := d.decode(d.b); err != nil {
+			t.Fatalf("%s: well formed proof should decode: %v\n", d.name, err)
+		}
+		for n := 0; n < len(d.b); n++ {
+			if err := d.decode(d.b[:n]); err == nil {
+				t.Fatalf("%s: proof truncated to %d bytes should not decode\n", d.name, n)
+			}
+		}
+		if err := d.decode(append(d.b, 0x00)); err == nil {
+			t.Fatalf("%s: proof with trailing bytes should not decode\n", d.name)
+		}
+	}
+
+	// A huge tuple count must be refused before anything is allocated for it.
+	var buf bytes.Buffer
+	writeProofHeader(&buf, TestCurve.C, TestCurve.Hash)
+	writePoint(&buf, TestCurve.C, rp.ProofAggregate)
+	writeScalar(&buf, TestCurve.C, rp.ProofE)
+	wire.WriteVarInt(&buf, 1<<40)
+	if _, err := NewRangeProofFromBytes(buf.Bytes()); err == nil {
+		t.Fatalf("oversized tuple count should not decode\n")
+	}
+
+	// Corrupt the first point of the GSPFS proof.
+	b := gspfs.Bytes()
+	b[len(b)-2*32-2*33] ^= 0xff
+	if _, err := NewGSPFSProofFromBytes(b); err == nil {
+		t.Fatalf("invalid point should not decode\n")
+	}
+}
+
+func TestNewZKPCurveParams(t *testing.T) {
+	forEachCurve(t, testNewZKPCurveParams)
+}
+
+func testNewZKPCurveParams(t *testing.T, zkpcp ZKPCurveParams) {
+	if err := zkpcp.Validate(); err != nil {
+		t.Fatalf("test curve should be valid: %v\n", err)
+	}
+
+	a, err := NewZKPCurveParams(zkpcp.C, []byte("seed"))
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	b, _ := NewZKPCurveParams(zkpcp.C, []byte("seed"))
+	c, _ := NewZKPCurveParams(zkpcp.C, []byte("another seed"))
+	if !a.H.Equal(b.H) {
+		t.Fatalf("the same seed should give the same H\n")
+	}
+	if a.H.Equal(c.H) {
+		t.Fatalf("different seeds should give different H\n")
+	}
+
+	bad := a
+	bad.H = a.G
+	if bad.Validate() == nil {
+		t.Fatalf("G == H should not validate\n")
+	}
+
+	bad = a
+	bad.H = zkpcp.C.Identity()
+	if bad.Validate() == nil {
+		t.Fatalf("an identity H should not validate\n")
+	}
+
+	bad = a
+	bad.HPoints = append([]ECPoint(nil), a.HPoints...)
+	bad.HPoints[5] = a.HPoints[6]
+	if bad.Validate() == nil {
+		t.Fatalf("HPoints[5] != 2^5 * G should not validate\n")
+	}
+
+	bad.HPoints = a.HPoints[:40]
+	if bad.Validate() == nil {
+		t.Fatalf("short HPoints should not validate\n")
+	}
+
+	if a.WithHash(0xff).Validate() == nil {
+		t.Fatalf("an unknown hash should not validate\n")
+	}
+}
+
 // TODO: make a ton more test cases
 
 type etx struct {
@@ -130,6 +406,10 @@ type etx struct {
 
 //TODO: make a sk-pk that is consistant across all test cases
 func TestAverages_Basic(t *testing.T) {
+	forEachCurve(t, testAverages_Basic)
+}
+
+func testAverages_Basic(t *testing.T, zkpcp ZKPCurveParams) {
 
 	// remember to change both number here...
 	numTx := 100
@@ -138,23 +418,23 @@ func TestAverages_Basic(t *testing.T) {
 	totalValue := big.NewInt(0)
 	totalRand := big.NewInt(0)
 	txn := make([]etx, numTx)
-	sk, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
-	PK := TestCurve.Mult(TestCurve.H, sk)
+	sk, _ := rand.Int(rand.Reader, zkpcp.C.Order())
+	PK := zkpcp.Mult(zkpcp.H, sk)
 	var value *big.Int
 	var commRand *big.Int
 	var err error
 
 	// Generate
 	for ii := 0; ii < numTx; ii++ {
-		value, _ = rand.Int(rand.Reader, TestCurve.C.Params().N)
+		value, _ = rand.Int(rand.Reader, zkpcp.C.Order())
 		totalValue.Add(totalValue, value)
-		txn[ii].CM, commRand, err = PedCommit(TestCurve, value)
+		txn[ii].CM, commRand, err = PedCommit(zkpcp, value)
 		if err != nil {
 			t.Fatalf("%v\n", err)
 		}
 		totalRand.Add(totalRand, commRand)
-		txn[ii].CMTok = TestCurve.Mult(PK, commRand)
-		txn[ii].ABCP, _ = NewABCProof(TestCurve, txn[ii].CM, txn[ii].CMTok, value, sk, Right)
+		txn[ii].CMTok = zkpcp.Mult(PK, commRand)
+		txn[ii].ABCP, _ = NewABCProof(zkpcp, txn[ii].CM, txn[ii].CMTok, value, sk, Right)
 	}
 
 	// Purely for testing purposes, usually this is computed at the end by auditor
@@ -175,33 +455,33 @@ func TestAverages_Basic(t *testing.T) {
 	totalCTok := Zero
 
 	for ii := 0; ii < numTx; ii++ {
-		totalCM = TestCurve.Add(txn[ii].CM, totalCM)
-		totalCMTok = TestCurve.Add(txn[ii].CMTok, totalCMTok)
-		totalC = TestCurve.Add(txn[ii].ABCP.C, totalC)
-		totalCTok = TestCurve.Add(txn[ii].ABCP.CToken, totalCTok)
+		totalCM = zkpcp.Add(txn[ii].CM, totalCM)
+		totalCMTok = zkpcp.Add(txn[ii].CMTok, totalCMTok)
+		totalC = zkpcp.Add(txn[ii].ABCP.C, totalC)
+		totalCTok = zkpcp.Add(txn[ii].ABCP.CToken, totalCTok)
 	}
 
 	// makes the call look cleaner
-	B1 := TestCurve.Add(totalC, TestCurve.Neg(TestCurve.Mult(TestCurve.G, numTranx)))
+	B1 := zkpcp.Add(totalC, zkpcp.Neg(zkpcp.Mult(zkpcp.G, numTranx)))
 	R1 := totalCTok
-	B2 := TestCurve.H
+	B2 := zkpcp.H
 	R2 := PK
 
-	eProofNumTx, status := NewEquivalenceProof(TestCurve, B1, R1, B2, R2, sk)
+	eProofNumTx, status := NewEquivalenceProof(zkpcp, B1, R1, B2, R2, sk)
 
 	if status != nil {
-		proofStatus(status.(*errorProof))
+		proofStatus(status.(*ProofError))
 		t.Logf("Average Test: equivalence proof failed to generate for numTx\n")
 		t.Fatalf("Averages did not generate correct NUMTX equivalence proof\n")
 	}
 
-	B1 = TestCurve.Add(totalCM, TestCurve.Neg(TestCurve.Mult(TestCurve.G, totalValue)))
+	B1 = zkpcp.Add(totalCM, zkpcp.Neg(zkpcp.Mult(zkpcp.G, totalValue)))
 	R1 = totalCMTok
 
-	eProofValue, status1 := NewEquivalenceProof(TestCurve, B1, R1, B2, R2, sk)
+	eProofValue, status1 := NewEquivalenceProof(zkpcp, B1, R1, B2, R2, sk)
 
 	if status1 != nil {
-		proofStatus(status1.(*errorProof))
+		proofStatus(status1.(*ProofError))
 		t.Logf("Average Test: equivalence proof failed to generate for value sum\n")
 		t.Fatalf("Averages did not generate correct VALUE equivalence proof\n")
 	}
@@ -214,12 +494,12 @@ func TestAverages_Basic(t *testing.T) {
 	// auditor WILL verify eProofs and then perform the final average calculation, shown below
 	// ======== AUDITOR PROCESS ===========
 
-	B1 = TestCurve.Add(totalC, TestCurve.Neg(TestCurve.Mult(TestCurve.G, numTranx)))
+	B1 = zkpcp.Add(totalC, zkpcp.Neg(zkpcp.Mult(zkpcp.G, numTranx)))
 	R1 = totalCTok
-	B2 = TestCurve.H
+	B2 = zkpcp.H
 	R2 = PK
 
-	checkTx, err := eProofNumTx.Verify(TestCurve, B1, R1, B2, R2)
+	checkTx, err := eProofNumTx.Verify(zkpcp, B1, R1, B2, R2)
 
 	if err != nil {
 		t.Fatalf("Error while calling equivalence proof verify: %s", err.Error())
@@ -230,10 +510,10 @@ func TestAverages_Basic(t *testing.T) {
 		t.Fatalf("equivalence proof of NUMTX did not verify\n")
 	}
 
-	B1 = TestCurve.Add(totalCM, TestCurve.Neg(TestCurve.Mult(TestCurve.G, totalValue)))
+	B1 = zkpcp.Add(totalCM, zkpcp.Neg(zkpcp.Mult(zkpcp.G, totalValue)))
 	R1 = totalCMTok
 
-	checkVal, err := eProofValue.Verify(TestCurve, B1, R1, B2, R2)
+	checkVal, err := eProofValue.Verify(zkpcp, B1, R1, B2, R2)
 
 	if err != nil {
 		t.Fatalf("Error while calling equivalence proof verify: %s", err.Error())
@@ -276,7 +556,7 @@ func TestAverages_Basic(t *testing.T) {
 
 // ============== BENCHMARKS =================
 func BenchmarkPedCommit(b *testing.B) {
-	value, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	value, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 	b.ResetTimer()
 	for ii := 0; ii < b.N; ii++ {
 		PedCommit(TestCurve, value)
@@ -284,8 +564,8 @@ func BenchmarkPedCommit(b *testing.B) {
 }
 
 func BenchmarkPedCommitR(b *testing.B) {
-	value, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
-	randVal, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	value, _ := rand.Int(rand.Reader, TestCurve.C.Order())
+	randVal, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 	b.ResetTimer()
 	for ii := 0; ii < b.N; ii++ {
 		PedCommitR(TestCurve, value, randVal)
@@ -293,11 +573,33 @@ func BenchmarkPedCommitR(b *testing.B) {
 }
 
 func BenchmarkOpen(b *testing.B) {
-	value, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
-	randVal, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	value, _ := rand.Int(rand.Reader, TestCurve.C.Order())
+	randVal, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 	CM := PedCommitR(TestCurve, value, randVal)
 	b.ResetTimer()
 	for ii := 0; ii < b.N; ii++ {
 		Open(TestCurve, value, randVal, CM)
 	}
 }
+
+func TestGenerateChallenge(t *testing.T) {
+	forEachCurve(t, testGenerateChallenge)
+}
+
+func testGenerateChallenge(t *testing.T, zkpcp ZKPCurveParams) {
+	a, b := []byte("zksigma"), zkpcp.Bytes(zkpcp.H)
+	c := GenerateChallenge(zkpcp, a, b)
+
+	// 512 bits of SHAKE256 over the concatenated inputs, reduced modulo N
+	wide := sha3.SumSHAKE256(append(append([]byte(nil), a...), b...), 64)
+	want := new(big.Int).Mod(new(big.Int).SetBytes(wide), zkpcp.C.Order())
+	if c.Cmp(want) != 0 {
+		t.Fatalf("expected challenge %x, got %x\n", want, c)
+	}
+	if GenerateChallenge(zkpcp, append(a, b...)).Cmp(c) != 0 {
+		t.Fatalf("challenge should only depend on the concatenated input\n")
+	}
+	if pointsChallenge(zkpcp, zkpcp.G, zkpcp.H).Cmp(GenerateChallenge(zkpcp, zkpcp.Bytes(zkpcp.G), b)) != 0 {
+		t.Fatalf("pointsChallenge should hash the point encodings\n")
+	}
+}
@@ -1,9 +1,7 @@
 package zksigma
 
 import (
-	"bytes"
-	"crypto/rand"
-	"fmt"
+	"io"
 	"math/big"
 )
 
@@ -25,7 +23,7 @@ import (
 //  s = u1 + deltaC * x
 //  T1, T2, c, deltaC, u3, s, u2 -MAP-> T1, T2, c, c1, c2, s1, s2
 //                                      c ?= HASH(T1, T2, G, A, B)
-//                                      c ?= c1 + c2 // mod zkpcp.C.Params().N
+//                                      c ?= c1 + c2 // mod zkpcp.C.Order()
 //                                      s1G ?= T1 + c1A
 //                                      s2G ?= T2 + c2A
 //  To prove y instead:
@@ -50,6 +48,9 @@ type DisjunctiveProof struct {
 	C2 *big.Int
 	S1 *big.Int
 	S2 *big.Int
+
+	curve Group         // group the proof was computed in
+	hash  ChallengeHash // hash the challenges were computed with
 }
 
 // NewDisjunctiveProof generates a disjunctive proof. Base1 and Base2 are our chosen base points.
@@ -59,7 +60,17 @@ type DisjunctiveProof struct {
 func NewDisjunctiveProof(
 	zkpcp ZKPCurveParams, Base1, Result1, Base2, Result2 ECPoint, x *big.Int, option Side) (*DisjunctiveProof, error) {
 
-	modValue := new(big.Int).Mod(x, zkpcp.C.Params().N)
+	in := zkpcp.validateInputs(DisjunctiveProofType, "NewDisjunctiveProof")
+	in.point("Base1", Base1)
+	in.point("Result1", Result1)
+	in.point("Base2", Base2)
+	in.point("Result2", Result2)
+	if err := in.done(); err != nil {
+		return &DisjunctiveProof{}, err
+	}
+
+	modValue := zkpcp.C.ReduceScalar(x)
+	defer WipeScalars(modValue)
 
 	// Declaring them like this because Golang crys otherwise
 	var ProveBase, ProveResult, OtherBase, OtherResult ECPoint
@@ -76,58 +87,68 @@ func NewDisjunctiveProof(
 		OtherBase = Base1
 		OtherResult = Result1
 	} else { // number for option is not correct
-		return &DisjunctiveProof{}, &errorProof{"DisjunctiveProve", "invalid side provided"}
+		return &DisjunctiveProof{}, zkpcp.traceError(&ProofError{Type: DisjunctiveProofType, Op: "NewDisjunctiveProof", Err: ErrInvalidWitness, Msg: "invalid side provided"})
 	}
 
-	if !zkpcp.Mult(ProveBase, x).Equal(ProveResult) {
-		return &DisjunctiveProof{}, &errorProof{"DisjunctiveProve", "Base and Result to be proved not related by x"}
+	if !zkpcp.MultSecret(ProveBase, x).Equal(ProveResult) {
+		return &DisjunctiveProof{}, zkpcp.traceError(&ProofError{Type: DisjunctiveProofType, Op: "NewDisjunctiveProof", Err: ErrInvalidWitness, Msg: "Base and Result to be proved not related by x"})
+	}
+	nonces, err := zkpcp.newNonceSource(DisjunctiveProofType, []*big.Int{x}, Base1, Result1, Base2, Result2)
+	if err != nil {
+		return nil, err
 	}
-	u1, err := rand.Int(rand.Reader, zkpcp.C.Params().N)
+	defer nonces.destroy()
+	u1, err := nonces.scalar()
 	if err != nil {
 		return nil, err
 	}
-	u2, err := rand.Int(rand.Reader, zkpcp.C.Params().N)
+	u2, err := nonces.scalar()
 	if err != nil {
 		return nil, err
 	}
-	u3, err := rand.Int(rand.Reader, zkpcp.C.Params().N)
+	u3, err := nonces.scalar()
 	if err != nil {
 		return nil, err
 	}
 
 	// for (-u3)yH
-	u3Neg := new(big.Int).Neg(u3)
-	u3Neg.Mod(u3Neg, zkpcp.C.Params().N)
+	u3Neg := zkpcp.C.NegScalar(u3)
 
 	// T1 = u1G
-	T1 := zkpcp.Mult(ProveBase, u1)
+	T1 := zkpcp.MultSecret(ProveBase, u1)
 
 	// u2H
-	temp := zkpcp.Mult(OtherBase, u2)
+	temp := zkpcp.MultSecret(OtherBase, u2)
 	// (-u3)yH
-	temp2 := zkpcp.Mult(OtherResult, u3Neg)
+	temp2 := zkpcp.MultSecret(OtherResult, u3Neg)
 	// T2 = u2H + (-u3)yH (yH is OtherResult)
 	T2 := zkpcp.Add(temp, temp2)
 
 	var Challenge *big.Int
 	if option == 0 {
 		// String for proving Base1 and Result1
-		Challenge = GenerateChallenge(zkpcp, Base1.Bytes(), Result1.Bytes(),
-			Base2.Bytes(), Result2.Bytes(),
-			T1.Bytes(), T2.Bytes())
+		Challenge = GenerateChallenge(zkpcp, zkpcp.Bytes(Base1), zkpcp.Bytes(Result1),
+			zkpcp.Bytes(Base2), zkpcp.Bytes(Result2),
+			zkpcp.Bytes(T1), zkpcp.Bytes(T2))
 	} else {
 
 		// If we are proving Base2 and Result2 then we must switch T1 and
 		// T2 in this string, look at mapping in proof for clarification
-		Challenge = GenerateChallenge(zkpcp, Base1.Bytes(), Result1.Bytes(),
-			Base2.Bytes(), Result2.Bytes(),
-			T2.Bytes(), T1.Bytes()) //T2 and T1 SWAPPED!
+		Challenge = GenerateChallenge(zkpcp, zkpcp.Bytes(Base1), zkpcp.Bytes(Result1),
+			zkpcp.Bytes(Base2), zkpcp.Bytes(Result2),
+			zkpcp.Bytes(T2), zkpcp.Bytes(T1)) //T2 and T1 SWAPPED!
 	}
+	// T1 and T2 are not logged, their order here gives away option
+	zkpcp.trace(DisjunctiveProofType, "prover commitment", "challenge", Challenge)
 
-	deltaC := new(big.Int).Sub(Challenge, u3)
-	deltaC.Mod(deltaC, zkpcp.C.Params().N)
+	deltaC := zkpcp.C.SubScalars(Challenge, u3)
 
-	s := new(big.Int).Add(u1, new(big.Int).Mul(deltaC, modValue))
+	s := zkpcp.C.AddScalars(u1, zkpcp.C.MulScalars(deltaC, modValue))
+
+	// u2 and u3 are the simulated response and challenge, which are public,
+	// and must survive nonces.destroy
+	u2 = new(big.Int).Set(u2)
+	u3 = new(big.Int).Set(u3)
 
 	// Look at mapping given in block comment above
 	if option == Left {
@@ -138,7 +159,9 @@ func NewDisjunctiveProof(
 			deltaC,
 			u3,
 			s,
-			u2}, nil
+			u2,
+			zkpcp.C,
+			zkpcp.Hash}, nil
 	}
 
 	return &DisjunctiveProof{
@@ -148,7 +171,9 @@ func NewDisjunctiveProof(
 		u3,
 		deltaC,
 		u2,
-		s}, nil
+		s,
+		zkpcp.C,
+		zkpcp.Hash}, nil
 }
 
 // Verify checks if DisjunctiveProof djProof is valid for the given bases and results
@@ -156,7 +181,24 @@ func (djProof *DisjunctiveProof) Verify(
 	zkpcp ZKPCurveParams, Base1, Result1, Base2, Result2 ECPoint) (bool, error) {
 
 	if djProof == nil {
-		return false, &errorProof{"DisjunctiveProof.Verify", fmt.Sprintf("passed proof is nil")}
+		return false, zkpcp.traceError(&ProofError{Type: DisjunctiveProofType, Op: "DisjunctiveProof.Verify", Err: ErrMalformedProof, Msg: "passed proof is nil"})
+	}
+
+	v := zkpcp.validateInputs(DisjunctiveProofType, "DisjunctiveProof.Verify")
+	v.hash(djProof.hash)
+	v.point("Base1", Base1)
+	v.point("Result1", Result1)
+	v.point("Base2", Base2)
+	v.point("Result2", Result2)
+	v.point("T1", djProof.T1)
+	v.point("T2", djProof.T2)
+	v.scalar("C", djProof.C)
+	v.scalar("C1", djProof.C1)
+	v.scalar("C2", djProof.C2)
+	v.scalar("S1", djProof.S1)
+	v.scalar("S2", djProof.S2)
+	if err := v.done(); err != nil {
+		return false, err
 	}
 
 	T1 := djProof.T1
@@ -167,20 +209,20 @@ func (djProof *DisjunctiveProof) Verify(
 	S1 := djProof.S1
 	S2 := djProof.S2
 
-	checkC := GenerateChallenge(zkpcp, Base1.Bytes(), Result1.Bytes(),
-		Base2.Bytes(), Result2.Bytes(),
-		T1.Bytes(), T2.Bytes())
+	checkC := GenerateChallenge(zkpcp, zkpcp.Bytes(Base1), zkpcp.Bytes(Result1),
+		zkpcp.Bytes(Base2), zkpcp.Bytes(Result2),
+		zkpcp.Bytes(T1), zkpcp.Bytes(T2))
 
 	if checkC.Cmp(C) != 0 {
-		return false, &errorProof{"DisjunctiveVerify", "checkC does not agree with proofC"}
+		return false, zkpcp.traceError(&ProofError{Type: DisjunctiveProofType, Op: "DisjunctiveProof.Verify", Err: ErrChallengeMismatch, Msg: "checkC does not agree with proofC"})
 	}
 
 	// C1 + C2
-	totalC := new(big.Int).Add(C1, C2)
-	totalC.Mod(totalC, zkpcp.C.Params().N)
+	totalC := zkpcp.C.AddScalars(C1, C2)
 	if totalC.Cmp(C) != 0 {
-		return false, &errorProof{"DisjunctiveVerify", "totalC does not agree with proofC"}
+		return false, zkpcp.traceError(&ProofError{Type: DisjunctiveProofType, Op: "DisjunctiveProof.Verify", Err: ErrChallengeMismatch, Msg: "totalC does not agree with proofC"})
 	}
+	zkpcp.trace(DisjunctiveProofType, "verifier challenge", "challenge", checkC)
 
 	// T1 + c1A
 	c1A := zkpcp.Mult(Result1, C1)
@@ -188,7 +230,7 @@ func (djProof *DisjunctiveProof) Verify(
 	s1G := zkpcp.Mult(Base1, S1)
 
 	if !checks1G.Equal(s1G) {
-		return false, &errorProof{"DisjunctiveVerify", "s1G not equal to T1 + c1A"}
+		return false, zkpcp.traceError(&ProofError{Type: DisjunctiveProofType, Op: "DisjunctiveProof.Verify", Err: ErrEquationFailed, Equation: 1, Msg: "s1G not equal to T1 + c1A"})
 	}
 
 	// T2 + c2B
@@ -197,38 +239,165 @@ func (djProof *DisjunctiveProof) Verify(
 	s2G := zkpcp.Mult(Base2, S2)
 
 	if !checks2G.Equal(s2G) {
-		return false, &errorProof{"DisjunctiveVerify", "s2G not equal to T2 + c2B"}
+		return false, zkpcp.traceError(&ProofError{Type: DisjunctiveProofType, Op: "DisjunctiveProof.Verify", Err: ErrEquationFailed, Equation: 2, Msg: "s2G not equal to T2 + c2B"})
 	}
 
+	zkpcp.trace(DisjunctiveProofType, "verified")
 	return true, nil
 }
 
-// Bytes returns a byte slice with a serialized representation of DisjunctiveProof proof
-func (djProof *DisjunctiveProof) Bytes() []byte {
-	var buf bytes.Buffer
+// ChallengeHash returns the hash the challenges of djProof were computed with
+func (djProof *DisjunctiveProof) ChallengeHash() ChallengeHash {
+	return djProof.hash
+}
+
+// Type returns DisjunctiveProofType
+func (djProof *DisjunctiveProof) Type() ProofType {
+	return DisjunctiveProofType
+}
+
+// VerifyStatement checks djProof against a DisjunctiveStatement, see Verify
+func (djProof *DisjunctiveProof) VerifyStatement(s Statement) (bool, error) {
+	st, ok := s.(DisjunctiveStatement)
+	if !ok {
+		return false, wrongStatement(DisjunctiveProofType, s)
+	}
+	return djProof.Verify(st.Params, st.Base1, st.Result1, st.Base2, st.Result2)
+}
+
+// Diagnose runs every check of Verify and reports the outcome of each, see
+// VerificationReport
+func (djProof *DisjunctiveProof) Diagnose(
+	zkpcp ZKPCurveParams, Base1, Result1, Base2, Result2 ECPoint) *VerificationReport {
+
+	r := newReport(DisjunctiveProofType, zkpcp)
+	if djProof == nil {
+		return r.malformed()
+	}
+	if !r.wellFormed([]ECPoint{Base1, Result1, Base2, Result2, djProof.T1, djProof.T2},
+		[]*big.Int{djProof.C, djProof.C1, djProof.C2, djProof.S1, djProof.S2}) {
+		return r
+	}
+	r.hash(djProof.hash)
+
+	checkC := GenerateChallenge(zkpcp, zkpcp.Bytes(Base1), zkpcp.Bytes(Result1),
+		zkpcp.Bytes(Base2), zkpcp.Bytes(Result2),
+		zkpcp.Bytes(djProof.T1), zkpcp.Bytes(djProof.T2))
+	r.scalar("HASH(Base1, Result1, Base2, Result2, T1, T2) == C", checkC, djProof.C)
+	r.scalar("C1 + C2 == C", zkpcp.C.AddScalars(djProof.C1, djProof.C2), djProof.C)
 
-	WriteECPoint(&buf, djProof.T1)
-	WriteECPoint(&buf, djProof.T2)
-	WriteBigInt(&buf, djProof.C)
-	WriteBigInt(&buf, djProof.C1)
-	WriteBigInt(&buf, djProof.C2)
-	WriteBigInt(&buf, djProof.S1)
-	WriteBigInt(&buf, djProof.S2)
+	r.point("S1*Base1 == T1 + C1*Result1", 1,
+		zkpcp.Mult(Base1, djProof.S1), zkpcp.Add(djProof.T1, zkpcp.Mult(Result1, djProof.C1)))
+	r.point("S2*Base2 == T2 + C2*Result2", 2,
+		zkpcp.Mult(Base2, djProof.S2), zkpcp.Add(djProof.T2, zkpcp.Mult(Result2, djProof.C2)))
+	return r
+}
+
+// DiagnoseStatement diagnoses djProof against a DisjunctiveStatement, see
+// Diagnose
+func (djProof *DisjunctiveProof) DiagnoseStatement(s Statement) (*VerificationReport, error) {
+	st, ok := s.(DisjunctiveStatement)
+	if !ok {
+		return nil, wrongStatement(DisjunctiveProofType, s)
+	}
+	return djProof.Diagnose(st.Params, st.Base1, st.Result1, st.Base2, st.Result2), nil
+}
+
+// WriteTo writes the serialized representation of DisjunctiveProof djProof to w
+func (djProof *DisjunctiveProof) WriteTo(w io.Writer) (int64, error) {
+	pw := newProofWriter(w, proofCurve(djProof.curve), djProof.hash)
+	pw.point(djProof.T1)
+	pw.point(djProof.T2)
+	pw.scalar(djProof.C)
+	pw.scalar(djProof.C1)
+	pw.scalar(djProof.C2)
+	pw.scalar(djProof.S1)
+	pw.scalar(djProof.S2)
+	return pw.done()
+}
+
+// ReadFrom reads one DisjunctiveProof written by WriteTo from r into djProof.  It reads no
+// further than the end of the proof, so proofs can be read back to back from
+// a stream; io.EOF is returned if r ends before the next proof starts.
+func (djProof *DisjunctiveProof) ReadFrom(r io.Reader) (int64, error) {
+	var p DisjunctiveProof
+	pr := newProofReader("DisjunctiveProof.ReadFrom", r)
+	p.curve, p.hash = pr.curve, pr.hash
+	p.T1 = pr.point("T1")
+	p.T2 = pr.point("T2")
+	p.C = pr.scalar("C")
+	p.C1 = pr.scalar("C1")
+	p.C2 = pr.scalar("C2")
+	p.S1 = pr.scalar("S1")
+	p.S2 = pr.scalar("S2")
+	n, err := pr.done()
+	if err != nil {
+		return n, err
+	}
+	*djProof = p
+	return n, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (djProof *DisjunctiveProof) MarshalBinary() ([]byte, error) {
+	return marshalProof(djProof)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.  b must hold exactly
+// one proof.
+func (djProof *DisjunctiveProof) UnmarshalBinary(b []byte) error {
+	return unmarshalProof("DisjunctiveProof.UnmarshalBinary", b, djProof)
+}
 
-	return buf.Bytes()
+// Bytes returns a byte slice with a serialized representation of DisjunctiveProof djProof, or
+// nil if djProof can not be serialized
+func (djProof *DisjunctiveProof) Bytes() []byte {
+	b, _ := djProof.MarshalBinary()
+	return b
 }
 
 // NewDisjunctiveProofFromBytes returns a DisjunctiveProof generated from the
 // deserialization of byte slice b
 func NewDisjunctiveProofFromBytes(b []byte) (*DisjunctiveProof, error) {
 	proof := new(DisjunctiveProof)
-	buf := bytes.NewBuffer(b)
-	proof.T1, _ = ReadECPoint(buf)
-	proof.T2, _ = ReadECPoint(buf)
-	proof.C, _ = ReadBigInt(buf)
-	proof.C1, _ = ReadBigInt(buf)
-	proof.C2, _ = ReadBigInt(buf)
-	proof.S1, _ = ReadBigInt(buf)
-	proof.S2, _ = ReadBigInt(buf)
+	if err := proof.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
 	return proof, nil
 }
+
+// MarshalJSON encodes djProof as a JSON object in its group, see Group.Encode
+func (djProof *DisjunctiveProof) MarshalJSON() ([]byte, error) {
+	w := newJSONWriter(proofCurve(djProof.curve))
+	w.group()
+	w.hash(djProof.hash)
+	w.point("T1", djProof.T1)
+	w.point("T2", djProof.T2)
+	w.scalar("C", djProof.C)
+	w.scalar("C1", djProof.C1)
+	w.scalar("C2", djProof.C2)
+	w.scalar("S1", djProof.S1)
+	w.scalar("S2", djProof.S2)
+	return w.marshal()
+}
+
+// UnmarshalJSON decodes a proof written by MarshalJSON
+func (djProof *DisjunctiveProof) UnmarshalJSON(b []byte) error {
+	var p DisjunctiveProof
+	r := newJSONReader("DisjunctiveProof.UnmarshalJSON", b)
+	r.group()
+	p.hash = r.hash()
+	p.T1 = r.point("T1")
+	p.T2 = r.point("T2")
+	p.C = r.scalar("C")
+	p.C1 = r.scalar("C1")
+	p.C2 = r.scalar("C2")
+	p.S1 = r.scalar("S1")
+	p.S2 = r.scalar("S2")
+	if err := r.done(); err != nil {
+		return err
+	}
+	p.curve = r.curve
+	*djProof = p
+	return nil
+}
@@ -7,44 +7,48 @@ import (
 )
 
 func TestDisjunctive(t *testing.T) {
+	forEachCurve(t, testDisjunctive)
+}
+
+func testDisjunctive(t *testing.T, zkpcp ZKPCurveParams) {
 
 	x := big.NewInt(100)
 	y := big.NewInt(101)
 
-	Base1 := TestCurve.G
-	Result1 := TestCurve.Mult(TestCurve.G, x)
-	Base2 := TestCurve.H
-	Result2 := TestCurve.Mult(TestCurve.H, y)
+	Base1 := zkpcp.G
+	Result1 := zkpcp.Mult(zkpcp.G, x)
+	Base2 := zkpcp.H
+	Result2 := zkpcp.Mult(zkpcp.H, y)
 
-	djProofLEFT, status1 := NewDisjunctiveProof(TestCurve, Base1, Result1, Base2, Result2, x, Left)
+	djProofLEFT, status1 := NewDisjunctiveProof(zkpcp, Base1, Result1, Base2, Result2, x, Left)
 
 	if status1 != nil {
-		proofStatus(status1.(*errorProof))
+		proofStatus(status1.(*ProofError))
 		t.Fatalf("TestDisjunctive - incorrect error message for correct proof, case 1\n")
 	}
 
-	djProofRIGHT, status2 := NewDisjunctiveProof(TestCurve, Base1, Result1, Base2, Result2, y, Right)
+	djProofRIGHT, status2 := NewDisjunctiveProof(zkpcp, Base1, Result1, Base2, Result2, y, Right)
 
 	if status2 != nil {
-		proofStatus(status2.(*errorProof))
+		proofStatus(status2.(*ProofError))
 		t.Fatalf("TestDisjunctive - incorrect error message for correct proof, case 2\n")
 	}
 
 	t.Logf("Testing DisjunctiveProof:\n")
 	t.Logf("First djProof : ")
-	check, err := djProofLEFT.Verify(TestCurve, Base1, Result1, Base2, Result2)
+	check, err := djProofLEFT.Verify(zkpcp, Base1, Result1, Base2, Result2)
 	if !check || err != nil {
 		t.Fatalf("djProof failed to generate properly for left side\n")
 	}
 
 	t.Logf("Passed \n [testing] Second djProof : ")
-	check, err = djProofRIGHT.Verify(TestCurve, Base1, Result1, Base2, Result2)
+	check, err = djProofRIGHT.Verify(zkpcp, Base1, Result1, Base2, Result2)
 	if !check || err != nil {
 		t.Fatalf("djProof failed to generate properly for right side\n")
 	}
 
 	t.Logf("Passed \n [testing] Next djProof attempt should result in an error message\n")
-	_, status3 := NewDisjunctiveProof(TestCurve, Base1, Result1, Base2, Result2, y, Left) // This should fail
+	_, status3 := NewDisjunctiveProof(zkpcp, Base1, Result1, Base2, Result2, y, Left) // This should fail
 
 	if status3 == nil {
 		t.Fatalf("TestDisjunctive - incorrect error message for incorrect proof, case 3\n")
@@ -53,18 +57,22 @@ func TestDisjunctive(t *testing.T) {
 }
 
 func TestDisjuncSerialization(t *testing.T) {
-	value, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
-	randVal, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
-	Base1 := TestCurve.G
-	Result1 := TestCurve.Mult(Base1, value)
-	Base2 := TestCurve.H
-	Result2 := TestCurve.Mult(Base2, randVal)
-	proof, _ := NewDisjunctiveProof(TestCurve, Base1, Result1, Base2, Result2, value, Left)
+	forEachCurve(t, testDisjuncSerialization)
+}
+
+func testDisjuncSerialization(t *testing.T, zkpcp ZKPCurveParams) {
+	value, _ := rand.Int(rand.Reader, zkpcp.C.Order())
+	randVal, _ := rand.Int(rand.Reader, zkpcp.C.Order())
+	Base1 := zkpcp.G
+	Result1 := zkpcp.Mult(Base1, value)
+	Base2 := zkpcp.H
+	Result2 := zkpcp.Mult(Base2, randVal)
+	proof, _ := NewDisjunctiveProof(zkpcp, Base1, Result1, Base2, Result2, value, Left)
 	proof, err := NewDisjunctiveProofFromBytes(proof.Bytes())
 	if err != nil {
 		t.Fatalf("TestDisjuncSerialization failed to deserialize\n")
 	}
-	ok, err := proof.Verify(TestCurve, Base1, Result1, Base2, Result2)
+	ok, err := proof.Verify(zkpcp, Base1, Result1, Base2, Result2)
 	if !ok || err != nil {
 		t.Fatalf("TestDisjuncSerialization failed to verify\n")
 	}
@@ -72,8 +80,8 @@ func TestDisjuncSerialization(t *testing.T) {
 }
 
 func BenchmarkDisjuncProve_LEFT(b *testing.B) {
-	value, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
-	randVal, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	value, _ := rand.Int(rand.Reader, TestCurve.C.Order())
+	randVal, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 	Base1 := TestCurve.G
 	Result1 := TestCurve.Mult(Base1, value)
 	Base2 := TestCurve.H
@@ -85,8 +93,8 @@ func BenchmarkDisjuncProve_LEFT(b *testing.B) {
 }
 
 func BenchmarkDisjuncProve_RIGHT(b *testing.B) {
-	value, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
-	randVal, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	value, _ := rand.Int(rand.Reader, TestCurve.C.Order())
+	randVal, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 	Base1 := TestCurve.G
 	Result1 := TestCurve.Mult(Base1, value)
 	Base2 := TestCurve.H
@@ -98,8 +106,8 @@ func BenchmarkDisjuncProve_RIGHT(b *testing.B) {
 }
 
 func BenchmarkDisjuncVerify_LEFT(b *testing.B) {
-	value, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
-	randVal, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	value, _ := rand.Int(rand.Reader, TestCurve.C.Order())
+	randVal, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 	Base1 := TestCurve.G
 	Result1 := TestCurve.Mult(Base1, value)
 	Base2 := TestCurve.H
@@ -112,8 +120,8 @@ func BenchmarkDisjuncVerify_LEFT(b *testing.B) {
 }
 
 func BenchmarkDisjuncVerify_RIGHT(b *testing.B) {
-	value, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
-	randVal, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	value, _ := rand.Int(rand.Reader, TestCurve.C.Order())
+	randVal, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 	Base1 := TestCurve.G
 	Result1 := TestCurve.Mult(Base1, value)
 	Base2 := TestCurve.H
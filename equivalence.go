@@ -1,9 +1,8 @@
 package zksigma
 
 import (
-	"bytes"
-	"crypto/rand"
 	"fmt"
+	"io"
 	"math/big"
 )
 
@@ -27,10 +26,12 @@ import (
 //                                      sG ?= T1 + cA
 //                                      sH ?= T2 + cB
 type EquivalenceProof struct {
-	UG          ECPoint  // uG is the scalar mult of u (random num) with base G
-	UH          ECPoint  // uH is the scalar mult of u (random num) with base H
-	Challenge   *big.Int // Challenge is hash sum of challenge commitment
-	HiddenValue *big.Int // Hidden Value hides the discrete log x that we want to prove equivalence for
+	UG          ECPoint       // uG is the scalar mult of u (random num) with base G
+	UH          ECPoint       // uH is the scalar mult of u (random num) with base H
+	Challenge   *big.Int      // Challenge is hash sum of challenge commitment
+	HiddenValue *big.Int      // Hidden Value hides the discrete log x that we want to prove equivalence for
+	curve       Group         // group the proof was computed in
+	hash        ChallengeHash // hash the challenges were computed with
 }
 
 // NewEquivalenceProof generates an equivalence proof that Result1 is the scalar multiple of base Base1,
@@ -38,43 +39,61 @@ type EquivalenceProof struct {
 func NewEquivalenceProof(
 	zkpcp ZKPCurveParams, Base1, Result1, Base2, Result2 ECPoint, x *big.Int) (*EquivalenceProof, error) {
 
-	modValue := new(big.Int).Mod(x, zkpcp.C.Params().N)
-	check1 := zkpcp.Mult(Base1, modValue)
+	in := zkpcp.validateInputs(EquivalenceProofType, "NewEquivalenceProof")
+	in.point("Base1", Base1)
+	in.point("Result1", Result1)
+	in.point("Base2", Base2)
+	in.point("Result2", Result2)
+	if err := in.done(); err != nil {
+		return nil, err
+	}
+
+	modValue := zkpcp.C.ReduceScalar(x)
+	defer WipeScalars(modValue)
+	check1 := zkpcp.MultSecret(Base1, modValue)
 
 	if !check1.Equal(Result1) {
-		return nil, &errorProof{"EquivalenceProve", "Base1 and Result1 are not related by x"}
+		return nil, zkpcp.traceError(&ProofError{Type: EquivalenceProofType, Op: "NewEquivalenceProof", Err: ErrInvalidWitness, Msg: "Base1 and Result1 are not related by x"})
 	}
 
-	check2 := zkpcp.Mult(Base2, modValue)
+	check2 := zkpcp.MultSecret(Base2, modValue)
 	if !check2.Equal(Result2) {
-		return nil, &errorProof{"EquivalenceProve", "Base2 and Result2 are not related by x"}
+		return nil, zkpcp.traceError(&ProofError{Type: EquivalenceProofType, Op: "NewEquivalenceProof", Err: ErrInvalidWitness, Msg: "Base2 and Result2 are not related by x"})
 	}
 
 	// random number
-	u, err := rand.Int(rand.Reader, zkpcp.C.Params().N) // random number to hide x later
+	nonces, err := zkpcp.newNonceSource(EquivalenceProofType, []*big.Int{x}, Base1, Result1, Base2, Result2)
+	if err != nil {
+		return nil, err
+	}
+	defer nonces.destroy()
+	u, err := nonces.scalar() // random number to hide x later
 	if err != nil {
 		return nil, err
 	}
 
 	// uG
-	uBase1 := zkpcp.Mult(Base1, u)
+	uBase1 := zkpcp.MultSecret(Base1, u)
 	// uH
-	uBase2 := zkpcp.Mult(Base2, u)
+	uBase2 := zkpcp.MultSecret(Base2, u)
 
 	// HASH(G, H, xG, xH, uG, uH)
-	Challenge := GenerateChallenge(zkpcp, Base1.Bytes(), Result1.Bytes(),
-		Base2.Bytes(), Result2.Bytes(),
-		uBase1.Bytes(), uBase2.Bytes())
+	Challenge := GenerateChallenge(zkpcp, zkpcp.Bytes(Base1), zkpcp.Bytes(Result1),
+		zkpcp.Bytes(Base2), zkpcp.Bytes(Result2),
+		zkpcp.Bytes(uBase1), zkpcp.Bytes(uBase2))
+	zkpcp.trace(EquivalenceProofType, "prover commitment",
+		"UG", zkpcp.logPoint(uBase1), "UH", zkpcp.logPoint(uBase2), "challenge", Challenge)
 
 	// s = u + c * x
-	HiddenValue := new(big.Int).Add(u, new(big.Int).Mul(Challenge, modValue))
-	HiddenValue = HiddenValue.Mod(HiddenValue, zkpcp.C.Params().N)
+	HiddenValue := zkpcp.C.AddScalars(u, zkpcp.C.MulScalars(Challenge, modValue))
 
 	return &EquivalenceProof{
 		uBase1, // uG
 		uBase2, // uH
 		Challenge,
-		HiddenValue}, nil
+		HiddenValue,
+		zkpcp.C,
+		zkpcp.Hash}, nil
 
 }
 
@@ -85,18 +104,33 @@ func (eqProof *EquivalenceProof) Verify(
 	zkpcp ZKPCurveParams, Base1, Result1, Base2, Result2 ECPoint) (bool, error) {
 
 	if eqProof == nil {
-		return false, &errorProof{"EquivalenceVerify", fmt.Sprintf("passed proof is nil")}
+		return false, zkpcp.traceError(&ProofError{Type: EquivalenceProofType, Op: "EquivalenceProof.Verify", Err: ErrMalformedProof, Msg: "passed proof is nil"})
+	}
+
+	v := zkpcp.validateInputs(EquivalenceProofType, "EquivalenceProof.Verify")
+	v.hash(eqProof.hash)
+	v.point("Base1", Base1)
+	v.point("Result1", Result1)
+	v.point("Base2", Base2)
+	v.point("Result2", Result2)
+	v.point("UG", eqProof.UG)
+	v.point("UH", eqProof.UH)
+	v.scalar("Challenge", eqProof.Challenge)
+	v.scalar("HiddenValue", eqProof.HiddenValue)
+	if err := v.done(); err != nil {
+		return false, err
 	}
 
 	// Regenerate challenge string
-	c := GenerateChallenge(zkpcp, Base1.Bytes(), Result1.Bytes(),
-		Base2.Bytes(), Result2.Bytes(),
-		eqProof.UG.Bytes(), eqProof.UH.Bytes())
+	c := GenerateChallenge(zkpcp, zkpcp.Bytes(Base1), zkpcp.Bytes(Result1),
+		zkpcp.Bytes(Base2), zkpcp.Bytes(Result2),
+		zkpcp.Bytes(eqProof.UG), zkpcp.Bytes(eqProof.UH))
 
 	if c.Cmp(eqProof.Challenge) != 0 {
-		return false, &errorProof{"EquivalenceVerify", fmt.Sprintf("challenge comparison failed. proof: %v calculated: %v",
-			eqProof.Challenge, c)}
+		return false, zkpcp.traceError(&ProofError{Type: EquivalenceProofType, Op: "EquivalenceProof.Verify", Err: ErrChallengeMismatch, Msg: fmt.Sprintf("challenge comparison failed. proof: %v calculated: %v",
+			eqProof.Challenge, c)})
 	}
+	zkpcp.trace(EquivalenceProofType, "verifier challenge", "challenge", c)
 
 	// sG ?= uG + cA
 	sG := zkpcp.Mult(Base1, eqProof.HiddenValue)
@@ -104,7 +138,7 @@ func (eqProof *EquivalenceProof) Verify(
 	test := zkpcp.Add(eqProof.UG, cG)
 
 	if !sG.Equal(test) {
-		return false, &errorProof{"EquivalenceVerify", "sG comparison did not pass"}
+		return false, zkpcp.traceError(&ProofError{Type: EquivalenceProofType, Op: "EquivalenceProof.Verify", Err: ErrEquationFailed, Equation: 1, Msg: "sG comparison did not pass"})
 	}
 
 	// sH ?= uH + cB
@@ -113,34 +147,156 @@ func (eqProof *EquivalenceProof) Verify(
 	test = zkpcp.Add(eqProof.UH, cH)
 
 	if !sH.Equal(test) {
-		return false, &errorProof{"EquivalenceVerify", "sH comparison did not pass"}
+		return false, zkpcp.traceError(&ProofError{Type: EquivalenceProofType, Op: "EquivalenceProof.Verify", Err: ErrEquationFailed, Equation: 2, Msg: "sH comparison did not pass"})
 	}
 
 	// All three checks passed, proof must be correct
+	zkpcp.trace(EquivalenceProofType, "verified")
 	return true, nil
 
 }
 
-// Bytes returns a byte slice with a serialized representation of EquivalenceProof proof
-func (proof *EquivalenceProof) Bytes() []byte {
-	var buf bytes.Buffer
+// ChallengeHash returns the hash the challenges of eqProof were computed with
+func (eqProof *EquivalenceProof) ChallengeHash() ChallengeHash {
+	return eqProof.hash
+}
 
-	WriteECPoint(&buf, proof.UG)
-	WriteECPoint(&buf, proof.UH)
-	WriteBigInt(&buf, proof.Challenge)
-	WriteBigInt(&buf, proof.HiddenValue)
+// Type returns EquivalenceProofType
+func (eqProof *EquivalenceProof) Type() ProofType {
+	return EquivalenceProofType
+}
 
-	return buf.Bytes()
+// VerifyStatement checks eqProof against a EquivalenceStatement, see Verify
+func (eqProof *EquivalenceProof) VerifyStatement(s Statement) (bool, error) {
+	st, ok := s.(EquivalenceStatement)
+	if !ok {
+		return false, wrongStatement(EquivalenceProofType, s)
+	}
+	return eqProof.Verify(st.Params, st.Base1, st.Result1, st.Base2, st.Result2)
+}
+
+// Diagnose runs every check of Verify and reports the outcome of each, see
+// VerificationReport
+func (eqProof *EquivalenceProof) Diagnose(
+	zkpcp ZKPCurveParams, Base1, Result1, Base2, Result2 ECPoint) *VerificationReport {
+
+	r := newReport(EquivalenceProofType, zkpcp)
+	if eqProof == nil {
+		return r.malformed()
+	}
+	if !r.wellFormed([]ECPoint{Base1, Result1, Base2, Result2, eqProof.UG, eqProof.UH},
+		[]*big.Int{eqProof.Challenge, eqProof.HiddenValue}) {
+		return r
+	}
+	r.hash(eqProof.hash)
+
+	c := GenerateChallenge(zkpcp, zkpcp.Bytes(Base1), zkpcp.Bytes(Result1),
+		zkpcp.Bytes(Base2), zkpcp.Bytes(Result2),
+		zkpcp.Bytes(eqProof.UG), zkpcp.Bytes(eqProof.UH))
+	r.scalar("HASH(Base1, Result1, Base2, Result2, UG, UH) == Challenge", c, eqProof.Challenge)
+
+	r.point("HiddenValue*Base1 == UG + Challenge*Result1", 1,
+		zkpcp.Mult(Base1, eqProof.HiddenValue),
+		zkpcp.Add(eqProof.UG, zkpcp.Mult(Result1, eqProof.Challenge)))
+	r.point("HiddenValue*Base2 == UH + Challenge*Result2", 2,
+		zkpcp.Mult(Base2, eqProof.HiddenValue),
+		zkpcp.Add(eqProof.UH, zkpcp.Mult(Result2, eqProof.Challenge)))
+	return r
+}
+
+// DiagnoseStatement diagnoses eqProof against an EquivalenceStatement, see
+// Diagnose
+func (eqProof *EquivalenceProof) DiagnoseStatement(s Statement) (*VerificationReport, error) {
+	st, ok := s.(EquivalenceStatement)
+	if !ok {
+		return nil, wrongStatement(EquivalenceProofType, s)
+	}
+	return eqProof.Diagnose(st.Params, st.Base1, st.Result1, st.Base2, st.Result2), nil
+}
+
+// WriteTo writes the serialized representation of EquivalenceProof eqProof to w
+func (eqProof *EquivalenceProof) WriteTo(w io.Writer) (int64, error) {
+	pw := newProofWriter(w, proofCurve(eqProof.curve), eqProof.hash)
+	pw.point(eqProof.UG)
+	pw.point(eqProof.UH)
+	pw.scalar(eqProof.Challenge)
+	pw.scalar(eqProof.HiddenValue)
+	return pw.done()
+}
+
+// ReadFrom reads one EquivalenceProof written by WriteTo from r into eqProof.  It reads no
+// further than the end of the proof, so proofs can be read back to back from
+// a stream; io.EOF is returned if r ends before the next proof starts.
+func (eqProof *EquivalenceProof) ReadFrom(r io.Reader) (int64, error) {
+	var p EquivalenceProof
+	pr := newProofReader("EquivalenceProof.ReadFrom", r)
+	p.curve, p.hash = pr.curve, pr.hash
+	p.UG = pr.point("UG")
+	p.UH = pr.point("UH")
+	p.Challenge = pr.scalar("Challenge")
+	p.HiddenValue = pr.scalar("HiddenValue")
+	n, err := pr.done()
+	if err != nil {
+		return n, err
+	}
+	*eqProof = p
+	return n, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (eqProof *EquivalenceProof) MarshalBinary() ([]byte, error) {
+	return marshalProof(eqProof)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.  b must hold exactly
+// one proof.
+func (eqProof *EquivalenceProof) UnmarshalBinary(b []byte) error {
+	return unmarshalProof("EquivalenceProof.UnmarshalBinary", b, eqProof)
+}
+
+// Bytes returns a byte slice with a serialized representation of EquivalenceProof eqProof, or
+// nil if eqProof can not be serialized
+func (eqProof *EquivalenceProof) Bytes() []byte {
+	b, _ := eqProof.MarshalBinary()
+	return b
 }
 
 // NewEquivalenceProofFromBytes returns a EquivalenceProof generated from the
 // deserialization of byte slice b
 func NewEquivalenceProofFromBytes(b []byte) (*EquivalenceProof, error) {
 	proof := new(EquivalenceProof)
-	buf := bytes.NewBuffer(b)
-	proof.UG, _ = ReadECPoint(buf)
-	proof.UH, _ = ReadECPoint(buf)
-	proof.Challenge, _ = ReadBigInt(buf)
-	proof.HiddenValue, _ = ReadBigInt(buf)
+	if err := proof.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
 	return proof, nil
 }
+
+// MarshalJSON encodes eqProof as a JSON object in its group, see Group.Encode
+func (eqProof *EquivalenceProof) MarshalJSON() ([]byte, error) {
+	w := newJSONWriter(proofCurve(eqProof.curve))
+	w.group()
+	w.hash(eqProof.hash)
+	w.point("UG", eqProof.UG)
+	w.point("UH", eqProof.UH)
+	w.scalar("Challenge", eqProof.Challenge)
+	w.scalar("HiddenValue", eqProof.HiddenValue)
+	return w.marshal()
+}
+
+// UnmarshalJSON decodes a proof written by MarshalJSON
+func (eqProof *EquivalenceProof) UnmarshalJSON(b []byte) error {
+	var p EquivalenceProof
+	r := newJSONReader("EquivalenceProof.UnmarshalJSON", b)
+	r.group()
+	p.hash = r.hash()
+	p.UG = r.point("UG")
+	p.UH = r.point("UH")
+	p.Challenge = r.scalar("Challenge")
+	p.HiddenValue = r.scalar("HiddenValue")
+	if err := r.done(); err != nil {
+		return err
+	}
+	p.curve = r.curve
+	*eqProof = p
+	return nil
+}
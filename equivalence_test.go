@@ -7,22 +7,26 @@ import (
 )
 
 func TestEquivalence(t *testing.T) {
+	forEachCurve(t, testEquivalence)
+}
 
-	x, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
-	Base1 := TestCurve.G
-	Result1 := TestCurve.Mult(Base1, x)
+func testEquivalence(t *testing.T, zkpcp ZKPCurveParams) {
 
-	Base2 := TestCurve.H
-	Result2 := TestCurve.Mult(Base2, x)
+	x, _ := rand.Int(rand.Reader, zkpcp.C.Order())
+	Base1 := zkpcp.G
+	Result1 := zkpcp.Mult(Base1, x)
 
-	eqProof, status1 := NewEquivalenceProof(TestCurve, Base1, Result1, Base2, Result2, x)
+	Base2 := zkpcp.H
+	Result2 := zkpcp.Mult(Base2, x)
+
+	eqProof, status1 := NewEquivalenceProof(zkpcp, Base1, Result1, Base2, Result2, x)
 
 	if status1 != nil {
-		proofStatus(status1.(*errorProof))
+		proofStatus(status1.(*ProofError))
 		t.Fatalf("error code should have indicated successful proof")
 	}
 
-	check, err := eqProof.Verify(TestCurve, Base1, Result1, Base2, Result2)
+	check, err := eqProof.Verify(zkpcp, Base1, Result1, Base2, Result2)
 	if !check || err != nil {
 		t.Logf("Base1 : %v\n", Base1)
 		t.Logf("Result1 : %v\n", Result1)
@@ -35,7 +39,7 @@ func TestEquivalence(t *testing.T) {
 	t.Logf("Next comparison should fail\n")
 
 	// Bases swapped shouldn't work
-	check, err = eqProof.Verify(TestCurve, Base2, Result1, Base1, Result2)
+	check, err = eqProof.Verify(zkpcp, Base2, Result1, Base1, Result2)
 
 	if check || err == nil {
 		t.Logf("Base1 : %v\n", Base1)
@@ -49,7 +53,7 @@ func TestEquivalence(t *testing.T) {
 	t.Logf("Next comparison should fail\n")
 	// Bad proof
 	eqProof.HiddenValue = big.NewInt(-1)
-	check, err = eqProof.Verify(TestCurve, Base2, Result1, Base1, Result2)
+	check, err = eqProof.Verify(zkpcp, Base2, Result1, Base1, Result2)
 	if check || err == nil {
 		t.Logf("Base1 : %v\n", Base1)
 		t.Logf("Result1 : %v\n", Result1)
@@ -59,33 +63,37 @@ func TestEquivalence(t *testing.T) {
 		t.Fatalf("Equivalence Proof verification doesn't work")
 	}
 
-	x, _ = rand.Int(rand.Reader, TestCurve.C.Params().N)
-	_, status2 := NewEquivalenceProof(TestCurve, Base1, Result1, Base2, Result2, x)
+	x, _ = rand.Int(rand.Reader, zkpcp.C.Order())
+	_, status2 := NewEquivalenceProof(zkpcp, Base1, Result1, Base2, Result2, x)
 
 	// here I check proofStatus in the else statement because I want to make sure
 	// the failed case will raise an error
 	if status2 == nil {
 		t.Fatalf("error code should have indicated failed proof")
 	} else {
-		proofStatus(status2.(*errorProof))
+		proofStatus(status2.(*ProofError))
 	}
 
 }
 
 func TestEquivSerialization(t *testing.T) {
-	value, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
-	Base1 := TestCurve.G
-	Result1 := TestCurve.Mult(Base1, value)
+	forEachCurve(t, testEquivSerialization)
+}
 
-	Base2 := TestCurve.H
-	Result2 := TestCurve.Mult(Base2, value)
+func testEquivSerialization(t *testing.T, zkpcp ZKPCurveParams) {
+	value, _ := rand.Int(rand.Reader, zkpcp.C.Order())
+	Base1 := zkpcp.G
+	Result1 := zkpcp.Mult(Base1, value)
 
-	proof, _ := NewEquivalenceProof(TestCurve, Base1, Result1, Base2, Result2, value)
+	Base2 := zkpcp.H
+	Result2 := zkpcp.Mult(Base2, value)
+
+	proof, _ := NewEquivalenceProof(zkpcp, Base1, Result1, Base2, Result2, value)
 	proof, err := NewEquivalenceProofFromBytes(proof.Bytes())
 	if err != nil {
 		t.Fatalf("TestEquivSerialization failed to deserialize\n")
 	}
-	ok, err := proof.Verify(TestCurve, Base1, Result1, Base2, Result2)
+	ok, err := proof.Verify(zkpcp, Base1, Result1, Base2, Result2)
 	if !ok || err != nil {
 		t.Fatalf("TestEquivSerialization failed to verify\n")
 	}
@@ -93,7 +101,7 @@ func TestEquivSerialization(t *testing.T) {
 }
 
 func BenchmarkEquivProve(b *testing.B) {
-	value, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	value, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 	Base1 := TestCurve.G
 	Result1 := TestCurve.Mult(Base1, value)
 
@@ -106,7 +114,7 @@ func BenchmarkEquivProve(b *testing.B) {
 }
 
 func BenchmarkEquivVerify(b *testing.B) {
-	value, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	value, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 	Base1 := TestCurve.G
 	Result1 := TestCurve.Mult(Base1, value)
 
@@ -0,0 +1,68 @@
+package zksigma
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The errors wrapped by every ProofError, to be tested for with errors.Is.
+var (
+	// ErrChallengeMismatch means the challenge in a proof is not the hash of
+	// its statement and commitments.
+	ErrChallengeMismatch = errors.New("challenge mismatch")
+	// ErrEquationFailed means one of the verification equations of a proof
+	// does not hold, see ProofError.Equation.
+	ErrEquationFailed = errors.New("verification equation failed")
+	// ErrInvalidWitness means a prover was given a witness that does not
+	// satisfy the statement, so it refused to make a proof.
+	ErrInvalidWitness = errors.New("invalid witness")
+	// ErrMalformedProof means a proof is missing fields or could not be
+	// decoded.
+	ErrMalformedProof = errors.New("malformed proof")
+	// ErrWrongStatement means a proof was verified against a statement it is
+	// not about: VerifyStatement was given a statement for a different type
+	// of proof, or GSPFSProof.Verify a base other than the proof's.
+	ErrWrongStatement = errors.New("wrong statement")
+	// ErrUnknownProofType means an envelope names an unregistered proof type.
+	ErrUnknownProofType = errors.New("unknown proof type")
+	// ErrInvalidPoint means a prover or a verifier was given a point, in the
+	// statement or the proof, that is missing, not on the curve or the
+	// identity.
+	ErrInvalidPoint = errors.New("invalid point")
+	// ErrInvalidScalar means a proof contains a scalar that is missing or not
+	// in [0, N).
+	ErrInvalidScalar = errors.New("invalid scalar")
+	// ErrHashMismatch means a proof was made with another ChallengeHash than
+	// the ZKPCurveParams it is verified with.
+	ErrHashMismatch = errors.New("challenge hash mismatch")
+	// ErrInvalidParams means ZKPCurveParams, e.g. those of a statement being
+	// encoded, have no group.
+	ErrInvalidParams = errors.New("invalid curve parameters")
+)
+
+// ProofError is the error returned when making, verifying or decoding a proof
+// fails.  Err is one of the sentinel errors above, or for a proof containing
+// another proof, like ABCProof, the inner proof's ProofError, or the error of
+// the context a range proof was made or verified under.
+type ProofError struct {
+	Type     ProofType // type of the proof, 0 if not known
+	Op       string    // function that failed, e.g. "NewGSPFSProof" or "GSPFSProof.Verify"
+	Err      error
+	Equation int    // verification equation that failed, counting from 1, or 0
+	Msg      string // details
+}
+
+func (e *ProofError) Error() string {
+	s := fmt.Sprintf("%v - %v", e.Op, e.Err)
+	if e.Equation != 0 {
+		s += fmt.Sprintf(" (equation %d)", e.Equation)
+	}
+	if e.Msg != "" {
+		s += ": " + e.Msg
+	}
+	return s
+}
+
+func (e *ProofError) Unwrap() error {
+	return e.Err
+}
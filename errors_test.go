@@ -0,0 +1,109 @@
+package zksigma
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestProofErrors(t *testing.T) {
+	x := big.NewInt(12)
+	X := TestCurve.Mult(TestCurve.G, x)
+
+	isErr := func(name string, err, target error, typ ProofType, equation int) {
+		t.Helper()
+		if !errors.Is(err, target) {
+			t.Fatalf("%s: expected %v, got %v\n", name, target, err)
+		}
+		var pe *ProofError
+		if !errors.As(err, &pe) {
+			t.Fatalf("%s: expected a *ProofError, got %T\n", name, err)
+		}
+		if pe.Type != typ || pe.Equation != equation {
+			t.Fatalf("%s: expected %v equation %d, got %v equation %d\n", name, typ, equation, pe.Type, pe.Equation)
+		}
+		if strings.HasSuffix(err.Error(), "\n") {
+			t.Fatalf("%s: error message should not end in a newline\n", name)
+		}
+	}
+
+	_, err := NewGSPFSProof(TestCurve, X, big.NewInt(13))
+	isErr("wrong witness", err, ErrInvalidWitness, GSPFSProofType, 0)
+
+	gspfs, err := NewGSPFSProof(TestCurve, X, x)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	bad := *gspfs
+	bad.Challenge = new(big.Int).Add(gspfs.Challenge, big.NewInt(1))
+	_, err = bad.Verify(TestCurve, X)
+	isErr("wrong challenge", err, ErrChallengeMismatch, GSPFSProofType, 0)
+
+	XH := TestCurve.Mult(TestCurve.H, x)
+	eq, err := NewEquivalenceProof(TestCurve, TestCurve.G, X, TestCurve.H, XH, x)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	badEq := *eq
+	badEq.HiddenValue = new(big.Int).Add(eq.HiddenValue, big.NewInt(1))
+	_, err = badEq.Verify(TestCurve, TestCurve.G, X, TestCurve.H, XH)
+	isErr("wrong response", err, ErrEquationFailed, EquivalenceProofType, 1)
+
+	_, err = gspfs.VerifyStatement(RangeStatement{TestCurve, X})
+	isErr("wrong statement", err, ErrWrongStatement, GSPFSProofType, 0)
+
+	// An ABC proof with a broken inner proof reports the inner failure.
+	PK, sk := KeyGen(TestCurve.C, TestCurve.H)
+	CM, u, err := PedCommit(TestCurve, x)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	CMTok := TestCurve.Mult(PK, u)
+	abc, err := NewABCProof(TestCurve, CM, CMTok, x, sk, Right)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	dj := *abc.DisjuncAC
+	dj.C = new(big.Int).Add(dj.C, big.NewInt(1))
+	abc.DisjuncAC = &dj
+	_, err = abc.Verify(TestCurve, CM, CMTok)
+	isErr("inner proof", err, ErrChallengeMismatch, ABCProofType, 0)
+	var inner *ProofError
+	if !errors.As(errors.Unwrap(err), &inner) || inner.Type != DisjunctiveProofType {
+		t.Fatalf("expected the disjunctive proof's error inside, got %v\n", err)
+	}
+
+	// An inequality proof reports failures of the ABC proof it is made of as
+	// its own.
+	CMB, uB, err := PedCommit(TestCurve, big.NewInt(13))
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	CMTokB := TestCurve.Mult(PK, uB)
+	ie, err := NewInequalityProof(TestCurve, CM, CMB, CMTok, CMTokB, x, big.NewInt(13), sk)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	ie.Challenge = new(big.Int).Add(ie.Challenge, big.NewInt(1))
+	_, err = ie.Verify(TestCurve, TestCurve.Sub(CM, CMB), TestCurve.Sub(CMTok, CMTokB))
+	isErr("inequality", err, ErrChallengeMismatch, InequalityProofType, 0)
+	if pe := err.(*ProofError); pe.Op != "InequalityProof.Verify" {
+		t.Fatalf("expected InequalityProof.Verify, got %v\n", pe.Op)
+	}
+
+	b := EncodeProof(gspfs)
+	_, err = DecodeProof(b[:len(b)-1])
+	isErr("truncated", err, ErrMalformedProof, 0, 0)
+	b[1] = 0xff
+	_, err = DecodeProof(b)
+	isErr("unknown type", err, ErrUnknownProofType, ProofType(0xff), 0)
+
+	// A failing stream is reported as itself, not as a malformed proof.
+	_, err = new(GSPFSProof).ReadFrom(io.MultiReader(bytes.NewReader(gspfs.Bytes()[:10]), failingReader{}))
+	if !errors.Is(err, errRand) || errors.Is(err, ErrMalformedProof) {
+		t.Fatalf("expected the reader's error, got %v\n", err)
+	}
+}
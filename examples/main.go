@@ -7,5 +7,9 @@ import (
 )
 
 func main() {
-	fmt.Println(zksigma.ZKCurve)
+	zkpcp, err := zksigma.NewZKPCurveParams(zksigma.Secp256k1(), []byte("zksigma example"))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(zkpcp.C.Name(), zkpcp.G, zkpcp.H)
 }
@@ -0,0 +1,169 @@
+package zksigma
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+)
+
+// Group is a prime-order group that the proofs in zksigma are computed in.
+// Points of every group are carried around as ECPoints, and every group uses
+// Zero as its identity element. Scalars are big.Ints modulo Order().
+//
+// The proofs only ever talk to a Group through ZKPCurveParams, so adding a new
+// backend means implementing this interface; see Secp256k1 for the reference
+// implementation.
+type Group interface {
+	// Name returns a short, stable identifier for the group, e.g. "secp256k1".
+	Name() string
+
+	// Order returns N, the prime order of the group.
+	Order() *big.Int
+
+	// ReduceScalar returns s mod N.
+	ReduceScalar(s *big.Int) *big.Int
+	// AddScalars returns a + b mod N.
+	AddScalars(a, b *big.Int) *big.Int
+	// SubScalars returns a - b mod N.
+	SubScalars(a, b *big.Int) *big.Int
+	// MulScalars returns a * b mod N.
+	MulScalars(a, b *big.Int) *big.Int
+	// NegScalar returns -s mod N.
+	NegScalar(s *big.Int) *big.Int
+	// InvertScalar returns the multiplicative inverse of s mod N, or nil if
+	// s is 0 mod N.
+	InvertScalar(s *big.Int) *big.Int
+	// RandomScalar returns a uniformly distributed scalar in [0, N) read from r.
+	RandomScalar(r io.Reader) (*big.Int, error)
+
+	// Generator returns the standard base point of the group.
+	Generator() ECPoint
+	// Identity returns the identity element of the group.
+	Identity() ECPoint
+	// IsIdentity returns true if p is the identity element.
+	IsIdentity(p ECPoint) bool
+	// Contains returns true if p is an element of the group, identity included.
+	Contains(p ECPoint) bool
+
+	// Add returns p + q.
+	Add(p, q ECPoint) ECPoint
+	// Neg returns -p.
+	Neg(p ECPoint) ECPoint
+	// ScalarMult returns k * p.
+	ScalarMult(p ECPoint, k *big.Int) ECPoint
+	// ScalarBaseMult returns k * Generator().
+	ScalarBaseMult(k *big.Int) ECPoint
+
+	// PointSize returns the length in bytes of every encoding Encode produces.
+	PointSize() int
+	// Encode returns the canonical encoding of p.
+	Encode(p ECPoint) []byte
+	// Decode parses an encoding produced by Encode, returning an error if b
+	// is not the encoding of an element of the group.
+	Decode(b []byte) (ECPoint, error)
+
+	// HashToPoint deterministically maps msg to a group element whose
+	// discrete log with respect to Generator() is unknown.
+	HashToPoint(msg []byte) ECPoint
+}
+
+// SecretScalarMultiplier is implemented by Groups whose ScalarMult takes
+// time that depends on the scalar.  SecretScalarMult returns the same point in
+// time that does not depend on k.  zksigma uses it whenever k is a secret key,
+// a witness or a nonce, and keeps ScalarMult for the public scalars verifiers
+// multiply by.
+type SecretScalarMultiplier interface {
+	SecretScalarMult(p ECPoint, k *big.Int) ECPoint
+}
+
+// secretScalarMult computes k * p with g's SecretScalarMult, if it has one.
+func secretScalarMult(g Group, p ECPoint, k *big.Int) ECPoint {
+	if m, ok := g.(SecretScalarMultiplier); ok {
+		return m.SecretScalarMult(p, k)
+	}
+	return g.ScalarMult(p, k)
+}
+
+var (
+	groupsMu sync.RWMutex
+	groups   = make(map[string]Group)
+)
+
+// RegisterGroup makes g available to the proof decoders under g.Name().
+// Secp256k1, Ristretto255 and P256 are registered by default.
+func RegisterGroup(g Group) {
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+	groups[g.Name()] = g
+}
+
+// GroupByName returns the registered Group called name.
+func GroupByName(name string) (Group, error) {
+	groupsMu.RLock()
+	defer groupsMu.RUnlock()
+	g, ok := groups[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown group %q", name)
+	}
+	return g, nil
+}
+
+func init() {
+	RegisterGroup(Secp256k1())
+	RegisterGroup(Ristretto255())
+	RegisterGroup(P256())
+}
+
+// scalarField implements the scalar half of the Group interface for a group of
+// prime order n.  Backends embed it.
+type scalarField struct {
+	n *big.Int
+}
+
+func (f scalarField) Order() *big.Int {
+	return f.n
+}
+
+func (f scalarField) ReduceScalar(s *big.Int) *big.Int {
+	return new(big.Int).Mod(s, f.n)
+}
+
+func (f scalarField) AddScalars(a, b *big.Int) *big.Int {
+	r := new(big.Int).Add(a, b)
+	return r.Mod(r, f.n)
+}
+
+func (f scalarField) SubScalars(a, b *big.Int) *big.Int {
+	r := new(big.Int).Sub(a, b)
+	return r.Mod(r, f.n)
+}
+
+func (f scalarField) MulScalars(a, b *big.Int) *big.Int {
+	r := new(big.Int).Mul(a, b)
+	return r.Mod(r, f.n)
+}
+
+func (f scalarField) NegScalar(s *big.Int) *big.Int {
+	r := new(big.Int).Neg(s)
+	return r.Mod(r, f.n)
+}
+
+func (f scalarField) InvertScalar(s *big.Int) *big.Int {
+	return new(big.Int).ModInverse(f.ReduceScalar(s), f.n)
+}
+
+func (f scalarField) RandomScalar(r io.Reader) (*big.Int, error) {
+	return rand.Int(r, f.n)
+}
+
+// isZeroBytes returns true if every byte of b is zero.
+func isZeroBytes(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+package zksigma
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/mit-dci/zksigma/btcec"
+)
+
+func TestGroupLaws(t *testing.T) {
+	forEachCurve(t, testGroupLaws)
+}
+
+func testGroupLaws(t *testing.T, zkpcp ZKPCurveParams) {
+	curve := zkpcp.C
+
+	a, _ := curve.RandomScalar(rand.Reader)
+	b, _ := curve.RandomScalar(rand.Reader)
+	A := curve.ScalarBaseMult(a)
+	B := curve.ScalarBaseMult(b)
+
+	// aG + bG = (a + b)G
+	if !curve.Add(A, B).Equal(curve.ScalarBaseMult(curve.AddScalars(a, b))) {
+		t.Fatalf("aG + bG should be (a + b)G\n")
+	}
+
+	// b(aG) = (ab)G
+	if !curve.ScalarMult(A, b).Equal(curve.ScalarBaseMult(curve.MulScalars(a, b))) {
+		t.Fatalf("b(aG) should be (ab)G\n")
+	}
+
+	// aG + -aG = 0
+	if !curve.IsIdentity(curve.Add(A, curve.Neg(A))) {
+		t.Fatalf("aG + -aG should be the identity\n")
+	}
+
+	// a * inv(a) = 1
+	if curve.MulScalars(a, curve.InvertScalar(a)).Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("a * inv(a) should be 1\n")
+	}
+
+	if !curve.Contains(A) || !curve.Contains(curve.Identity()) {
+		t.Fatalf("group should contain aG and the identity\n")
+	}
+}
+
+func TestSecretScalarMult(t *testing.T) {
+	forEachCurve(t, testSecretScalarMult)
+}
+
+func testSecretScalarMult(t *testing.T, zkpcp ZKPCurveParams) {
+	curve := zkpcp.C
+	m, ok := curve.(SecretScalarMultiplier)
+	if !ok {
+		t.Fatalf("%s has no SecretScalarMult\n", curve.Name())
+	}
+
+	a, _ := curve.RandomScalar(rand.Reader)
+	N := curve.Order()
+	scalars := []*big.Int{a, big.NewInt(0), big.NewInt(1), big.NewInt(2), big.NewInt(15), big.NewInt(16),
+		new(big.Int).Sub(N, big.NewInt(1)), N, new(big.Int).Add(N, a)}
+	b, _ := curve.RandomScalar(rand.Reader)
+	scalars = append(scalars, b, curve.ReduceScalar(new(big.Int).Lsh(b, 3)))
+	points := []ECPoint{zkpcp.G, zkpcp.H, curve.ScalarBaseMult(a), curve.Identity()}
+	for _, p := range points {
+		for _, k := range scalars {
+			if !m.SecretScalarMult(p, k).Equal(curve.ScalarMult(p, k)) {
+				t.Fatalf("SecretScalarMult(%v, %v) differs from ScalarMult\n", p, k)
+			}
+		}
+	}
+	if !zkpcp.MultSecret(zkpcp.H, a).Equal(zkpcp.Mult(zkpcp.H, a)) {
+		t.Fatalf("MultSecret differs from Mult\n")
+	}
+}
+
+func TestGroupOffCurve(t *testing.T) {
+	forEachCurve(t, testGroupOffCurve)
+}
+
+// testGroupOffCurve feeds a point that is on none of the curves to the group
+// arithmetic and to the provers, which must neither panic nor produce a group
+// element from it.
+func testGroupOffCurve(t *testing.T, zkpcp ZKPCurveParams) {
+	curve := zkpcp.C
+	off := ECPoint{big.NewInt(1), big.NewInt(2)}
+	if curve.Contains(off) {
+		t.Fatalf("(1, 2) should not be in %s\n", curve.Name())
+	}
+	k := big.NewInt(3)
+	for _, p := range []ECPoint{
+		curve.Add(off, zkpcp.G), curve.Add(zkpcp.G, off), curve.Neg(off), curve.ScalarMult(off, k),
+		curve.(SecretScalarMultiplier).SecretScalarMult(off, k),
+		zkpcp.Add(off, zkpcp.G), zkpcp.Sub(zkpcp.G, off), zkpcp.Mult(off, k), zkpcp.MultSecret(off, k),
+	} {
+		if curve.Contains(p) {
+			t.Fatalf("arithmetic on %v gave the group element %v\n", off, p)
+		}
+	}
+
+	x := big.NewInt(5)
+	X := zkpcp.Mult(zkpcp.G, x)
+	if _, err := NewGSPFSProofBase(zkpcp, off, X, x); !errors.Is(err, ErrInvalidPoint) {
+		t.Fatalf("GSPFS proof over an off-curve base: expected %v, got %v\n", ErrInvalidPoint, err)
+	}
+	if _, err := NewDisjunctiveProof(zkpcp, zkpcp.G, X, off, zkpcp.H, x, Left); !errors.Is(err, ErrInvalidPoint) {
+		t.Fatalf("disjunctive proof over an off-curve base: expected %v, got %v\n", ErrInvalidPoint, err)
+	}
+	if _, err := NewCompactEquivalenceProof(zkpcp, zkpcp.G, X, off, X, x); !errors.Is(err, ErrInvalidPoint) {
+		t.Fatalf("compact equivalence proof over an off-curve base: expected %v, got %v\n", ErrInvalidPoint, err)
+	}
+}
+
+func TestGroupEncoding(t *testing.T) {
+	forEachCurve(t, testGroupEncoding)
+}
+
+func testGroupEncoding(t *testing.T, zkpcp ZKPCurveParams) {
+	curve := zkpcp.C
+
+	a, _ := curve.RandomScalar(rand.Reader)
+	for _, p := range []ECPoint{curve.ScalarBaseMult(a), curve.Identity()} {
+		q, err := curve.Decode(curve.Encode(p))
+		if err != nil {
+			t.Fatalf("failed to decode encoded point: %v\n", err)
+		}
+		if !q.Equal(p) {
+			t.Fatalf("decoded point %v does not match %v\n", q, p)
+		}
+	}
+
+	bad := curve.Encode(curve.ScalarBaseMult(a))
+	bad[0] ^= 0xff
+	if _, err := curve.Decode(bad); err == nil {
+		t.Fatalf("decoding a corrupted point should fail\n")
+	}
+}
+
+func TestSecp256k1H(t *testing.T) {
+	// Mult takes btcec's precomputed path for H, which is only correct if
+	// TestCurve.H is the point those tables were built for.
+	hX, hY := btcec.S256().ScalarBaseMultH(big.NewInt(1).Bytes())
+	if !TestCurve.H.Equal(ECPoint{hX, hY}) {
+		t.Fatalf("TestCurve.H is not btcec's H\n")
+	}
+
+	k, _ := TestCurve.C.RandomScalar(rand.Reader)
+	X, Y := btcec.S256().ScalarMult(TestCurve.H.X, TestCurve.H.Y, k.Bytes())
+	if !TestCurve.Mult(TestCurve.H, k).Equal(ECPoint{X, Y}) {
+		t.Fatalf("Mult(H, k) does not agree with generic scalar multiplication\n")
+	}
+}
+
+func TestP256H(t *testing.T) {
+	curve := P256()
+	H := curve.HashToPoint(new(big.Int).Add(curve.Generator().X, big.NewInt(2)).Bytes())
+	if !curve.Contains(H) {
+		t.Fatalf("P-256 H is not on the curve\n")
+	}
+
+	// The table path for H must agree with crypto/elliptic's generic path.
+	for _, k := range []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(17), new(big.Int).Sub(curve.Order(), big.NewInt(1))} {
+		X, Y := elliptic.P256().ScalarMult(H.X, H.Y, k.Bytes())
+		if !curve.ScalarMult(H, k).Equal(ECPoint{X, Y}) {
+			t.Fatalf("ScalarMult(H, %v) does not agree with generic scalar multiplication\n", k)
+		}
+	}
+	k, _ := curve.RandomScalar(rand.Reader)
+	X, Y := elliptic.P256().ScalarMult(H.X, H.Y, k.Bytes())
+	if !curve.ScalarMult(H, k).Equal(ECPoint{X, Y}) {
+		t.Fatalf("ScalarMult(H, k) does not agree with generic scalar multiplication\n")
+	}
+}
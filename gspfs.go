@@ -1,9 +1,7 @@
 package zksigma
 
 import (
-	"bytes"
-	"crypto/rand"
-	"fmt"
+	"io"
 	"math/big"
 )
 
@@ -19,16 +17,21 @@ import (
 //  selects random u
 //  T1 = uG
 //  c = HASH(G, xG, uG)
-//  s = u + c * x
+//  s = u - c * x
 //
 //  T1, s, c -------------------------->
 //                                      c ?= HASH(G, A, T1)
-//                                      sG ?= T1 + cA
+//                                      T1 ?= sG + cA
+//
+// The base G is part of the statement: the verifier must be told it, and
+// checks that the proof's Base is the same point.
 type GSPFSProof struct {
-	Base        ECPoint  // Base point
-	RandCommit  ECPoint  // this is H = uG, where u is random value and G is a generator point
-	HiddenValue *big.Int // s = x * c + u, here c is the challenge and x is what we want to prove knowledge of
-	Challenge   *big.Int // challenge string hash sum, only use for sanity checks
+	Base        ECPoint       // Base point
+	RandCommit  ECPoint       // this is H = uG, where u is random value and G is a generator point
+	HiddenValue *big.Int      // s = x * c + u, here c is the challenge and x is what we want to prove knowledge of
+	Challenge   *big.Int      // challenge string hash sum, only use for sanity checks
+	curve       Group         // group the proof was computed in
+	hash        ChallengeHash // hash the challenges were computed with
 }
 
 // NewGSPFSProof generates a Schnorr proof for the value x using the
@@ -41,82 +44,239 @@ func NewGSPFSProof(zkpcp ZKPCurveParams, A ECPoint, x *big.Int) (*GSPFSProof, er
 // NewGSPFSProofBase is the same as NewGSPFSProof, except it allows you to specify
 // your own base point in parameter base, instead of using the first base point from zkpcp.
 func NewGSPFSProofBase(zkpcp ZKPCurveParams, base, A ECPoint, x *big.Int) (*GSPFSProof, error) {
-	modValue := new(big.Int).Mod(x, zkpcp.C.Params().N)
+	in := zkpcp.validateInputs(GSPFSProofType, "NewGSPFSProof")
+	in.point("base", base)
+	in.point("A", A)
+	if err := in.done(); err != nil {
+		return nil, err
+	}
+
+	modValue := zkpcp.C.ReduceScalar(x)
+	defer WipeScalars(modValue)
 
 	// A = xG, G is any base point in this proof
-	cX, cY := zkpcp.C.ScalarMult(base.X, base.Y, modValue.Bytes())
-	C := ECPoint{cX, cY}
+	C := zkpcp.MultSecret(base, modValue)
 	if !C.Equal(A) {
-		return nil, &errorProof{"GSPFSProve:", "the point given is not xG"}
+		return nil, zkpcp.traceError(&ProofError{Type: GSPFSProofType, Op: "NewGSPFSProof", Err: ErrInvalidWitness, Msg: "the point given is not xG"})
 	}
 
-	u, err := rand.Int(rand.Reader, zkpcp.C.Params().N)
+	nonces, err := zkpcp.newNonceSource(GSPFSProofType, []*big.Int{x}, base, A)
+	if err != nil {
+		return nil, err
+	}
+	defer nonces.destroy()
+	u, err := nonces.scalar()
 	if err != nil {
 		return nil, err
 	}
 
 	// generate random point uG
-	uG := zkpcp.Mult(base, u)
+	uG := zkpcp.MultSecret(base, u)
 
 	// generate hashed string challenge
-	c := GenerateChallenge(zkpcp, A.Bytes(), uG.Bytes())
+	c := GenerateChallenge(zkpcp, zkpcp.Bytes(base), zkpcp.Bytes(A), zkpcp.Bytes(uG))
+	zkpcp.trace(GSPFSProofType, "prover commitment", "RandCommit", zkpcp.logPoint(uG), "challenge", c)
 
 	// v = u - c * x
-	v := new(big.Int).Sub(u, new(big.Int).Mul(c, modValue))
-	v = v.Mod(v, zkpcp.C.Params().N)
+	v := zkpcp.C.SubScalars(u, zkpcp.C.MulScalars(c, modValue))
 
-	return &GSPFSProof{base, uG, v, c}, nil
+	return &GSPFSProof{base, uG, v, c, zkpcp.C, zkpcp.Hash}, nil
 }
 
-// Verify (GSPFSVerify) checks if GSPFSProof proof is a valid proof for commitment A
+// Verify (GSPFSVerify) checks if GSPFSProof proof is a valid proof for
+// commitment A = x * zkpcp.G, see VerifyBase
 func (proof *GSPFSProof) Verify(zkpcp ZKPCurveParams, A ECPoint) (bool, error) {
+	return proof.VerifyBase(zkpcp, zkpcp.G, A)
+}
+
+// VerifyBase checks if GSPFSProof proof is a valid proof for commitment
+// A = x * base.  A proof made for any other base is rejected.
+func (proof *GSPFSProof) VerifyBase(zkpcp ZKPCurveParams, base, A ECPoint) (bool, error) {
 
 	if proof == nil {
-		return false, &errorProof{"GSPFSProof.Verify", fmt.Sprintf("passed proof is nil")}
+		return false, zkpcp.traceError(&ProofError{Type: GSPFSProofType, Op: "GSPFSProof.Verify", Err: ErrMalformedProof, Msg: "passed proof is nil"})
+	}
+
+	v := zkpcp.validateInputs(GSPFSProofType, "GSPFSProof.Verify")
+	v.hash(proof.hash)
+	v.point("base", base)
+	v.point("A", A)
+	v.point("Base", proof.Base)
+	v.point("RandCommit", proof.RandCommit)
+	v.scalar("HiddenValue", proof.HiddenValue)
+	v.scalar("Challenge", proof.Challenge)
+	if err := v.done(); err != nil {
+		return false, err
+	}
+
+	// The prover picks Base, so it must be the one the statement names
+	if !proof.Base.Equal(base) {
+		return false, zkpcp.traceError(&ProofError{Type: GSPFSProofType, Op: "GSPFSProof.Verify", Err: ErrWrongStatement, Msg: "proof is for another base point"})
 	}
 
-	// A = xG and RandCommit = uG
-	testC := GenerateChallenge(zkpcp, A.Bytes(), proof.RandCommit.Bytes())
+	// A = xBase and RandCommit = uBase
+	testC := GenerateChallenge(zkpcp, zkpcp.Bytes(base), zkpcp.Bytes(A), zkpcp.Bytes(proof.RandCommit))
 
 	if testC.Cmp(proof.Challenge) != 0 {
-		return false, &errorProof{"GSPFSProof.Verify", "calculated challenge and proof's challenge do not agree!"}
+		return false, zkpcp.traceError(&ProofError{Type: GSPFSProofType, Op: "GSPFSProof.Verify", Err: ErrChallengeMismatch, Msg: "calculated challenge and proof's challenge do not agree!"})
 	}
+	zkpcp.trace(GSPFSProofType, "verifier challenge", "challenge", testC)
 
-	// (u - c * x)G, look at HiddenValue from GSPFS.Proof()
-	s := zkpcp.Mult(proof.Base, proof.HiddenValue)
+	// (u - c * x)Base, look at HiddenValue from GSPFS.Proof()
+	s := zkpcp.Mult(base, proof.HiddenValue)
 
-	// cResult = c(xG), we use testC as that follows the proof verficaion process more closely than using Challenge
+	// cResult = c(xBase)
 	c := zkpcp.Mult(A, proof.Challenge)
 
-	// cxG + (u - cx)G = uG
+	// cxBase + (u - cx)Base = uBase
 	tot := zkpcp.Add(s, c)
 
 	if !proof.RandCommit.Equal(tot) {
-		return false, &errorProof{"GSPFSProof.Verify", "proof's final value and verification final value do not agree!"}
+		return false, zkpcp.traceError(&ProofError{Type: GSPFSProofType, Op: "GSPFSProof.Verify", Err: ErrEquationFailed, Equation: 1, Msg: "proof's final value and verification final value do not agree!"})
 	}
+	zkpcp.trace(GSPFSProofType, "verified")
 	return true, nil
 }
 
-// Bytes returns a byte slice with a serialized representation of GSPFSProof proof
-func (proof *GSPFSProof) Bytes() []byte {
-	var buf bytes.Buffer
+// ChallengeHash returns the hash the challenges of proof were computed with
+func (proof *GSPFSProof) ChallengeHash() ChallengeHash {
+	return proof.hash
+}
+
+// Type returns GSPFSProofType
+func (proof *GSPFSProof) Type() ProofType {
+	return GSPFSProofType
+}
+
+// VerifyStatement checks proof against a GSPFSStatement, see Verify
+func (proof *GSPFSProof) VerifyStatement(s Statement) (bool, error) {
+	st, ok := s.(GSPFSStatement)
+	if !ok {
+		return false, wrongStatement(GSPFSProofType, s)
+	}
+	return proof.VerifyBase(st.Params, st.Base, st.A)
+}
+
+// Diagnose runs every check of Verify and reports the outcome of each, see
+// VerificationReport
+func (proof *GSPFSProof) Diagnose(zkpcp ZKPCurveParams, A ECPoint) *VerificationReport {
+	return proof.DiagnoseBase(zkpcp, zkpcp.G, A)
+}
+
+// DiagnoseBase runs every check of VerifyBase and reports the outcome of
+// each, see VerificationReport
+func (proof *GSPFSProof) DiagnoseBase(zkpcp ZKPCurveParams, base, A ECPoint) *VerificationReport {
+	r := newReport(GSPFSProofType, zkpcp)
+	if proof == nil {
+		return r.malformed()
+	}
+	if !r.wellFormed([]ECPoint{base, A, proof.Base, proof.RandCommit}, []*big.Int{proof.HiddenValue, proof.Challenge}) {
+		return r
+	}
+	r.hash(proof.hash)
+
+	r.point("Base == base", 0, proof.Base, base)
 
-	WriteECPoint(&buf, proof.Base)
-	WriteECPoint(&buf, proof.RandCommit)
-	WriteBigInt(&buf, proof.HiddenValue)
-	WriteBigInt(&buf, proof.Challenge)
+	testC := GenerateChallenge(zkpcp, zkpcp.Bytes(base), zkpcp.Bytes(A), zkpcp.Bytes(proof.RandCommit))
+	r.scalar("HASH(base, A, RandCommit) == Challenge", testC, proof.Challenge)
+
+	tot := zkpcp.Add(zkpcp.Mult(base, proof.HiddenValue), zkpcp.Mult(A, proof.Challenge))
+	r.point("HiddenValue*base + Challenge*A == RandCommit", 1, tot, proof.RandCommit)
+	return r
+}
+
+// DiagnoseStatement diagnoses proof against a GSPFSStatement, see Diagnose
+func (proof *GSPFSProof) DiagnoseStatement(s Statement) (*VerificationReport, error) {
+	st, ok := s.(GSPFSStatement)
+	if !ok {
+		return nil, wrongStatement(GSPFSProofType, s)
+	}
+	return proof.DiagnoseBase(st.Params, st.Base, st.A), nil
+}
+
+// WriteTo writes the serialized representation of GSPFSProof proof to w
+func (proof *GSPFSProof) WriteTo(w io.Writer) (int64, error) {
+	pw := newProofWriter(w, proofCurve(proof.curve), proof.hash)
+	pw.point(proof.Base)
+	pw.point(proof.RandCommit)
+	pw.scalar(proof.HiddenValue)
+	pw.scalar(proof.Challenge)
+	return pw.done()
+}
+
+// ReadFrom reads one GSPFSProof written by WriteTo from r into proof.  It reads no
+// further than the end of the proof, so proofs can be read back to back from
+// a stream; io.EOF is returned if r ends before the next proof starts.
+func (proof *GSPFSProof) ReadFrom(r io.Reader) (int64, error) {
+	var p GSPFSProof
+	pr := newProofReader("GSPFSProof.ReadFrom", r)
+	p.curve, p.hash = pr.curve, pr.hash
+	p.Base = pr.point("Base")
+	p.RandCommit = pr.point("RandCommit")
+	p.HiddenValue = pr.scalar("HiddenValue")
+	p.Challenge = pr.scalar("Challenge")
+	n, err := pr.done()
+	if err != nil {
+		return n, err
+	}
+	*proof = p
+	return n, nil
+}
 
-	return buf.Bytes()
+// MarshalBinary implements encoding.BinaryMarshaler
+func (proof *GSPFSProof) MarshalBinary() ([]byte, error) {
+	return marshalProof(proof)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.  b must hold exactly
+// one proof.
+func (proof *GSPFSProof) UnmarshalBinary(b []byte) error {
+	return unmarshalProof("GSPFSProof.UnmarshalBinary", b, proof)
+}
+
+// Bytes returns a byte slice with a serialized representation of GSPFSProof proof, or
+// nil if proof can not be serialized
+func (proof *GSPFSProof) Bytes() []byte {
+	b, _ := proof.MarshalBinary()
+	return b
 }
 
 // NewGSPFSProofFromBytes returns a GSPFSProof generated from the
 // deserialization of byte slice b
 func NewGSPFSProofFromBytes(b []byte) (*GSPFSProof, error) {
 	proof := new(GSPFSProof)
-	buf := bytes.NewBuffer(b)
-	proof.Base, _ = ReadECPoint(buf)
-	proof.RandCommit, _ = ReadECPoint(buf)
-	proof.HiddenValue, _ = ReadBigInt(buf)
-	proof.Challenge, _ = ReadBigInt(buf)
+	if err := proof.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
 	return proof, nil
 }
+
+// MarshalJSON encodes proof as a JSON object in its group, see Group.Encode
+func (proof *GSPFSProof) MarshalJSON() ([]byte, error) {
+	w := newJSONWriter(proofCurve(proof.curve))
+	w.group()
+	w.hash(proof.hash)
+	w.point("Base", proof.Base)
+	w.point("RandCommit", proof.RandCommit)
+	w.scalar("HiddenValue", proof.HiddenValue)
+	w.scalar("Challenge", proof.Challenge)
+	return w.marshal()
+}
+
+// UnmarshalJSON decodes a proof written by MarshalJSON
+func (proof *GSPFSProof) UnmarshalJSON(b []byte) error {
+	var p GSPFSProof
+	r := newJSONReader("GSPFSProof.UnmarshalJSON", b)
+	r.group()
+	p.hash = r.hash()
+	p.Base = r.point("Base")
+	p.RandCommit = r.point("RandCommit")
+	p.HiddenValue = r.scalar("HiddenValue")
+	p.Challenge = r.scalar("Challenge")
+	if err := r.done(); err != nil {
+		return err
+	}
+	p.curve = r.curve
+	*proof = p
+	return nil
+}
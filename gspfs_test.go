@@ -2,25 +2,31 @@ package zksigma
 
 import (
 	"crypto/rand"
+	"errors"
+	"math/big"
 	"testing"
 )
 
 func TestGSPFS(t *testing.T) {
+	forEachCurve(t, testGSPFS)
+}
+
+func testGSPFS(t *testing.T, zkpcp ZKPCurveParams) {
 
-	x, err := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	x, err := rand.Int(rand.Reader, zkpcp.C.Order())
 	if err != nil {
 		t.Fatalf("%v\n", err)
 	}
 
 	// MUST use G here because of GSPFSProve implementation
-	result := TestCurve.Mult(TestCurve.G, x)
+	result := zkpcp.Mult(zkpcp.G, x)
 
-	testProof, err := NewGSPFSProof(TestCurve, result, x)
+	testProof, err := NewGSPFSProof(zkpcp, result, x)
 	if err != nil {
 		t.Fatalf("%v\n", err)
 	}
 
-	status, err := testProof.Verify(TestCurve, result)
+	status, err := testProof.Verify(zkpcp, result)
 	if !status && err == nil {
 		t.Logf("x : %v\n", x)
 		t.Logf("randPoint : %v\n", result)
@@ -29,10 +35,10 @@ func TestGSPFS(t *testing.T) {
 	}
 
 	// Using H here should break the proof
-	result = TestCurve.Mult(TestCurve.H, x)
+	result = zkpcp.Mult(zkpcp.H, x)
 
 	t.Logf("Next GSPFSVerify should fail\n")
-	status, err = testProof.Verify(TestCurve, result)
+	status, err = testProof.Verify(zkpcp, result)
 	if status && err != nil {
 		t.Logf("x : %v\n", x)
 		t.Logf("randPoint : %v\n", result)
@@ -43,23 +49,60 @@ func TestGSPFS(t *testing.T) {
 }
 
 func TestGSPFSSerialization(t *testing.T) {
-	value, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
-	Base := TestCurve.G
-	CM := TestCurve.Mult(TestCurve.G, value)
-	proof, err := NewGSPFSProofBase(TestCurve, Base, CM, value)
+	forEachCurve(t, testGSPFSSerialization)
+}
+
+func testGSPFSSerialization(t *testing.T, zkpcp ZKPCurveParams) {
+	value, _ := rand.Int(rand.Reader, zkpcp.C.Order())
+	Base := zkpcp.G
+	CM := zkpcp.Mult(zkpcp.G, value)
+	proof, err := NewGSPFSProofBase(zkpcp, Base, CM, value)
 	proof, err = NewGSPFSProofFromBytes(proof.Bytes())
 	if err != nil {
 		t.Fatalf("TestGSPFSSerialization failed to deserialize\n")
 	}
-	ok, err := proof.Verify(TestCurve, CM)
+	ok, err := proof.Verify(zkpcp, CM)
 	if !ok || err != nil {
 		t.Fatalf("TestGSPFSSerialization failed to verify\n")
 	}
 
 }
 
+func TestGSPFSForgedBase(t *testing.T) {
+	forEachCurve(t, testGSPFSForgedBase)
+}
+
+// testGSPFSForgedBase checks that a prover cannot pick a base that makes the
+// equation hold for an A it does not know the discrete log of.
+func testGSPFSForgedBase(t *testing.T, zkpcp ZKPCurveParams) {
+	A := zkpcp.H // nobody knows log_G(H)
+	R := zkpcp.Mult(zkpcp.G, big.NewInt(77))
+	for name, c := range map[string]*big.Int{
+		"without base": GenerateChallenge(zkpcp, zkpcp.Bytes(A), zkpcp.Bytes(R)),
+		"with base G":  GenerateChallenge(zkpcp, zkpcp.Bytes(zkpcp.G), zkpcp.Bytes(A), zkpcp.Bytes(R)),
+	} {
+		// Base = R - cA makes 1 * Base + c * A == R
+		forged := &GSPFSProof{zkpcp.Sub(R, zkpcp.Mult(A, c)), R, big.NewInt(1), c, zkpcp.C, zkpcp.Hash}
+		decoded, err := DecodeProof(EncodeProof(forged))
+		if err != nil {
+			t.Fatalf("%s: %v\n", name, err)
+		}
+		for _, p := range []Proof{forged, decoded} {
+			if ok, err := p.VerifyStatement(GSPFSStatement{zkpcp, zkpcp.G, A}); ok || !errors.Is(err, ErrWrongStatement) {
+				t.Fatalf("%s: forged proof accepted for base G: %v\n", name, err)
+			}
+			if ok, err := p.VerifyStatement(GSPFSStatement{zkpcp, forged.Base, A}); ok || err == nil {
+				t.Fatalf("%s: forged proof accepted for its own base\n", name)
+			}
+		}
+		if ok, _ := forged.Verify(zkpcp, A); ok {
+			t.Fatalf("%s: forged proof accepted by Verify\n", name)
+		}
+	}
+}
+
 func BenchmarkGSPFS_AnyBase(b *testing.B) {
-	value, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	value, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 	Base := TestCurve.G
 	CM := TestCurve.Mult(TestCurve.G, value)
 	b.ResetTimer()
@@ -69,7 +112,7 @@ func BenchmarkGSPFS_AnyBase(b *testing.B) {
 }
 
 func BenchmarkGSPFS_Verify(b *testing.B) {
-	value, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	value, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 	Base := TestCurve.G
 	CM := TestCurve.Mult(TestCurve.G, value)
 	proof, err := NewGSPFSProofBase(TestCurve, Base, CM, value)
@@ -1,7 +1,7 @@
 package zksigma
 
 import (
-	"fmt"
+	"io"
 	"math/big"
 )
 
@@ -13,8 +13,17 @@ type InequalityProof ABCProof
 // There is no Inequality verify since this generates an ABCProof, so just use ABCVerify
 func NewInequalityProof(zkpcp ZKPCurveParams, A, B, CMTokA, CMTokB ECPoint, a, b, sk *big.Int) (*InequalityProof, error) {
 
+	in := zkpcp.validateInputs(InequalityProofType, "NewInequalityProof")
+	in.point("A", A)
+	in.point("B", B)
+	in.point("CMTokA", CMTokA)
+	in.point("CMTokB", CMTokB)
+	if err := in.done(); err != nil {
+		return nil, err
+	}
+
 	if a.Cmp(b) == 0 {
-		return nil, &errorProof{"InequalityProve", "a and b should not be equal..."}
+		return nil, zkpcp.traceError(&ProofError{Type: InequalityProofType, Op: "NewInequalityProof", Err: ErrInvalidWitness, Msg: "a and b should not be equal..."})
 	}
 
 	// should I check if a > b? I think that shouldn't be a problem
@@ -27,7 +36,7 @@ func NewInequalityProof(zkpcp ZKPCurveParams, A, B, CMTokA, CMTokB ECPoint, a, b
 	proof, proofStatus := NewABCProof(zkpcp, CM, CMTok, value, sk, Right)
 
 	if proofStatus != nil {
-		return nil, proofStatus
+		return nil, inequalityError(proofStatus, "NewInequalityProof")
 	}
 
 	return ((*InequalityProof)(proof)), proofStatus
@@ -36,8 +45,107 @@ func NewInequalityProof(zkpcp ZKPCurveParams, A, B, CMTokA, CMTokB ECPoint, a, b
 // Verify checks if InequalityProof ieProof with appropriate commits CM and CMTok is correct
 func (ieProof *InequalityProof) Verify(zkpcp ZKPCurveParams, CM, CMTok ECPoint) (bool, error) {
 	if ieProof == nil {
-		return false, &errorProof{"InequalityProof.Verify", fmt.Sprintf("passed proof is nil")}
+		return false, zkpcp.traceError(&ProofError{Type: InequalityProofType, Op: "InequalityProof.Verify", Err: ErrMalformedProof, Msg: "passed proof is nil"})
+	}
+
+	ok, err := ((*ABCProof)(ieProof)).Verify(zkpcp, CM, CMTok)
+	if err != nil {
+		return false, inequalityError(err, "InequalityProof.Verify")
+	}
+	return ok, nil
+}
+
+// inequalityError retags a ProofError from the ABCProof underneath an
+// InequalityProof as coming from op of the InequalityProof.
+func inequalityError(err error, op string) error {
+	pe, ok := err.(*ProofError)
+	if !ok {
+		return err
+	}
+	e := *pe
+	e.Type = InequalityProofType
+	e.Op = op
+	return &e
+}
+
+// ChallengeHash returns the hash the challenges of ieProof were computed with
+func (ieProof *InequalityProof) ChallengeHash() ChallengeHash {
+	return (*ABCProof)(ieProof).ChallengeHash()
+}
+
+// Type returns InequalityProofType
+func (ieProof *InequalityProof) Type() ProofType {
+	return InequalityProofType
+}
+
+// VerifyStatement checks ieProof against an InequalityStatement, see Verify
+func (ieProof *InequalityProof) VerifyStatement(s Statement) (bool, error) {
+	st, ok := s.(InequalityStatement)
+	if !ok {
+		return false, wrongStatement(InequalityProofType, s)
 	}
+	return ieProof.Verify(st.Params, st.CM, st.CMTok)
+}
+
+// Diagnose runs every check of Verify and reports the outcome of each, see
+// ABCProof.Diagnose
+func (ieProof *InequalityProof) Diagnose(zkpcp ZKPCurveParams, CM, CMTok ECPoint) *VerificationReport {
+	r := (*ABCProof)(ieProof).Diagnose(zkpcp, CM, CMTok)
+	r.Type = InequalityProofType
+	return r
+}
+
+// DiagnoseStatement diagnoses ieProof against an InequalityStatement, see
+// Diagnose
+func (ieProof *InequalityProof) DiagnoseStatement(s Statement) (*VerificationReport, error) {
+	st, ok := s.(InequalityStatement)
+	if !ok {
+		return nil, wrongStatement(InequalityProofType, s)
+	}
+	return ieProof.Diagnose(st.Params, st.CM, st.CMTok), nil
+}
+
+// WriteTo writes ieProof to w, see ABCProof.WriteTo
+func (ieProof *InequalityProof) WriteTo(w io.Writer) (int64, error) {
+	return ((*ABCProof)(ieProof)).WriteTo(w)
+}
+
+// ReadFrom reads one InequalityProof from r, see ABCProof.ReadFrom
+func (ieProof *InequalityProof) ReadFrom(r io.Reader) (int64, error) {
+	return ((*ABCProof)(ieProof)).ReadFrom(r)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (ieProof *InequalityProof) MarshalBinary() ([]byte, error) {
+	return ((*ABCProof)(ieProof)).MarshalBinary()
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (ieProof *InequalityProof) UnmarshalBinary(b []byte) error {
+	return ((*ABCProof)(ieProof)).UnmarshalBinary(b)
+}
+
+// Bytes returns a byte slice with a serialized representation of InequalityProof proof
+func (ieProof *InequalityProof) Bytes() []byte {
+	return ((*ABCProof)(ieProof)).Bytes()
+}
+
+// NewInequalityProofFromBytes returns an InequalityProof generated from the
+// deserialization of byte slice b
+func NewInequalityProofFromBytes(b []byte) (*InequalityProof, error) {
+	proof, err := NewABCProofFromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	return (*InequalityProof)(proof), nil
+}
+
+// MarshalJSON encodes ieProof as a JSON object, see ABCProof.MarshalJSON
+func (ieProof *InequalityProof) MarshalJSON() ([]byte, error) {
+	return ((*ABCProof)(ieProof)).MarshalJSON()
+}
 
-	return ((*ABCProof)(ieProof)).Verify(zkpcp, CM, CMTok)
+// UnmarshalJSON decodes a proof written by MarshalJSON
+func (ieProof *InequalityProof) UnmarshalJSON(b []byte) error {
+	return ((*ABCProof)(ieProof)).UnmarshalJSON(b)
 }
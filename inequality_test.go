@@ -7,52 +7,56 @@ import (
 )
 
 func TestInequalityProve(t *testing.T) {
+	forEachCurve(t, testInequalityProve)
+}
+
+func testInequalityProve(t *testing.T, zkpcp ZKPCurveParams) {
 
-	sk, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	sk, _ := rand.Int(rand.Reader, zkpcp.C.Order())
 	a, _ := rand.Int(rand.Reader, big.NewInt(10000000000)) // "realistic range"
 	b, _ := rand.Int(rand.Reader, big.NewInt(10000000000)) // "realistic range"
-	A, ua, err := PedCommit(TestCurve, a)
+	A, ua, err := PedCommit(zkpcp, a)
 
 	if err != nil {
 		t.Fatalf("%v\n", err)
 	}
 
-	B, ub, err := PedCommit(TestCurve, b)
+	B, ub, err := PedCommit(zkpcp, b)
 	if err != nil {
 		t.Fatalf("%v\n", err)
 	}
 
-	PK := TestCurve.Mult(TestCurve.H, sk)
+	PK := zkpcp.Mult(zkpcp.H, sk)
 
 	// Even though we generated the values for ua and ub in this test case, we do not
 	// need to know ua or ub, only the commitment tokens are needed
-	CMTokA := TestCurve.Mult(PK, ua)
-	CMTokB := TestCurve.Mult(PK, ub)
+	CMTokA := zkpcp.Mult(PK, ua)
+	CMTokB := zkpcp.Mult(PK, ub)
 
-	aProof, status := NewInequalityProof(TestCurve, A, B, CMTokA, CMTokB, a, b, sk)
+	aProof, status := NewInequalityProof(zkpcp, A, B, CMTokA, CMTokB, a, b, sk)
 
 	if status != nil {
-		proofStatus(status.(*errorProof))
+		proofStatus(status.(*ProofError))
 		t.Logf("ABCProof for InequalityProve failed to generate!\n")
 		t.Fatalf("ABCProof for InequalityProve failed\n")
 	}
 
-	check, err := aProof.Verify(TestCurve, TestCurve.Sub(A, B), TestCurve.Sub(CMTokA, CMTokB))
+	check, err := aProof.Verify(zkpcp, zkpcp.Sub(A, B), zkpcp.Sub(CMTokA, CMTokB))
 	if !check || err != nil {
 		t.Logf("ABCProof for InequalityProve failed to verify!\n")
 		t.Fatalf("ABCVerify for InequalityProve failed\n")
 	}
 
 	// Swapped positions of commitments, tokens and values, should work just fine
-	aProof, status = NewInequalityProof(TestCurve, B, A, CMTokB, CMTokA, b, a, sk)
+	aProof, status = NewInequalityProof(zkpcp, B, A, CMTokB, CMTokA, b, a, sk)
 
 	if status != nil {
-		proofStatus(status.(*errorProof))
+		proofStatus(status.(*ProofError))
 		t.Logf("ABCProof for InequalityProve failed to generate!\n")
 		t.Fatalf("ABCProof for InequalityProve failed\n")
 	}
 
-	check, err = aProof.Verify(TestCurve, TestCurve.Sub(B, A), TestCurve.Sub(CMTokB, CMTokA))
+	check, err = aProof.Verify(zkpcp, zkpcp.Sub(B, A), zkpcp.Sub(CMTokB, CMTokA))
 	if !check || err != nil {
 		t.Logf("ABCProof for InequalityProve failed to verify!\n")
 		t.Fatalf("ABCVerify for InequalityProve failed\n")
@@ -61,15 +65,15 @@ func TestInequalityProve(t *testing.T) {
 	// Mismatched commitments and values, a proof does generate but the
 	// verification step will catch the false proof.
 	// Use the -debug1 flag to see this in action
-	aProof, status = NewInequalityProof(TestCurve, A, B, CMTokA, CMTokB, b, a, sk)
+	aProof, status = NewInequalityProof(zkpcp, A, B, CMTokA, CMTokB, b, a, sk)
 
 	if status != nil {
-		proofStatus(status.(*errorProof))
+		proofStatus(status.(*ProofError))
 		t.Logf("ABCProof for InequalityProve failed to generate!\n")
 		t.Fatalf("ABCProof for InequalityProve failed\n")
 	}
 
-	check, err = aProof.Verify(TestCurve, TestCurve.Sub(A, B), TestCurve.Sub(CMTokA, CMTokB))
+	check, err = aProof.Verify(zkpcp, zkpcp.Sub(A, B), zkpcp.Sub(CMTokA, CMTokB))
 	if check || err == nil {
 		t.Logf("ABCProof for InequalityProve failed to verify!\n")
 		t.Fatalf("ABCVerify for InequalityProve failed\n")
@@ -79,7 +83,7 @@ func TestInequalityProve(t *testing.T) {
 
 func BenchmarkInequalityProve(b *testing.B) {
 
-	sk, _ := rand.Int(rand.Reader, TestCurve.C.Params().N)
+	sk, _ := rand.Int(rand.Reader, TestCurve.C.Order())
 	a, _ := rand.Int(rand.Reader, big.NewInt(10000000000))      // "realistic range"
 	bValue, _ := rand.Int(rand.Reader, big.NewInt(10000000000)) // "realistic range"
 	A, ua, err := PedCommit(TestCurve, a)
@@ -0,0 +1,529 @@
+package zksigma
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// The JSON forms of proofs and statements are flat objects.  Points are the
+// hex of their Group.Encode encoding, scalars the hex of their writeScalar
+// encoding and the challenges of compact proofs the hex of their
+// writeChallenge encoding, in the group named by the object's "group" field
+// (or, for statements, by "params").  Decoding is strict: every field must be present
+// and valid, and unknown fields are errors.
+
+// jsonWriter builds the JSON object for a proof or statement.  Like
+// proofReader it keeps the first error.
+type jsonWriter struct {
+	curve Group
+	m     map[string]interface{}
+	err   error
+}
+
+func newJSONWriter(curve Group) *jsonWriter {
+	return &jsonWriter{curve: curve, m: make(map[string]interface{})}
+}
+
+// group writes the name of w's group to the "group" field.
+func (w *jsonWriter) group() {
+	w.m["group"] = w.curve.Name()
+}
+
+func (w *jsonWriter) point(field string, p ECPoint) {
+	if w.err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	if err := writePoint(&buf, w.curve, p); err != nil {
+		w.fail(field, err)
+		return
+	}
+	w.m[field] = hex.EncodeToString(buf.Bytes())
+}
+
+func (w *jsonWriter) scalar(field string, s *big.Int) {
+	if w.err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	if err := writeScalar(&buf, w.curve, s); err != nil {
+		w.fail(field, err)
+		return
+	}
+	w.m[field] = hex.EncodeToString(buf.Bytes())
+}
+
+func (w *jsonWriter) challenge(field string, c *big.Int) {
+	var buf bytes.Buffer
+	if err := writeChallenge(&buf, c); err != nil {
+		w.fail(field, err)
+		return
+	}
+	w.m[field] = hex.EncodeToString(buf.Bytes())
+}
+
+// newStatementJSONWriter starts the JSON object of statement name with its
+// parameters.  Without a group there is nothing to encode the points in, so
+// the object fails to marshal with ErrInvalidParams.
+func newStatementJSONWriter(name string, zkpcp ZKPCurveParams) *jsonWriter {
+	w := newJSONWriter(zkpcp.C)
+	if zkpcp.C == nil {
+		w.err = &ProofError{Op: name + ".MarshalJSON", Err: ErrInvalidParams, Msg: "ZKPCurveParams has no group"}
+		return w
+	}
+	w.value("params", zkpcp)
+	return w
+}
+
+// hash writes the name of h to the "hash" field.
+func (w *jsonWriter) hash(h ChallengeHash) {
+	if !h.Valid() {
+		w.fail("hash", fmt.Errorf("unknown challenge hash %v", h))
+		return
+	}
+	w.m["hash"] = h.String()
+}
+
+// value writes v, which must marshal itself, to field.
+func (w *jsonWriter) value(field string, v interface{}) {
+	w.m[field] = v
+}
+
+// objects writes subs, which must share w's group, as a list of objects.
+func (w *jsonWriter) objects(field string, subs []*jsonWriter) {
+	list := make([]map[string]interface{}, len(subs))
+	for i, sub := range subs {
+		if sub.err != nil {
+			w.fail(fmt.Sprintf("%s[%d]", field, i), sub.err)
+		}
+		list[i] = sub.m
+	}
+	w.m[field] = list
+}
+
+func (w *jsonWriter) fail(field string, err error) {
+	if w.err == nil {
+		w.err = fmt.Errorf("%s: %v", field, err)
+	}
+}
+
+func (w *jsonWriter) marshal() ([]byte, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	return json.Marshal(w.m)
+}
+
+// jsonReader decodes an object written by a jsonWriter.
+type jsonReader struct {
+	name  string // type being decoded, for error messages
+	curve Group
+	m     map[string]json.RawMessage
+	used  map[string]bool
+	err   error
+}
+
+func newJSONReader(name string, b []byte) *jsonReader {
+	r := &jsonReader{name: name, used: make(map[string]bool)}
+	if err := json.Unmarshal(b, &r.m); err != nil {
+		r.err = &ProofError{Op: name, Err: ErrMalformedProof, Msg: err.Error()}
+	}
+	return r
+}
+
+// field returns the raw value of field, failing if it is missing.
+func (r *jsonReader) field(field string) json.RawMessage {
+	if r.err != nil {
+		return nil
+	}
+	raw, ok := r.m[field]
+	if !ok {
+		r.fail(field, fmt.Errorf("missing"))
+		return nil
+	}
+	r.used[field] = true
+	return raw
+}
+
+// bytes reads a hex string field that must decode to exactly n bytes.
+func (r *jsonReader) bytes(field string, n int) []byte {
+	raw := r.field(field)
+	if r.err != nil {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		r.fail(field, err)
+		return nil
+	}
+	b, err := hex.DecodeString(s)
+	if err == nil && len(b) != n {
+		err = fmt.Errorf("expected %d bytes, got %d", n, len(b))
+	}
+	r.fail(field, err)
+	return b
+}
+
+// group reads the "group" field and decodes later points and scalars in it.
+func (r *jsonReader) group() {
+	raw := r.field("group")
+	if r.err != nil {
+		return
+	}
+	var name string
+	err := json.Unmarshal(raw, &name)
+	if err == nil {
+		r.curve, err = GroupByName(name)
+	}
+	r.fail("group", err)
+}
+
+// params reads the "params" field into zkpcp and decodes later points and
+// scalars in its group.
+func (r *jsonReader) params(zkpcp *ZKPCurveParams) {
+	r.value("params", zkpcp)
+	r.curve = zkpcp.C
+}
+
+// hash reads the "hash" field, failing for names ParseChallengeHash does not
+// know.
+func (r *jsonReader) hash() ChallengeHash {
+	var name string
+	r.value("hash", &name)
+	if r.err != nil {
+		return HashSHAKE256
+	}
+	h, err := ParseChallengeHash(name)
+	r.fail("hash", err)
+	return h
+}
+
+func (r *jsonReader) point(field string) ECPoint {
+	if r.err != nil {
+		return ECPoint{}
+	}
+	b := r.bytes(field, r.curve.PointSize())
+	if r.err != nil {
+		return ECPoint{}
+	}
+	p, err := readPoint(bytes.NewReader(b), r.curve)
+	r.fail(field, err)
+	return p
+}
+
+func (r *jsonReader) scalar(field string) *big.Int {
+	if r.err != nil {
+		return nil
+	}
+	b := r.bytes(field, scalarSize(r.curve))
+	if r.err != nil {
+		return nil
+	}
+	s, err := readScalar(bytes.NewReader(b), r.curve)
+	r.fail(field, err)
+	return s
+}
+
+func (r *jsonReader) challenge(field string) *big.Int {
+	if r.err != nil {
+		return nil
+	}
+	b := r.bytes(field, compactChallengeSize)
+	if r.err != nil {
+		return nil
+	}
+	return new(big.Int).SetBytes(b)
+}
+
+// value unmarshals field into v.
+func (r *jsonReader) value(field string, v interface{}) {
+	raw := r.field(field)
+	if r.err != nil {
+		return
+	}
+	r.fail(field, json.Unmarshal(raw, v))
+}
+
+// objects reads a list of at most max objects and returns a reader for each,
+// all decoding in r's group.
+func (r *jsonReader) objects(field string, max int) []*jsonReader {
+	var raws []json.RawMessage
+	r.value(field, &raws)
+	if r.err == nil && len(raws) > max {
+		r.fail(field, fmt.Errorf("count %d is larger than %d", len(raws), max))
+	}
+	if r.err != nil {
+		return nil
+	}
+	subs := make([]*jsonReader, len(raws))
+	for i, raw := range raws {
+		subs[i] = newJSONReader(fmt.Sprintf("%s[%d]", field, i), raw)
+		subs[i].curve = r.curve
+	}
+	return subs
+}
+
+func (r *jsonReader) fail(field string, err error) {
+	if err == nil || r.err != nil {
+		return
+	}
+	if _, ok := err.(*ProofError); ok {
+		r.err = err
+		return
+	}
+	r.err = &ProofError{Op: r.name, Err: ErrMalformedProof, Msg: fmt.Sprintf("%s: %v", field, err)}
+}
+
+// done returns the first error, or an error if the object had fields that
+// were never read.
+func (r *jsonReader) done() error {
+	if r.err != nil {
+		return r.err
+	}
+	var unknown []string
+	for field := range r.m {
+		if !r.used[field] {
+			unknown = append(unknown, field)
+		}
+	}
+	if len(unknown) != 0 {
+		sort.Strings(unknown)
+		return &ProofError{Op: r.name, Err: ErrMalformedProof, Msg: fmt.Sprintf("unknown fields %v", unknown)}
+	}
+	return nil
+}
+
+// ============ ZKPCurveParams ==================
+
+// ECPoint has no JSON methods of its own: its coordinates alone do not say
+// which Group it is in.  Proofs, statements and ZKPCurveParams encode their
+// points in their group instead.
+
+// MarshalJSON encodes the group, the generators G and H and the name of the
+// challenge hash.  HPoints is not written since it is derived from G.
+func (zkpcp ZKPCurveParams) MarshalJSON() ([]byte, error) {
+	if zkpcp.C == nil {
+		return nil, &ProofError{Op: "ZKPCurveParams.MarshalJSON", Err: ErrInvalidParams, Msg: "no group"}
+	}
+	if !zkpcp.Hash.Valid() {
+		return nil, &ProofError{Op: "ZKPCurveParams.MarshalJSON", Err: ErrInvalidParams, Msg: "unknown challenge hash " + zkpcp.Hash.String()}
+	}
+	w := newJSONWriter(zkpcp.C)
+	w.group()
+	w.point("G", zkpcp.G)
+	w.point("H", zkpcp.H)
+	w.hash(zkpcp.Hash)
+	return w.marshal()
+}
+
+// UnmarshalJSON decodes parameters written by MarshalJSON, recomputes
+// HPoints and validates the result.
+func (zkpcp *ZKPCurveParams) UnmarshalJSON(b []byte) error {
+	r := newJSONReader("ZKPCurveParams", b)
+	r.group()
+	G := r.point("G")
+	H := r.point("H")
+	hash := r.hash()
+	if err := r.done(); err != nil {
+		return err
+	}
+
+	params := ZKPCurveParams{C: r.curve, G: G, H: H, HPoints: generateH2tothe(r.curve, G), Hash: hash}
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	*zkpcp = params
+	return nil
+}
+
+// ============ Statements ==================
+
+// MarshalJSON encodes the statement with its parameters.
+func (st GSPFSStatement) MarshalJSON() ([]byte, error) {
+	w := newStatementJSONWriter("GSPFSStatement", st.Params)
+	w.point("Base", st.Base)
+	w.point("A", st.A)
+	return w.marshal()
+}
+
+// UnmarshalJSON decodes a statement written by MarshalJSON.
+func (st *GSPFSStatement) UnmarshalJSON(b []byte) error {
+	var s GSPFSStatement
+	r := newJSONReader("GSPFSStatement", b)
+	r.params(&s.Params)
+	s.Base = r.point("Base")
+	s.A = r.point("A")
+	if err := r.done(); err != nil {
+		return err
+	}
+	*st = s
+	return nil
+}
+
+// MarshalJSON encodes the statement with its parameters.
+func (st EquivalenceStatement) MarshalJSON() ([]byte, error) {
+	w := newStatementJSONWriter("EquivalenceStatement", st.Params)
+	w.point("Base1", st.Base1)
+	w.point("Result1", st.Result1)
+	w.point("Base2", st.Base2)
+	w.point("Result2", st.Result2)
+	return w.marshal()
+}
+
+// UnmarshalJSON decodes a statement written by MarshalJSON.
+func (st *EquivalenceStatement) UnmarshalJSON(b []byte) error {
+	var s EquivalenceStatement
+	r := newJSONReader("EquivalenceStatement", b)
+	r.params(&s.Params)
+	s.Base1 = r.point("Base1")
+	s.Result1 = r.point("Result1")
+	s.Base2 = r.point("Base2")
+	s.Result2 = r.point("Result2")
+	if err := r.done(); err != nil {
+		return err
+	}
+	*st = s
+	return nil
+}
+
+// MarshalJSON encodes the statement with its parameters.
+func (st DisjunctiveStatement) MarshalJSON() ([]byte, error) {
+	return EquivalenceStatement(st).MarshalJSON()
+}
+
+// UnmarshalJSON decodes a statement written by MarshalJSON.
+func (st *DisjunctiveStatement) UnmarshalJSON(b []byte) error {
+	return (*EquivalenceStatement)(st).UnmarshalJSON(b)
+}
+
+// MarshalJSON encodes the statement with its parameters.
+func (st ConsistencyStatement) MarshalJSON() ([]byte, error) {
+	w := newStatementJSONWriter("ConsistencyStatement", st.Params)
+	w.point("CM", st.CM)
+	w.point("CMTok", st.CMTok)
+	w.point("PubKey", st.PubKey)
+	return w.marshal()
+}
+
+// UnmarshalJSON decodes a statement written by MarshalJSON.
+func (st *ConsistencyStatement) UnmarshalJSON(b []byte) error {
+	var s ConsistencyStatement
+	r := newJSONReader("ConsistencyStatement", b)
+	r.params(&s.Params)
+	s.CM = r.point("CM")
+	s.CMTok = r.point("CMTok")
+	s.PubKey = r.point("PubKey")
+	if err := r.done(); err != nil {
+		return err
+	}
+	*st = s
+	return nil
+}
+
+// MarshalJSON encodes the statement with its parameters.
+func (st ABCStatement) MarshalJSON() ([]byte, error) {
+	w := newStatementJSONWriter("ABCStatement", st.Params)
+	w.point("CM", st.CM)
+	w.point("CMTok", st.CMTok)
+	return w.marshal()
+}
+
+// UnmarshalJSON decodes a statement written by MarshalJSON.
+func (st *ABCStatement) UnmarshalJSON(b []byte) error {
+	var s ABCStatement
+	r := newJSONReader("ABCStatement", b)
+	r.params(&s.Params)
+	s.CM = r.point("CM")
+	s.CMTok = r.point("CMTok")
+	if err := r.done(); err != nil {
+		return err
+	}
+	*st = s
+	return nil
+}
+
+// MarshalJSON encodes the statement with its parameters.
+func (st InequalityStatement) MarshalJSON() ([]byte, error) {
+	return ABCStatement(st).MarshalJSON()
+}
+
+// UnmarshalJSON decodes a statement written by MarshalJSON.
+func (st *InequalityStatement) UnmarshalJSON(b []byte) error {
+	return (*ABCStatement)(st).UnmarshalJSON(b)
+}
+
+// MarshalJSON encodes the statement with its parameters.
+func (st RangeStatement) MarshalJSON() ([]byte, error) {
+	w := newStatementJSONWriter("RangeStatement", st.Params)
+	w.point("Comm", st.Comm)
+	return w.marshal()
+}
+
+// UnmarshalJSON decodes a statement written by MarshalJSON.
+func (st *RangeStatement) UnmarshalJSON(b []byte) error {
+	var s RangeStatement
+	r := newJSONReader("RangeStatement", b)
+	r.params(&s.Params)
+	s.Comm = r.point("Comm")
+	if err := r.done(); err != nil {
+		return err
+	}
+	*st = s
+	return nil
+}
+
+// MarshalJSON encodes the statement with its parameters.
+func (st CompactGSPFSStatement) MarshalJSON() ([]byte, error) {
+	w := newStatementJSONWriter("CompactGSPFSStatement", st.Params)
+	w.point("Base", st.Base)
+	w.point("A", st.A)
+	return w.marshal()
+}
+
+// UnmarshalJSON decodes a statement written by MarshalJSON.
+func (st *CompactGSPFSStatement) UnmarshalJSON(b []byte) error {
+	var s CompactGSPFSStatement
+	r := newJSONReader("CompactGSPFSStatement", b)
+	r.params(&s.Params)
+	s.Base = r.point("Base")
+	s.A = r.point("A")
+	if err := r.done(); err != nil {
+		return err
+	}
+	*st = s
+	return nil
+}
+
+// MarshalJSON encodes the statement with its parameters.
+func (st CompactEquivalenceStatement) MarshalJSON() ([]byte, error) {
+	return EquivalenceStatement(st).MarshalJSON()
+}
+
+// UnmarshalJSON decodes a statement written by MarshalJSON.
+func (st *CompactEquivalenceStatement) UnmarshalJSON(b []byte) error {
+	return (*EquivalenceStatement)(st).UnmarshalJSON(b)
+}
+
+// MarshalJSON encodes the statement with its parameters.
+func (st CompactDisjunctiveStatement) MarshalJSON() ([]byte, error) {
+	return EquivalenceStatement(st).MarshalJSON()
+}
+
+// UnmarshalJSON decodes a statement written by MarshalJSON.
+func (st *CompactDisjunctiveStatement) UnmarshalJSON(b []byte) error {
+	return (*EquivalenceStatement)(st).UnmarshalJSON(b)
+}
+
+// MarshalJSON encodes the statement with its parameters.
+func (st CompactConsistencyStatement) MarshalJSON() ([]byte, error) {
+	return ConsistencyStatement(st).MarshalJSON()
+}
+
+// UnmarshalJSON decodes a statement written by MarshalJSON.
+func (st *CompactConsistencyStatement) UnmarshalJSON(b []byte) error {
+	return (*ConsistencyStatement)(st).UnmarshalJSON(b)
+}
@@ -0,0 +1,187 @@
+package zksigma
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	forEachCurve(t, testJSON)
+}
+
+func testJSON(t *testing.T, zkpcp ZKPCurveParams) {
+	for _, ps := range sampleProofs(t, zkpcp) {
+		b, err := json.Marshal(ps.proof)
+		if err != nil {
+			t.Fatalf("%v: %v\n", ps.proof.Type(), err)
+		}
+		proof := reflect.New(reflect.TypeOf(ps.proof).Elem()).Interface().(Proof)
+		if err := json.Unmarshal(b, proof); err != nil {
+			t.Fatalf("%v: %v\n%s\n", ps.proof.Type(), err, b)
+		}
+
+		b, err = json.Marshal(ps.st)
+		if err != nil {
+			t.Fatalf("%v statement: %v\n", ps.st.Type(), err)
+		}
+		st := reflect.New(reflect.TypeOf(ps.st))
+		if err := json.Unmarshal(b, st.Interface()); err != nil {
+			t.Fatalf("%v statement: %v\n%s\n", ps.st.Type(), err, b)
+		}
+
+		ok, err := proof.VerifyStatement(st.Elem().Interface().(Statement))
+		if !ok || err != nil {
+			t.Fatalf("%v: decoded proof should verify: %v\n", proof.Type(), err)
+		}
+	}
+}
+
+func TestStatementJSONWithoutParams(t *testing.T) {
+	for _, st := range []Statement{
+		GSPFSStatement{A: TestCurve.G},
+		DisjunctiveStatement{Base1: TestCurve.G},
+		RangeStatement{},
+		CompactConsistencyStatement{CM: TestCurve.H},
+	} {
+		if _, err := json.Marshal(st); !errors.Is(err, ErrInvalidParams) {
+			t.Fatalf("%T without params: expected %v, got %v\n", st, ErrInvalidParams, err)
+		}
+	}
+	if _, err := json.Marshal(ZKPCurveParams{}); !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("ZKPCurveParams without a group: expected %v, got %v\n", ErrInvalidParams, err)
+	}
+}
+
+func TestCurveParamsJSON(t *testing.T) {
+	forEachCurve(t, testCurveParamsJSON)
+}
+
+func testCurveParamsJSON(t *testing.T, zkpcp ZKPCurveParams) {
+	for _, h := range challengeHashes {
+		b, err := json.Marshal(zkpcp.WithHash(h))
+		if err != nil {
+			t.Fatalf("%v: %v\n", h, err)
+		}
+		var params ZKPCurveParams
+		if err := json.Unmarshal(b, &params); err != nil {
+			t.Fatalf("%v: %v\n%s\n", h, err, b)
+		}
+		if params.C != zkpcp.C || !params.G.Equal(zkpcp.G) || !params.H.Equal(zkpcp.H) || params.Hash != h {
+			t.Fatalf("%v: params did not round trip: %s\n", h, b)
+		}
+	}
+
+	// HPoints follow G, so params whose G is not the group's generator must
+	// round trip too.
+	other := zkpcp
+	other.G = zkpcp.C.Add(zkpcp.G, zkpcp.G)
+	other.HPoints = generateH2tothe(other.C, other.G)
+	b, err := json.Marshal(other)
+	if err != nil {
+		t.Fatalf("G = 2 * generator: %v\n", err)
+	}
+	var params ZKPCurveParams
+	if err := json.Unmarshal(b, &params); err != nil {
+		t.Fatalf("G = 2 * generator: %v\n%s\n", err, b)
+	}
+	if !params.G.Equal(other.G) || !params.HPoints[63].Equal(other.HPoints[63]) {
+		t.Fatalf("G = 2 * generator: params did not round trip: %s\n", b)
+	}
+
+	b, _ = json.Marshal(zkpcp)
+	var fields map[string]interface{}
+	json.Unmarshal(b, &fields)
+	for name, hash := range map[string]interface{}{"unknown hash": "MD5", "hash number": 1} {
+		fields["hash"] = hash
+		b, _ := json.Marshal(fields)
+		var params ZKPCurveParams
+		if err := json.Unmarshal(b, &params); !errors.Is(err, ErrMalformedProof) {
+			t.Fatalf("%s: expected %v, got %v\n", name, ErrMalformedProof, err)
+		}
+	}
+	delete(fields, "hash")
+	b, _ = json.Marshal(fields)
+	params = ZKPCurveParams{}
+	if err := json.Unmarshal(b, &params); err == nil {
+		t.Fatalf("params without a hash should fail to decode\n")
+	}
+
+	if _, err := json.Marshal(zkpcp.WithHash(0xff)); !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("unknown hash: expected %v, got %v\n", ErrInvalidParams, err)
+	}
+}
+
+func TestECPointJSON(t *testing.T) {
+	forEachCurve(t, testECPointJSON)
+}
+
+// testECPointJSON checks that points of every group, the identity and the nil
+// ECPoint survive the default JSON encoding of their coordinates.
+func testECPointJSON(t *testing.T, zkpcp ZKPCurveParams) {
+	for _, p := range []ECPoint{zkpcp.Mult(zkpcp.G, big.NewInt(3)), zkpcp.H, Zero} {
+		b, err := json.Marshal(p)
+		if err != nil {
+			t.Fatalf("%v: %v\n", p, err)
+		}
+		var q ECPoint
+		if err := json.Unmarshal(b, &q); err != nil {
+			t.Fatalf("%s: %v\n", b, err)
+		}
+		if !q.Equal(p) || !zkpcp.C.Contains(q) {
+			t.Fatalf("decoded %v, encoded %v\n", q, p)
+		}
+	}
+
+	b, err := json.Marshal(ECPoint{})
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	var q ECPoint
+	if err := json.Unmarshal(b, &q); err != nil || !q.isNil() {
+		t.Fatalf("the nil point decoded to %v, %v\n", q, err)
+	}
+}
+
+func TestProofJSONStrict(t *testing.T) {
+	x := big.NewInt(7)
+	proof, _ := NewGSPFSProof(TestCurve, TestCurve.Mult(TestCurve.G, x), x)
+	b, _ := json.Marshal(proof)
+
+	var fields map[string]interface{}
+	json.Unmarshal(b, &fields)
+	edit := func(f func(m map[string]interface{})) []byte {
+		m := make(map[string]interface{})
+		for k, v := range fields {
+			m[k] = v
+		}
+		f(m)
+		b, _ := json.Marshal(m)
+		return b
+	}
+
+	N := new(big.Int).Set(TestCurve.C.Order())
+	unreduced := make([]byte, 32)
+	N.FillBytes(unreduced)
+
+	bad := map[string][]byte{
+		"unknown field":    edit(func(m map[string]interface{}) { m["extra"] = "00" }),
+		"missing field":    edit(func(m map[string]interface{}) { delete(m, "Challenge") }),
+		"bad hex":          edit(func(m map[string]interface{}) { m["Base"] = "xyz" }),
+		"short point":      edit(func(m map[string]interface{}) { m["Base"] = "02" }),
+		"identity point":   edit(func(m map[string]interface{}) { m["Base"] = strings.Repeat("00", 33) }),
+		"unreduced scalar": edit(func(m map[string]interface{}) { m["Challenge"] = new(big.Int).SetBytes(unreduced).Text(16) }),
+		"unknown group":    edit(func(m map[string]interface{}) { m["group"] = "P-521" }),
+		"wrong group":      edit(func(m map[string]interface{}) { m["group"] = "ristretto255" }),
+		"not an object":    []byte(`[]`),
+	}
+	for name, b := range bad {
+		var p GSPFSProof
+		if err := json.Unmarshal(b, &p); err == nil {
+			t.Fatalf("%s: should not decode\n", name)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package zksigma
+
+import (
+	"context"
+	"encoding/hex"
+	"log/slog"
+)
+
+// tracing reports whether zkpcp.Logger wants trace events.
+func (zkpcp ZKPCurveParams) tracing() bool {
+	return zkpcp.Logger != nil && zkpcp.Logger.Enabled(context.Background(), slog.LevelDebug)
+}
+
+// trace logs a step of making or verifying a proof of type t at debug level.
+// Only public values may be passed in args, never witnesses or nonces.
+func (zkpcp ZKPCurveParams) trace(t ProofType, msg string, args ...any) {
+	if !zkpcp.tracing() {
+		return
+	}
+	zkpcp.Logger.Debug(msg, append([]any{"proof", t.String(), "group", zkpcp.groupName()}, args...)...)
+}
+
+// traceError logs err at debug level and returns it.
+func (zkpcp ZKPCurveParams) traceError(err *ProofError) *ProofError {
+	if zkpcp.tracing() {
+		zkpcp.Logger.Debug("proof failed", "proof", err.Type.String(), "group", zkpcp.groupName(), "err", err)
+	}
+	return err
+}
+
+// groupName returns the name of zkpcp.C, which may be nil.
+func (zkpcp ZKPCurveParams) groupName() string {
+	if zkpcp.C == nil {
+		return "none"
+	}
+	return zkpcp.C.Name()
+}
+
+// logPoint returns p as a log value that is only encoded if it is logged.
+func (zkpcp ZKPCurveParams) logPoint(p ECPoint) slog.LogValuer {
+	return tracedPoint{zkpcp.C, p}
+}
+
+type tracedPoint struct {
+	curve Group
+	p     ECPoint
+}
+
+func (tp tracedPoint) LogValue() slog.Value {
+	if tp.p.isNil() || !tp.curve.Contains(tp.p) {
+		return slog.StringValue("invalid")
+	}
+	return slog.StringValue(hex.EncodeToString(tp.curve.Encode(tp.p)))
+}
@@ -0,0 +1,50 @@
+package zksigma
+
+import (
+	"bytes"
+	"log/slog"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	zkpcp := TestCurve
+	zkpcp.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	for _, ps := range sampleProofs(t, zkpcp) {
+		if ok, err := ps.proof.VerifyStatement(ps.st); !ok || err != nil {
+			t.Fatalf("%v: %v\n", ps.proof.Type(), err)
+		}
+	}
+	for _, want := range []string{
+		"msg=\"prover commitment\" proof=GSPFSProof",
+		"msg=\"verifier challenge\" proof=RangeProof",
+		"msg=verified proof=ABCProof",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("log should contain %q:\n%s", want, buf.String())
+		}
+	}
+
+	x := big.NewInt(9)
+	proof, err := NewGSPFSProof(zkpcp, zkpcp.Mult(zkpcp.G, x), x)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	buf.Reset()
+	proof.Verify(zkpcp, zkpcp.G)
+	if !strings.Contains(buf.String(), "msg=\"proof failed\" proof=GSPFSProof") {
+		t.Fatalf("failed verification should be logged:\n%s", buf.String())
+	}
+
+	// Nothing is logged above debug level.
+	buf.Reset()
+	zkpcp.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+	NewGSPFSProof(zkpcp, zkpcp.Mult(zkpcp.G, x), x)
+	proof.Verify(zkpcp, zkpcp.G)
+	if buf.Len() != 0 {
+		t.Fatalf("expected no output at info level:\n%s", buf.String())
+	}
+}
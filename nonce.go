@@ -0,0 +1,115 @@
+package zksigma
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+	"math/big"
+
+	"github.com/mit-dci/zksigma/btcec"
+)
+
+// nonceEntropySize is the number of fresh random bytes mixed into the seed of
+// deterministic nonces.
+const nonceEntropySize = 32
+
+// nonceSource hands out the nonces of a single proof.  They are read from
+// zkpcp.Rand unless zkpcp.DeterministicNonces is set, in which case they come
+// from an RFC 6979 HMAC-DRBG, see newNonceSource.  Provers defer destroy to
+// wipe every nonce once the proof is made, so a nonce that ends up in a proof
+// must be copied.
+type nonceSource struct {
+	zkpcp  ZKPCurveParams
+	drbg   *btcec.HMACDRBG
+	drawn  bool
+	nonces []*big.Int
+}
+
+// newNonceSource returns the nonce source of a proof of type t for the secret
+// witness secrets and the public points of the statement.  With
+// DeterministicNonces the DRBG is seeded with
+//
+//	int2octets(secrets[0]) || ... || SHA-256(t || group || G || H || public) || entropy
+//
+// where entropy is read from zkpcp.Rand as in section 3.6 of RFC 6979.  A
+// weak or repeating RNG then cannot make two proofs for different witnesses
+// or statements share nonces, and a working RNG still keeps nonces
+// unpredictable if the witness leaks.
+func (zkpcp ZKPCurveParams) newNonceSource(t ProofType, secrets []*big.Int, public ...ECPoint) (*nonceSource, error) {
+	ns := &nonceSource{zkpcp: zkpcp}
+	if !zkpcp.DeterministicNonces {
+		return ns, nil
+	}
+
+	rolen := (zkpcp.C.Order().BitLen() + 7) / 8
+	var seed []byte
+	for _, s := range secrets {
+		seed = append(seed, zkpcp.C.ReduceScalar(s).FillBytes(make([]byte, rolen))...)
+	}
+
+	h := sha256.New()
+	h.Write([]byte{byte(t)})
+	h.Write([]byte(zkpcp.C.Name()))
+	for _, p := range append([]ECPoint{zkpcp.G, zkpcp.H}, public...) {
+		if p.isNil() {
+			h.Write([]byte{0})
+			continue
+		}
+		h.Write(zkpcp.Bytes(p))
+	}
+	seed = h.Sum(seed)
+
+	r := zkpcp.Rand
+	if r == nil {
+		r = rand.Reader
+	}
+	entropy := make([]byte, nonceEntropySize)
+	if _, err := io.ReadFull(r, entropy); err != nil {
+		return nil, err
+	}
+	seed = append(seed, entropy...)
+
+	ns.drbg = btcec.NewHMACDRBG(sha256.New, seed)
+	wipeBytes(seed)
+	return ns, nil
+}
+
+// scalar returns the next nonce, a scalar in [1, N) when deterministic.
+func (ns *nonceSource) scalar() (*big.Int, error) {
+	if ns.drbg == nil {
+		k, err := ns.zkpcp.randomScalar()
+		if err == nil {
+			ns.nonces = append(ns.nonces, k)
+		}
+		return k, err
+	}
+
+	N := ns.zkpcp.C.Order()
+	qlen := N.BitLen()
+	for {
+		if ns.drawn {
+			ns.drbg.Update()
+		}
+		ns.drawn = true
+
+		// bits2int: keep the leftmost qlen bits of T
+		T := ns.drbg.Generate(qlen)
+		k := new(big.Int).SetBytes(T)
+		k.Rsh(k, uint(len(T)*8-qlen))
+		wipeBytes(T)
+		if k.Sign() > 0 && k.Cmp(N) < 0 {
+			ns.nonces = append(ns.nonces, k)
+			return k, nil
+		}
+		WipeScalars(k)
+	}
+}
+
+// destroy wipes every nonce handed out and the state of the DRBG.
+func (ns *nonceSource) destroy() {
+	WipeScalars(ns.nonces...)
+	ns.nonces = nil
+	if ns.drbg != nil {
+		ns.drbg.Destroy()
+	}
+}
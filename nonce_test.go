@@ -0,0 +1,82 @@
+package zksigma
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func TestDeterministicNonces(t *testing.T) {
+	forEachCurve(t, testDeterministicNonces)
+}
+
+func testDeterministicNonces(t *testing.T, zkpcp ZKPCurveParams) {
+	zkpcp.DeterministicNonces = true
+
+	first := sampleProofs(t, zkpcp.WithRand(&hashReader{seed: []byte("nonces")}))
+	second := sampleProofs(t, zkpcp.WithRand(&hashReader{seed: []byte("nonces")}))
+	for i := range first {
+		if !bytes.Equal(first[i].proof.Bytes(), second[i].proof.Bytes()) {
+			t.Fatalf("%v: same witness, statement and entropy should give the same proof\n", first[i].proof.Type())
+		}
+		ok, err := first[i].proof.VerifyStatement(first[i].st)
+		if !ok || err != nil {
+			t.Fatalf("%v: proof with deterministic nonces should verify: %v\n", first[i].proof.Type(), err)
+		}
+	}
+
+	// With an RNG stuck at zero, the nonce still depends on the witness and
+	// on the statement.
+	zkpcp = zkpcp.WithRand(zeroReader{})
+	nonce := func(x *big.Int, public ...ECPoint) *big.Int {
+		ns, err := zkpcp.newNonceSource(GSPFSProofType, []*big.Int{x}, public...)
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+		k, err := ns.scalar()
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+		k2, err := ns.scalar()
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+		if k.Cmp(k2) == 0 {
+			t.Fatalf("consecutive nonces should differ\n")
+		}
+		return k
+	}
+	x1, x2 := big.NewInt(5), big.NewInt(6)
+	X1 := zkpcp.Mult(zkpcp.G, x1)
+	if nonce(x1, zkpcp.G, X1).Cmp(nonce(x1, zkpcp.G, X1)) != 0 {
+		t.Fatalf("same inputs should give the same nonce\n")
+	}
+	if nonce(x1, zkpcp.G, X1).Cmp(nonce(x2, zkpcp.G, X1)) == 0 {
+		t.Fatalf("different witnesses should not share a nonce\n")
+	}
+	if nonce(x1, zkpcp.G, X1).Cmp(nonce(x1, zkpcp.H, zkpcp.Mult(zkpcp.H, x1))) == 0 {
+		t.Fatalf("different statements should not share a nonce\n")
+	}
+
+	// Fresh entropy still changes the nonce of an identical proof.
+	p1, err := NewGSPFSProof(zkpcp, X1, x1)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	p2, err := NewGSPFSProof(zkpcp.WithRand(&hashReader{seed: []byte("other")}), X1, x1)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	if p1.RandCommit.Equal(p2.RandCommit) {
+		t.Fatalf("different entropy should give a different nonce\n")
+	}
+}
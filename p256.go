@@ -0,0 +1,225 @@
+package zksigma
+
+import (
+	"crypto/elliptic"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"sync"
+)
+
+// p256Group implements Group over NIST P-256 using crypto/elliptic.  Like the
+// secp256k1 backend it keeps a fast path for a secondary generator H, here
+// backed by a fixed-base table built the first time H is multiplied.
+type p256Group struct {
+	scalarField
+	curve elliptic.Curve
+	g     ECPoint
+	h     ECPoint // HashToPoint(G.X + 2), the same rule btcec uses for its H
+
+	hTableOnce sync.Once
+	hTable     [64][16]ECPoint // hTable[i][j] = j * 16^i * H
+}
+
+var (
+	p256Once     sync.Once
+	p256Instance *p256Group
+)
+
+// P256 returns the NIST P-256 Group.
+func P256() Group {
+	p256Once.Do(func() {
+		curve := elliptic.P256()
+		g := &p256Group{
+			scalarField: scalarField{curve.Params().N},
+			curve:       curve,
+			g:           ECPoint{curve.Params().Gx, curve.Params().Gy},
+		}
+		g.h = g.HashToPoint(new(big.Int).Add(g.g.X, big.NewInt(2)).Bytes())
+		p256Instance = g
+	})
+	return p256Instance
+}
+
+func (g *p256Group) Name() string {
+	return "P-256"
+}
+
+func (g *p256Group) Generator() ECPoint {
+	return g.g
+}
+
+func (g *p256Group) Identity() ECPoint {
+	return Zero
+}
+
+func (g *p256Group) IsIdentity(p ECPoint) bool {
+	return p.X.Sign() == 0 && p.Y.Sign() == 0
+}
+
+func (g *p256Group) Contains(p ECPoint) bool {
+	if p.X == nil || p.Y == nil {
+		return false
+	}
+	if g.IsIdentity(p) {
+		return true
+	}
+	P := g.curve.Params().P
+	if p.X.Sign() < 0 || p.X.Cmp(P) >= 0 || p.Y.Sign() < 0 || p.Y.Cmp(P) >= 0 {
+		return false
+	}
+	return g.curve.IsOnCurve(p.X, p.Y)
+}
+
+// Add returns p + q, or the nil ECPoint{} if p or q is not on the curve:
+// crypto/elliptic panics on such points.
+func (g *p256Group) Add(p, q ECPoint) ECPoint {
+	if !g.Contains(p) || !g.Contains(q) {
+		return ECPoint{}
+	}
+	X, Y := g.curve.Add(p.X, p.Y, q.X, q.Y)
+	return ECPoint{X, Y}
+}
+
+// Neg returns -p, or the nil ECPoint{} if p is not on the curve.
+func (g *p256Group) Neg(p ECPoint) ECPoint {
+	if !g.Contains(p) {
+		return ECPoint{}
+	}
+	if g.IsIdentity(p) {
+		return Zero
+	}
+	negY := new(big.Int).Neg(p.Y)
+	return ECPoint{p.X, negY.Mod(negY, g.curve.Params().P)}
+}
+
+// ScalarMult returns k * p, or the nil ECPoint{} if p is not on the curve.
+func (g *p256Group) ScalarMult(p ECPoint, k *big.Int) ECPoint {
+	if !g.Contains(p) {
+		return ECPoint{}
+	}
+	modK := g.ReduceScalar(k)
+	switch {
+	case g.IsIdentity(p) || modK.Sign() == 0:
+		return Zero
+	case p.Equal(g.g):
+		X, Y := g.curve.ScalarBaseMult(modK.Bytes())
+		return ECPoint{X, Y}
+	case p.Equal(g.h):
+		return g.scalarMultH(modK)
+	}
+	X, Y := g.curve.ScalarMult(p.X, p.Y, modK.Bytes())
+	return ECPoint{X, Y}
+}
+
+// SecretScalarMult computes k * p with crypto/elliptic, whose P-256 scalar
+// multiplication is constant time, skipping the table for H, whose lookups
+// and additions depend on k.  Points not on the curve give the nil ECPoint{}.
+func (g *p256Group) SecretScalarMult(p ECPoint, k *big.Int) ECPoint {
+	if !g.Contains(p) {
+		return ECPoint{}
+	}
+	if g.IsIdentity(p) {
+		return Zero
+	}
+	modK := g.ReduceScalar(k)
+	var kb [32]byte
+	modK.FillBytes(kb[:])
+	var X, Y *big.Int
+	if p.Equal(g.g) {
+		X, Y = g.curve.ScalarBaseMult(kb[:])
+	} else {
+		X, Y = g.curve.ScalarMult(p.X, p.Y, kb[:])
+	}
+	WipeScalars(modK)
+	wipeBytes(kb[:])
+	return ECPoint{X, Y}
+}
+
+func (g *p256Group) ScalarBaseMult(k *big.Int) ECPoint {
+	X, Y := g.curve.ScalarBaseMult(g.ReduceScalar(k).Bytes())
+	return ECPoint{X, Y}
+}
+
+// scalarMultH computes k * H from the precomputed table, one addition per
+// 4-bit window of k and no doublings.
+func (g *p256Group) scalarMultH(k *big.Int) ECPoint {
+	g.hTableOnce.Do(func() {
+		base := g.h
+		for i := range g.hTable {
+			g.hTable[i][0] = Zero
+			for j := 1; j < 16; j++ {
+				g.hTable[i][j] = g.Add(g.hTable[i][j-1], base)
+			}
+			base = g.Add(g.hTable[i][15], base)
+		}
+	})
+
+	var kb [32]byte
+	k.FillBytes(kb[:])
+	R := Zero
+	for i := 0; i < 64; i++ {
+		b := kb[31-i/2]
+		if i%2 == 1 {
+			b >>= 4
+		}
+		if w := b & 0x0f; w != 0 {
+			R = g.Add(R, g.hTable[i][w])
+		}
+	}
+	return R
+}
+
+func (g *p256Group) PointSize() int {
+	return 33
+}
+
+// Encode returns the 33-byte compressed SEC1 encoding of p, with the identity
+// padded out to 33 zero bytes like the secp256k1 backend does.
+func (g *p256Group) Encode(p ECPoint) []byte {
+	if g.IsIdentity(p) {
+		return make([]byte, 33)
+	}
+	return elliptic.MarshalCompressed(g.curve, p.X, p.Y)
+}
+
+func (g *p256Group) Decode(b []byte) (ECPoint, error) {
+	if len(b) != 33 {
+		return Zero, errors.New("P-256: invalid point encoding")
+	}
+	if isZeroBytes(b) {
+		return Zero, nil
+	}
+	// UnmarshalCompressed rejects x >= P and x with no matching y.
+	X, Y := elliptic.UnmarshalCompressed(g.curve, b)
+	if X == nil {
+		return Zero, errors.New("P-256: point is not on the curve")
+	}
+	return ECPoint{X, Y}, nil
+}
+
+// HashToPoint hashes msg with SHA-256 and uses the digest as the x coordinate
+// of a point with even y, rehashing the digest until it lands on the curve.
+func (g *p256Group) HashToPoint(msg []byte) ECPoint {
+	params := g.curve.Params()
+	three := big.NewInt(3)
+
+	digest := sha256.Sum256(msg)
+	for {
+		x := new(big.Int).SetBytes(digest[:])
+		if x.Cmp(params.P) < 0 {
+			// y^2 = x^3 - 3x + b
+			y2 := new(big.Int).Exp(x, three, params.P)
+			y2.Sub(y2, new(big.Int).Mul(three, x))
+			y2.Add(y2, params.B)
+			y2.Mod(y2, params.P)
+			if y := new(big.Int).ModSqrt(y2, params.P); y != nil {
+				if y.Bit(0) == 1 {
+					y.Sub(params.P, y)
+				}
+				return ECPoint{x, y}
+			}
+		}
+		digest = sha256.Sum256(digest[:])
+	}
+}
@@ -0,0 +1,299 @@
+package zksigma
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ProofType identifies the kind of a proof inside an envelope.
+type ProofType byte
+
+// The proof types built into zksigma.  Values are part of the envelope format
+// and must never be reused.
+const (
+	GSPFSProofType       ProofType = 1
+	EquivalenceProofType ProofType = 2
+	DisjunctiveProofType ProofType = 3
+	ConsistencyProofType ProofType = 4
+	ABCProofType         ProofType = 5
+	InequalityProofType  ProofType = 6
+	RangeProofType       ProofType = 7
+
+	CompactGSPFSProofType       ProofType = 8
+	CompactEquivalenceProofType ProofType = 9
+	CompactDisjunctiveProofType ProofType = 10
+	CompactConsistencyProofType ProofType = 11
+)
+
+// Proof is implemented by every proof in zksigma so that proofs of different
+// types can be stored, decoded and verified together.
+type Proof interface {
+	// Type returns the tag written in front of the proof by EncodeProof.
+	Type() ProofType
+	// Bytes returns the serialized proof, without an envelope.
+	Bytes() []byte
+	// VerifyStatement checks the proof against the public statement s, which
+	// must be the statement type that goes with the proof, e.g. a
+	// GSPFSStatement for a GSPFSProof.
+	VerifyStatement(s Statement) (bool, error)
+	// DiagnoseStatement runs every check VerifyStatement would and reports
+	// the outcome of each instead of stopping at the first failure.
+	DiagnoseStatement(s Statement) (*VerificationReport, error)
+
+	// Proofs serialize to the same bytes whether they are marshaled whole or
+	// streamed, and ReadFrom never reads past the end of the proof.
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+	io.WriterTo
+	io.ReaderFrom
+}
+
+var (
+	_ Proof = (*GSPFSProof)(nil)
+	_ Proof = (*EquivalenceProof)(nil)
+	_ Proof = (*DisjunctiveProof)(nil)
+	_ Proof = (*ConsistencyProof)(nil)
+	_ Proof = (*ABCProof)(nil)
+	_ Proof = (*InequalityProof)(nil)
+	_ Proof = (*RangeProof)(nil)
+
+	_ Proof = (*CompactGSPFSProof)(nil)
+	_ Proof = (*CompactEquivalenceProof)(nil)
+	_ Proof = (*CompactDisjunctiveProof)(nil)
+	_ Proof = (*CompactConsistencyProof)(nil)
+)
+
+// Statement is the public information a Proof is verified against: the curve
+// parameters and the points the prover makes a claim about.
+type Statement interface {
+	// Type returns the type of the proofs that prove this statement.
+	Type() ProofType
+}
+
+// GSPFSStatement is the statement of a GSPFSProof: the prover knows x with
+// A = x * Base.  The proof carries Base too, and must agree.
+type GSPFSStatement struct {
+	Params  ZKPCurveParams
+	Base, A ECPoint
+}
+
+// EquivalenceStatement is the statement of an EquivalenceProof: the prover
+// knows x with Result1 = x * Base1 and Result2 = x * Base2.
+type EquivalenceStatement struct {
+	Params                         ZKPCurveParams
+	Base1, Result1, Base2, Result2 ECPoint
+}
+
+// DisjunctiveStatement is the statement of a DisjunctiveProof: the prover
+// knows x with Result1 = x * Base1 or Result2 = x * Base2.
+type DisjunctiveStatement struct {
+	Params                         ZKPCurveParams
+	Base1, Result1, Base2, Result2 ECPoint
+}
+
+// ConsistencyStatement is the statement of a ConsistencyProof: CM and CMTok
+// were made with the same randomness, CMTok under PubKey.
+type ConsistencyStatement struct {
+	Params            ZKPCurveParams
+	CM, CMTok, PubKey ECPoint
+}
+
+// ABCStatement is the statement of an ABCProof about commitment CM and its
+// token CMTok.
+type ABCStatement struct {
+	Params    ZKPCurveParams
+	CM, CMTok ECPoint
+}
+
+// InequalityStatement is the statement of an InequalityProof, with CM = A - B
+// and CMTok = CMTokA - CMTokB.
+type InequalityStatement struct {
+	Params    ZKPCurveParams
+	CM, CMTok ECPoint
+}
+
+// NewInequalityStatement returns the statement of an InequalityProof made by
+// NewInequalityProof(zkpcp, A, B, CMTokA, CMTokB, ...).
+func NewInequalityStatement(zkpcp ZKPCurveParams, A, B, CMTokA, CMTokB ECPoint) InequalityStatement {
+	return InequalityStatement{zkpcp, zkpcp.Sub(A, B), zkpcp.Sub(CMTokA, CMTokB)}
+}
+
+// RangeStatement is the statement of a RangeProof: Comm commits to a value in
+// range.
+type RangeStatement struct {
+	Params ZKPCurveParams
+	Comm   ECPoint
+}
+
+// CompactGSPFSStatement is the statement of a CompactGSPFSProof: the prover
+// knows x with A = x * Base.  Unlike a GSPFSProof, the proof does not carry
+// Base.
+type CompactGSPFSStatement struct {
+	Params  ZKPCurveParams
+	Base, A ECPoint
+}
+
+// CompactEquivalenceStatement is the statement of a CompactEquivalenceProof,
+// the same as an EquivalenceStatement.
+type CompactEquivalenceStatement struct {
+	Params                         ZKPCurveParams
+	Base1, Result1, Base2, Result2 ECPoint
+}
+
+// CompactDisjunctiveStatement is the statement of a CompactDisjunctiveProof,
+// the same as a DisjunctiveStatement.
+type CompactDisjunctiveStatement struct {
+	Params                         ZKPCurveParams
+	Base1, Result1, Base2, Result2 ECPoint
+}
+
+// CompactConsistencyStatement is the statement of a CompactConsistencyProof,
+// the same as a ConsistencyStatement.
+type CompactConsistencyStatement struct {
+	Params            ZKPCurveParams
+	CM, CMTok, PubKey ECPoint
+}
+
+func (GSPFSStatement) Type() ProofType       { return GSPFSProofType }
+func (EquivalenceStatement) Type() ProofType { return EquivalenceProofType }
+func (DisjunctiveStatement) Type() ProofType { return DisjunctiveProofType }
+func (ConsistencyStatement) Type() ProofType { return ConsistencyProofType }
+func (ABCStatement) Type() ProofType         { return ABCProofType }
+func (InequalityStatement) Type() ProofType  { return InequalityProofType }
+func (RangeStatement) Type() ProofType       { return RangeProofType }
+
+func (CompactGSPFSStatement) Type() ProofType       { return CompactGSPFSProofType }
+func (CompactEquivalenceStatement) Type() ProofType { return CompactEquivalenceProofType }
+func (CompactDisjunctiveStatement) Type() ProofType { return CompactDisjunctiveProofType }
+func (CompactConsistencyStatement) Type() ProofType { return CompactConsistencyProofType }
+
+// wrongStatement returns the error VerifyStatement gives for a statement of
+// the wrong type.
+func wrongStatement(t ProofType, s Statement) error {
+	return &ProofError{Type: t, Op: t.String() + ".VerifyStatement", Err: ErrWrongStatement, Msg: fmt.Sprintf("cannot verify against a %T", s)}
+}
+
+// ============ REGISTRY ==================
+
+type proofTypeInfo struct {
+	name     string
+	newProof func() Proof
+}
+
+var (
+	proofTypesMu sync.RWMutex
+	proofTypes   = make(map[ProofType]proofTypeInfo)
+)
+
+// RegisterProofType makes DecodeProof and ReadProof decode proofs tagged t
+// into values returned by newProof, which must return a fresh proof each
+// call.  name is what t.String() returns.  The built-in proofs are registered
+// by default.
+func RegisterProofType(t ProofType, name string, newProof func() Proof) {
+	proofTypesMu.Lock()
+	defer proofTypesMu.Unlock()
+	proofTypes[t] = proofTypeInfo{name, newProof}
+}
+
+func (t ProofType) String() string {
+	proofTypesMu.RLock()
+	defer proofTypesMu.RUnlock()
+	if info, ok := proofTypes[t]; ok {
+		return info.name
+	}
+	return fmt.Sprintf("ProofType(%d)", byte(t))
+}
+
+func init() {
+	RegisterProofType(GSPFSProofType, "GSPFSProof", func() Proof { return new(GSPFSProof) })
+	RegisterProofType(EquivalenceProofType, "EquivalenceProof", func() Proof { return new(EquivalenceProof) })
+	RegisterProofType(DisjunctiveProofType, "DisjunctiveProof", func() Proof { return new(DisjunctiveProof) })
+	RegisterProofType(ConsistencyProofType, "ConsistencyProof", func() Proof { return new(ConsistencyProof) })
+	RegisterProofType(ABCProofType, "ABCProof", func() Proof { return new(ABCProof) })
+	RegisterProofType(InequalityProofType, "InequalityProof", func() Proof { return new(InequalityProof) })
+	RegisterProofType(RangeProofType, "RangeProof", func() Proof { return new(RangeProof) })
+	RegisterProofType(CompactGSPFSProofType, "CompactGSPFSProof", func() Proof { return new(CompactGSPFSProof) })
+	RegisterProofType(CompactEquivalenceProofType, "CompactEquivalenceProof", func() Proof { return new(CompactEquivalenceProof) })
+	RegisterProofType(CompactDisjunctiveProofType, "CompactDisjunctiveProof", func() Proof { return new(CompactDisjunctiveProof) })
+	RegisterProofType(CompactConsistencyProofType, "CompactConsistencyProof", func() Proof { return new(CompactConsistencyProof) })
+}
+
+// ============ ENVELOPE ==================
+
+// envelopeVersion is the first byte of every envelope.
+const envelopeVersion = 1
+
+// EncodeProof wraps p in an envelope that records its type, so that
+// DecodeProof can read it back without being told what it is:
+//
+//	[envelope version][proof type][p.Bytes()]
+func EncodeProof(p Proof) []byte {
+	return append([]byte{envelopeVersion, byte(p.Type())}, p.Bytes()...)
+}
+
+// DecodeProof decodes a proof written by EncodeProof.
+func DecodeProof(b []byte) (Proof, error) {
+	if len(b) < 2 {
+		return nil, &ProofError{Op: "DecodeProof", Err: ErrMalformedProof, Msg: "truncated envelope"}
+	}
+	p, err := newEnvelopeProof("DecodeProof", b[0], b[1])
+	if err != nil {
+		return nil, err
+	}
+	if err := p.UnmarshalBinary(b[2:]); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// WriteProof writes p to w in the format of EncodeProof without building the
+// encoding in memory first.  It returns the number of bytes written.
+func WriteProof(w io.Writer, p Proof) (int64, error) {
+	n, err := w.Write([]byte{envelopeVersion, byte(p.Type())})
+	if err != nil {
+		return int64(n), err
+	}
+	m, err := p.WriteTo(w)
+	return int64(n) + m, err
+}
+
+// ReadProof reads one proof written by WriteProof or EncodeProof from r.  It
+// reads no further than the end of the proof, so a stream of proofs can be
+// read by calling ReadProof until it returns io.EOF.
+func ReadProof(r io.Reader) (Proof, error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, &ProofError{Op: "ReadProof", Err: ErrMalformedProof, Msg: "truncated envelope"}
+		}
+		return nil, err
+	}
+	p, err := newEnvelopeProof("ReadProof", hdr[0], hdr[1])
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.ReadFrom(r); err != nil {
+		if err == io.EOF {
+			return nil, &ProofError{Op: "ReadProof", Err: ErrMalformedProof, Msg: "truncated input"}
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// newEnvelopeProof checks an envelope header and returns an empty proof of
+// the type it names.
+func newEnvelopeProof(fn string, version, t byte) (Proof, error) {
+	if version != envelopeVersion {
+		return nil, &ProofError{Op: fn, Err: ErrMalformedProof, Msg: fmt.Sprintf("unsupported envelope version %d", version)}
+	}
+	proofTypesMu.RLock()
+	info, ok := proofTypes[ProofType(t)]
+	proofTypesMu.RUnlock()
+	if !ok {
+		return nil, &ProofError{Type: ProofType(t), Op: fn, Err: ErrUnknownProofType, Msg: fmt.Sprintf("unknown proof type %d", t)}
+	}
+	return info.newProof(), nil
+}
@@ -0,0 +1,303 @@
+package zksigma
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+)
+
+type provenStatement struct {
+	proof Proof
+	st    Statement
+}
+
+// sampleProofs returns one valid proof of every type built into zksigma,
+// together with the statement it proves.
+func sampleProofs(t *testing.T, zkpcp ZKPCurveParams) []provenStatement {
+	x := big.NewInt(100)
+	y := big.NewInt(101)
+	rng := zkpcp.Rand
+	if rng == nil {
+		rng = rand.Reader
+	}
+	PK, sk, err := GenerateKey(zkpcp.C, zkpcp.H, rng)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	X := zkpcp.Mult(zkpcp.G, x)
+	XH := zkpcp.Mult(zkpcp.H, x)
+	YH := zkpcp.Mult(zkpcp.H, y)
+
+	CM, u, err := PedCommit(zkpcp, x)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	CMTok := zkpcp.Mult(PK, u)
+	CM2, u2, err := PedCommit(zkpcp, y)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	CMTok2 := zkpcp.Mult(PK, u2)
+
+	gspfs, err := NewGSPFSProof(zkpcp, X, x)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	eq, err := NewEquivalenceProof(zkpcp, zkpcp.G, X, zkpcp.H, XH, x)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	dj, err := NewDisjunctiveProof(zkpcp, zkpcp.G, X, zkpcp.H, YH, x, Left)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	con, err := NewConsistencyProof(zkpcp, CM, CMTok, PK, x, u)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	abc, err := NewABCProof(zkpcp, CM, CMTok, x, sk, Right)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	ie, err := NewInequalityProof(zkpcp, CM, CM2, CMTok, CMTok2, x, y, sk)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	rp, r, err := NewRangeProof(zkpcp, x)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	cgspfs, err := NewCompactGSPFSProofBase(zkpcp, zkpcp.H, XH, x)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	ceq, err := NewCompactEquivalenceProof(zkpcp, zkpcp.G, X, zkpcp.H, XH, x)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	cdj, err := NewCompactDisjunctiveProof(zkpcp, zkpcp.H, YH, zkpcp.G, X, x, Right)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	ccon, err := NewCompactConsistencyProof(zkpcp, CM, CMTok, PK, x, u)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	return []provenStatement{
+		{gspfs, GSPFSStatement{zkpcp, zkpcp.G, X}},
+		{eq, EquivalenceStatement{zkpcp, zkpcp.G, X, zkpcp.H, XH}},
+		{dj, DisjunctiveStatement{zkpcp, zkpcp.G, X, zkpcp.H, YH}},
+		{con, ConsistencyStatement{zkpcp, CM, CMTok, PK}},
+		{abc, ABCStatement{zkpcp, CM, CMTok}},
+		{ie, NewInequalityStatement(zkpcp, CM, CM2, CMTok, CMTok2)},
+		{rp, RangeStatement{zkpcp, PedCommitR(zkpcp, x, r)}},
+		{cgspfs, CompactGSPFSStatement{zkpcp, zkpcp.H, XH}},
+		{ceq, CompactEquivalenceStatement{zkpcp, zkpcp.G, X, zkpcp.H, XH}},
+		{cdj, CompactDisjunctiveStatement{zkpcp, zkpcp.H, YH, zkpcp.G, X}},
+		{ccon, CompactConsistencyStatement{zkpcp, CM, CMTok, PK}},
+	}
+}
+
+func TestProofEnvelope(t *testing.T) {
+	forEachCurve(t, testProofEnvelope)
+}
+
+func testProofEnvelope(t *testing.T, zkpcp ZKPCurveParams) {
+	for _, ps := range sampleProofs(t, zkpcp) {
+		if ps.proof.Type() != ps.st.Type() {
+			t.Fatalf("%v proven with a %v statement\n", ps.proof.Type(), ps.st.Type())
+		}
+
+		p, err := DecodeProof(EncodeProof(ps.proof))
+		if err != nil {
+			t.Fatalf("%v: %v\n", ps.proof.Type(), err)
+		}
+		if p.Type() != ps.proof.Type() {
+			t.Fatalf("decoded a %v, encoded a %v\n", p.Type(), ps.proof.Type())
+		}
+		ok, err := p.VerifyStatement(ps.st)
+		if !ok || err != nil {
+			t.Fatalf("%v: decoded proof should verify: %v\n", p.Type(), err)
+		}
+
+		// Every proof refuses a statement meant for another type.
+		if _, err := p.VerifyStatement(RangeStatement{zkpcp, Zero}); p.Type() != RangeProofType && err == nil {
+			t.Fatalf("%v: should not verify against a RangeStatement\n", p.Type())
+		}
+	}
+}
+
+func TestDecodeProofErrors(t *testing.T) {
+	x := big.NewInt(7)
+	proof, _ := NewGSPFSProof(TestCurve, TestCurve.Mult(TestCurve.G, x), x)
+	b := EncodeProof(proof)
+
+	if _, err := DecodeProof(b[:1]); err == nil {
+		t.Fatalf("truncated envelope should not decode\n")
+	}
+
+	bad := append([]byte(nil), b...)
+	bad[0] = envelopeVersion + 1
+	if _, err := DecodeProof(bad); err == nil {
+		t.Fatalf("unknown envelope version should not decode\n")
+	}
+
+	bad = append([]byte(nil), b...)
+	bad[1] = 0xff
+	if _, err := DecodeProof(bad); err == nil {
+		t.Fatalf("unknown proof type should not decode\n")
+	}
+
+	// The hash follows the body's format version and group name.
+	bad = append([]byte(nil), b...)
+	bad[2+1+1+len(TestCurve.C.Name())] = 0xff
+	if _, err := DecodeProof(bad); !errors.Is(err, ErrMalformedProof) {
+		t.Fatalf("unknown challenge hash should not decode: %v\n", err)
+	}
+
+	bad = append([]byte(nil), b...)
+	bad[1] = byte(DisjunctiveProofType)
+	if _, err := DecodeProof(bad); err == nil {
+		t.Fatalf("GSPFS proof tagged as a disjunctive proof should not decode\n")
+	}
+
+	if ProofType(0xff).String() != "ProofType(255)" || GSPFSProofType.String() != "GSPFSProof" {
+		t.Fatalf("unexpected ProofType names\n")
+	}
+}
+
+func TestProofStreaming(t *testing.T) {
+	forEachCurve(t, testProofStreaming)
+}
+
+func testProofStreaming(t *testing.T, zkpcp ZKPCurveParams) {
+	proofs := sampleProofs(t, zkpcp)
+
+	var buf bytes.Buffer
+	for _, ps := range proofs {
+		n, err := WriteProof(&buf, ps.proof)
+		if err != nil {
+			t.Fatalf("%v: %v\n", ps.proof.Type(), err)
+		}
+		if n != int64(len(EncodeProof(ps.proof))) {
+			t.Fatalf("%v: WriteProof wrote %d bytes, EncodeProof gives %d\n", ps.proof.Type(), n, len(EncodeProof(ps.proof)))
+		}
+
+		b, err := ps.proof.MarshalBinary()
+		if err != nil || !bytes.Equal(b, ps.proof.Bytes()) {
+			t.Fatalf("%v: MarshalBinary should match Bytes: %v\n", ps.proof.Type(), err)
+		}
+	}
+
+	for _, ps := range proofs {
+		p, err := ReadProof(&buf)
+		if err != nil {
+			t.Fatalf("%v: %v\n", ps.proof.Type(), err)
+		}
+		if p.Type() != ps.proof.Type() {
+			t.Fatalf("read a %v, wrote a %v\n", p.Type(), ps.proof.Type())
+		}
+		ok, err := p.VerifyStatement(ps.st)
+		if !ok || err != nil {
+			t.Fatalf("%v: streamed proof should verify: %v\n", p.Type(), err)
+		}
+	}
+	if _, err := ReadProof(&buf); err != io.EOF {
+		t.Fatalf("reading past the last proof should give io.EOF, got %v\n", err)
+	}
+
+	// A proof cut short in the middle of the stream is an error, not io.EOF.
+	b := EncodeProof(proofs[0].proof)
+	if _, err := ReadProof(bytes.NewReader(b[:len(b)-1])); err == nil || err == io.EOF {
+		t.Fatalf("truncated proof should give an error, got %v\n", err)
+	}
+}
+
+// hashReader is a deterministic stream of SHA-256(seed || counter) blocks.
+type hashReader struct {
+	seed []byte
+	ctr  uint64
+	buf  []byte
+}
+
+func (r *hashReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(r.buf) == 0 {
+			var ctr [8]byte
+			binary.BigEndian.PutUint64(ctr[:], r.ctr)
+			r.ctr++
+			h := sha256.Sum256(append(append([]byte(nil), r.seed...), ctr[:]...))
+			r.buf = h[:]
+		}
+		m := copy(p[n:], r.buf)
+		r.buf = r.buf[m:]
+		n += m
+	}
+	return n, nil
+}
+
+func TestProverRand(t *testing.T) {
+	forEachCurve(t, testProverRand)
+}
+
+func testProverRand(t *testing.T, zkpcp ZKPCurveParams) {
+	first := sampleProofs(t, zkpcp.WithRand(&hashReader{seed: []byte("prover rand")}))
+	second := sampleProofs(t, zkpcp.WithRand(&hashReader{seed: []byte("prover rand")}))
+	for i := range first {
+		if !bytes.Equal(first[i].proof.Bytes(), second[i].proof.Bytes()) {
+			t.Fatalf("%v: same Rand should give the same proof\n", first[i].proof.Type())
+		}
+		ok, err := first[i].proof.VerifyStatement(first[i].st)
+		if !ok || err != nil {
+			t.Fatalf("%v: proof from Rand should verify: %v\n", first[i].proof.Type(), err)
+		}
+	}
+}
+
+type failingReader struct{}
+
+var errRand = errors.New("rand failed")
+
+func (failingReader) Read([]byte) (int, error) { return 0, errRand }
+
+func TestProverRandError(t *testing.T) {
+	zkpcp := TestCurve
+	x := big.NewInt(3)
+	X := zkpcp.Mult(zkpcp.G, x)
+	PK, sk := KeyGen(zkpcp.C, zkpcp.H)
+	CM, u, err := PedCommit(zkpcp, x)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	CMTok := zkpcp.Mult(PK, u)
+
+	zkpcp = zkpcp.WithRand(failingReader{})
+	for name, prove := range map[string]func() error{
+		"GenerateKey": func() error { _, _, err := GenerateKey(zkpcp.C, zkpcp.H, zkpcp.Rand); return err },
+		"PedCommit":   func() error { _, _, err := PedCommit(zkpcp, x); return err },
+		"GSPFS":       func() error { _, err := NewGSPFSProof(zkpcp, X, x); return err },
+		"Equivalence": func() error {
+			_, err := NewEquivalenceProof(zkpcp, zkpcp.G, X, zkpcp.G, X, x)
+			return err
+		},
+		"Disjunctive": func() error {
+			_, err := NewDisjunctiveProof(zkpcp, zkpcp.G, X, zkpcp.H, zkpcp.H, x, Left)
+			return err
+		},
+		"Consistency": func() error { _, err := NewConsistencyProof(zkpcp, CM, CMTok, PK, x, u); return err },
+		"ABC":         func() error { _, err := NewABCProof(zkpcp, CM, CMTok, x, sk, Right); return err },
+		"Range":       func() error { _, _, err := NewRangeProof(zkpcp, x); return err },
+	} {
+		if err := prove(); err != errRand {
+			t.Fatalf("%s: expected the Rand error, got %v\n", name, err)
+		}
+	}
+}
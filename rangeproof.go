@@ -1,14 +1,12 @@
 package zksigma
 
 import (
-	"bytes"
-	"crypto/rand"
-	"crypto/sha256"
+	"context"
 	"fmt"
+	"io"
 	"math/big"
+	"runtime"
 	"sync"
-
-	"github.com/mit-dci/zksigma/wire"
 )
 
 // The following was copy-pasted from zkLedger's original implementation by Willy (github.com/wrv)
@@ -37,6 +35,9 @@ type RangeProof struct {
 	ProofAggregate ECPoint
 	ProofE         *big.Int
 	ProofTuples    []rangeProofTuple
+
+	curve Group         // group the proof was computed in
+	hash  ChallengeHash // hash the challenges were computed with
 }
 
 type proverInternalData struct {
@@ -44,114 +45,142 @@ type proverInternalData struct {
 	Bpoints  []ECPoint
 	kScalars []*big.Int
 	vScalars []*big.Int
+	jScalars []*big.Int // random j of proofGenB for 0 bits
 }
 
-// proofGenA takes in a waitgroup, index and bit
-// returns an Rpoint and Cpoint, and the k value bigint
-func proofGenA(zkpcp ZKPCurveParams,
-	wg *sync.WaitGroup, idx int, bit bool, s *proverInternalData) error {
+// forEachIndex calls fn(0), ..., fn(n-1) from at most workers goroutines, or
+// GOMAXPROCS if workers is not positive, and returns the first error fn
+// returns.  It stops handing out indices as soon as fn fails or ctx is done,
+// and returns ctx.Err() in the latter case.
+func forEachIndex(ctx context.Context, workers, n int, fn func(i int) error) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	indices := make(chan int)
+	errs := make(chan error, workers) // each worker sends at most once
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				if err := fn(i); err != nil {
+					errs <- err
+					cancel()
+					return
+				}
+			}
+		}()
+	}
 
-	defer wg.Done()
 	var err error
+	for i := 0; i < n && err == nil; i++ {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		select {
+		case indices <- i:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	close(indices)
+	wg.Wait()
 
+	select {
+	case fnErr := <-errs:
+		return fnErr
+	default:
+		return err
+	}
+}
+
+// proofGenA takes in an index and bit
+// sets the Rpoint and Bpoint of that index
+func proofGenA(zkpcp ZKPCurveParams, idx int, bit bool, s *proverInternalData) error {
 	//	R := s.Rpoints[idx]
 	//	B := s.Bpoints[idx]
 	//	k := stuff.kScalars[index]
 	//	v := stuff.vScalars[index]
 
 	if !bit { // If bit is 0, just make a random R = k*H
-		s.kScalars[idx], err = rand.Int(rand.Reader, zkpcp.C.Params().N) // random k
-		if err != nil {
-			return err
-		}
-		s.Rpoints[idx] = zkpcp.Mult(zkpcp.H, s.kScalars[idx]) // R is k * H
+		s.Rpoints[idx] = zkpcp.MultSecret(zkpcp.H, s.kScalars[idx]) // R is k * H
 	} else { // if bit is 1, actually do stuff
 
-		// get a random ri
-		s.vScalars[idx], err = rand.Int(rand.Reader, zkpcp.C.Params().N)
-		if err != nil {
-			return err
-		}
 		// get R as H*ri... what is KC..?
-		s.Rpoints[idx] = zkpcp.Mult(zkpcp.H, s.vScalars[idx])
+		s.Rpoints[idx] = zkpcp.MultSecret(zkpcp.H, s.vScalars[idx])
 
 		// B is htothe[index] plus partial R
-		s.Bpoints[idx].X, s.Bpoints[idx].Y =
-			zkpcp.C.Add(zkpcp.HPoints[idx].X, zkpcp.HPoints[idx].Y,
-				s.Rpoints[idx].X, s.Rpoints[idx].Y)
-
-			// random k
-		s.kScalars[idx], err = rand.Int(rand.Reader, zkpcp.C.Params().N)
-		if err != nil {
-			return err
-		}
+		s.Bpoints[idx] = zkpcp.Add(zkpcp.HPoints[idx], s.Rpoints[idx])
 
 		// make k*H for hashing
-		temp := zkpcp.Mult(zkpcp.H, s.kScalars[idx])
+		temp := zkpcp.MultSecret(zkpcp.H, s.kScalars[idx])
 
 		// Hash of temp point (why the whole thing..?
-		hash := sha256.Sum256(append(temp.X.Bytes(), temp.Y.Bytes()...))
-		ei := new(big.Int).SetBytes(hash[:])
-		ei.Mod(ei, zkpcp.C.Params().N)
-		s.Rpoints[idx].X, s.Rpoints[idx].Y =
-			zkpcp.C.ScalarMult(s.Bpoints[idx].X, s.Bpoints[idx].Y, ei.Bytes())
+		ei := pointsChallenge(zkpcp, temp)
+		s.Rpoints[idx] = zkpcp.MultSecret(s.Bpoints[idx], ei)
 	}
 	//	fmt.Printf("loop %d\n", idx)
 
 	return nil
 }
 
-// proofGenB takes waitgroup, index, bit, along with the data to operate on
-func proofGenB(zkpcp ZKPCurveParams,
-	wg *sync.WaitGroup, idx int, bit bool, e0 *big.Int, data *proverInternalData) error {
-
-	defer wg.Done()
-
+// proofGenB takes index, bit, along with the data to operate on
+func proofGenB(zkpcp ZKPCurveParams, idx int, bit bool, e0 *big.Int, data *proverInternalData) error {
 	if !bit {
-		// choose a random value from the integers mod prime
-		j, err := rand.Int(rand.Reader, zkpcp.C.Params().N)
-		if err != nil {
-			return err
-		}
+		// a random value from the integers mod prime
+		j := data.jScalars[idx]
 
-		m2 := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(idx)), zkpcp.C.Params().N)
-		//		m2 := big.NewInt(1 << uint(idx))
-		em2 := new(big.Int).Mul(e0, m2)
-		em2.Mod(em2, zkpcp.C.Params().N)
+		m2 := new(big.Int).Lsh(big.NewInt(1), uint(idx))
+		em2 := zkpcp.C.MulScalars(e0, m2)
 
-		rhsX, rhsY := zkpcp.C.ScalarBaseMult(em2.Bytes())
+		rhs := zkpcp.C.ScalarBaseMult(em2)
 
-		lhs := zkpcp.Mult(zkpcp.H, j)
+		lhs := zkpcp.MultSecret(zkpcp.H, j)
 
-		totX, totY := zkpcp.C.Add(lhs.X, lhs.Y, rhsX, rhsY)
+		tot := zkpcp.Add(lhs, rhs)
 
-		hash := sha256.Sum256(append(totX.Bytes(), totY.Bytes()...))
-		ei := new(big.Int).SetBytes(hash[:]) // get ei
-		ei.Mod(ei, zkpcp.C.Params().N)
+		ei := pointsChallenge(zkpcp, tot) // get ei
 
-		inverseEI := new(big.Int).ModInverse(ei, zkpcp.C.Params().N)
+		inverseEI := zkpcp.C.InvertScalar(ei)
 
-		data.vScalars[idx] = new(big.Int).Mul(inverseEI, data.kScalars[idx])
+		data.vScalars[idx] = zkpcp.C.MulScalars(inverseEI, data.kScalars[idx])
 
 		// set the C point for this index to R* inv ei
 		data.Bpoints[idx] = zkpcp.Mult(data.Rpoints[idx], inverseEI)
 
 		// s = k + (kValues[i] * e0) * inverse ei
-		data.kScalars[idx] = j.Add(
-			j, new(big.Int).Mul(data.kScalars[idx], new(big.Int).Mul(e0, inverseEI)))
+		data.kScalars[idx] = zkpcp.C.AddScalars(
+			j, zkpcp.C.MulScalars(data.kScalars[idx], zkpcp.C.MulScalars(e0, inverseEI)))
 
 	} else { // bit is 1, don't do anything
 		// s is k + e0*v
 
-		data.kScalars[idx] = new(big.Int).Add(
-			data.kScalars[idx], new(big.Int).Mul(e0, data.vScalars[idx]))
+		data.kScalars[idx] = zkpcp.C.AddScalars(
+			data.kScalars[idx], zkpcp.C.MulScalars(e0, data.vScalars[idx]))
 	}
 
 	return nil
 }
 
-// NewRangeProof generates a range proof for the given value
+// NewRangeProof generates a range proof for the given value, see
+// NewRangeProofContext
 func NewRangeProof(zkpcp ZKPCurveParams, value *big.Int) (*RangeProof, *big.Int, error) {
+	return NewRangeProofContext(context.Background(), zkpcp, value)
+}
+
+// NewRangeProofContext generates a range proof for the given value and returns
+// it with the randomness of the commitment it is a proof for.  The work is
+// spread over at most GOMAXPROCS goroutines; if ctx is done before the proof
+// is finished, the ProofError returned wraps ctx.Err().
+func NewRangeProofContext(ctx context.Context, zkpcp ZKPCurveParams, value *big.Int) (*RangeProof, *big.Int, error) {
 	proof := RangeProof{}
 
 	// extend or truncate our value to 64 bits, which is the range we are proving
@@ -159,14 +188,14 @@ func NewRangeProof(zkpcp ZKPCurveParams, value *big.Int) (*RangeProof, *big.Int,
 	// else, because of truncation, it will be deemed out of range not be equal
 
 	if value.Cmp(big.NewInt(1099511627776)) == 1 {
-		return nil, nil, fmt.Errorf("val %s too big, can only prove up to 1099511627776\n", value.String())
+		return nil, nil, zkpcp.traceError(&ProofError{Type: RangeProofType, Op: "NewRangeProof", Err: ErrInvalidWitness, Msg: fmt.Sprintf("val %s too big, can only prove up to 1099511627776", value.String())})
 	}
 
 	proofSize := 40
 	// check to see if our value is out of range
 	if proofSize > 40 || value.Cmp(BigZero) == -1 {
 		//if so, then we can't play
-		return nil, nil, fmt.Errorf("** Trying to get a value that is out of range! Range Proof will not work!\n")
+		return nil, nil, zkpcp.traceError(&ProofError{Type: RangeProofType, Op: "NewRangeProof", Err: ErrInvalidWitness, Msg: "value is negative, Range Proof will not work"})
 	}
 
 	stuff := new(proverInternalData)
@@ -175,42 +204,64 @@ func NewRangeProof(zkpcp ZKPCurveParams, value *big.Int) (*RangeProof, *big.Int,
 	stuff.Rpoints = make([]ECPoint, proofSize)
 	stuff.Bpoints = make([]ECPoint, proofSize)
 	stuff.vScalars = make([]*big.Int, proofSize)
+	stuff.jScalars = make([]*big.Int, proofSize)
+
+	nonces, err := zkpcp.newNonceSource(RangeProofType, []*big.Int{value})
+	if err != nil {
+		return nil, nil, err
+	}
+	// wipes k, v and j; the v of 0 bits is computed by proofGenB
+	defer nonces.destroy()
+	defer func() { WipeScalars(stuff.vScalars...) }()
+
+	// Draw all the randomness here, in bit order, so that zkpcp.Rand is never
+	// read from more than one goroutine and the same Rand gives the same proof.
+	for i := 0; i < proofSize; i++ {
+		if value.Bit(i) == 1 {
+			// a random ri, then a random k
+			if stuff.vScalars[i], err = nonces.scalar(); err != nil {
+				return nil, nil, err
+			}
+			if stuff.kScalars[i], err = nonces.scalar(); err != nil {
+				return nil, nil, err
+			}
+		} else {
+			// a random k, then a random j for proofGenB
+			if stuff.kScalars[i], err = nonces.scalar(); err != nil {
+				return nil, nil, err
+			}
+			if stuff.jScalars[i], err = nonces.scalar(); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
 
 	vTotal := big.NewInt(0)
 	proof.ProofTuples = make([]rangeProofTuple, proofSize)
 
 	//	 do the loop bValue times
-	var wg sync.WaitGroup
-	wg.Add(proofSize)
-	for i := 0; i < proofSize; i++ {
-		// TODO: Check errors
-		go proofGenA(zkpcp, &wg, i, value.Bit(i) == 1, stuff)
+	err = forEachIndex(ctx, 0, proofSize, func(i int) error {
+		return proofGenA(zkpcp, i, value.Bit(i) == 1, stuff)
+	})
+	if err != nil {
+		return nil, nil, zkpcp.traceError(&ProofError{Type: RangeProofType, Op: "NewRangeProof", Err: err})
 	}
-	wg.Wait()
 
 	// hash concat of all R values
-	rHash := sha256.New()
-	for _, rvalue := range stuff.Rpoints {
-		rHash.Write(rvalue.X.Bytes())
-		rHash.Write(rvalue.Y.Bytes())
-	}
-	hashed := rHash.Sum(nil)
-
-	e0 := new(big.Int).SetBytes(hashed[:])
-	e0.Mod(e0, zkpcp.C.Params().N)
+	e0 := pointsChallenge(zkpcp, stuff.Rpoints...)
+	zkpcp.trace(RangeProofType, "prover commitment", "bits", proofSize, "challenge", e0)
 
 	var AggregatePoint ECPoint
 	AggregatePoint.X = new(big.Int)
 	AggregatePoint.Y = new(big.Int)
 
 	// go through all 64 part B
-	wg.Add(proofSize)
-	for i := 0; i < proofSize; i++ {
-		// TODO: Check errors
-		go proofGenB(zkpcp,
-			&wg, i, value.Bit(i) == 1, e0, stuff)
+	err = forEachIndex(ctx, 0, proofSize, func(i int) error {
+		return proofGenB(zkpcp, i, value.Bit(i) == 1, e0, stuff)
+	})
+	if err != nil {
+		return nil, nil, zkpcp.traceError(&ProofError{Type: RangeProofType, Op: "NewRangeProof", Err: err})
 	}
-	wg.Wait()
 
 	for i := 0; i < proofSize; i++ {
 		//		add up to get vTotal scalar
@@ -226,18 +277,14 @@ func NewRangeProof(zkpcp ZKPCurveParams, value *big.Int) (*RangeProof, *big.Int,
 
 	proof.ProofE = e0
 	proof.ProofAggregate = AggregatePoint
+	proof.curve = zkpcp.C
+	proof.hash = zkpcp.Hash
 
 	return &proof, vTotal, nil
 }
 
-type verifyTuple struct {
-	index  int
-	Rpoint ECPoint
-}
-
-// give it a proof tuple, proofE.  Get back an Rpoint, and a Cpoint
-func verifyGen(zkpcp ZKPCurveParams,
-	idx int, proofE *big.Int, rpt rangeProofTuple, retbox chan verifyTuple) {
+// give it a proof tuple, proofE.  Get back an Rpoint
+func verifyGen(zkpcp ZKPCurveParams, idx int, proofE *big.Int, rpt rangeProofTuple) ECPoint {
 
 	lhs := zkpcp.Mult(zkpcp.H, rpt.S)
 
@@ -248,20 +295,39 @@ func verifyGen(zkpcp ZKPCurveParams,
 	//s_i * G - e_0 * (C_i - 2^i * H)
 	tot := zkpcp.Add(lhs, rhsXYNeg)
 
-	hash := sha256.Sum256(append(tot.X.Bytes(), tot.Y.Bytes()...))
-
-	e1 := new(big.Int).SetBytes(hash[:])
+	e1 := pointsChallenge(zkpcp, tot)
 
-	var result verifyTuple
-	result.index = idx
-	result.Rpoint = zkpcp.Mult(rpt.C, e1)
-
-	retbox <- result
+	return zkpcp.Mult(rpt.C, e1)
 }
 
+// Verify checks that proof shows comm commits to a value in range, see
+// VerifyContext
 func (proof *RangeProof) Verify(zkpcp ZKPCurveParams, comm ECPoint) (bool, error) {
+	return proof.VerifyContext(context.Background(), zkpcp, comm)
+}
+
+// VerifyContext checks that proof shows comm commits to a value in range.  Like
+// NewRangeProofContext it uses at most GOMAXPROCS goroutines and gives up with
+// an error wrapping ctx.Err() once ctx is done.
+func (proof *RangeProof) VerifyContext(ctx context.Context, zkpcp ZKPCurveParams, comm ECPoint) (bool, error) {
 	if proof == nil {
-		return false, &errorProof{"RangeProof.Verify", fmt.Sprintf("passed proof is nil")}
+		return false, zkpcp.traceError(&ProofError{Type: RangeProofType, Op: "RangeProof.Verify", Err: ErrMalformedProof, Msg: "passed proof is nil"})
+	}
+
+	v := zkpcp.validateInputs(RangeProofType, "RangeProof.Verify")
+	v.hash(proof.hash)
+	v.point("commitment", comm)
+	v.point("ProofAggregate", proof.ProofAggregate)
+	v.scalar("ProofE", proof.ProofE)
+	for i, t := range proof.ProofTuples {
+		v.point(fmt.Sprintf("ProofTuples[%d].C", i), t.C)
+		v.scalar(fmt.Sprintf("ProofTuples[%d].S", i), t.S)
+	}
+	if err := v.done(); err != nil {
+		return false, err
+	}
+	if len(proof.ProofTuples) == 0 || len(proof.ProofTuples) > len(zkpcp.HPoints) {
+		return false, zkpcp.traceError(&ProofError{Type: RangeProofType, Op: "RangeProof.Verify", Err: ErrMalformedProof, Msg: fmt.Sprintf("%d entries, expected 1 to %d", len(proof.ProofTuples), len(zkpcp.HPoints))})
 	}
 
 	proofs := proof.ProofTuples
@@ -272,85 +338,202 @@ func (proof *RangeProof) Verify(zkpcp ZKPCurveParams, comm ECPoint) (bool, error
 
 	totalPoint := ECPoint{big.NewInt(0), big.NewInt(0)}
 
-	resultBox := make(chan verifyTuple, 10) // doubt we'll use even 1
-
-	for i := 0; i < proofLength; i++ {
-		// check that proofs are non-nil
-		if proof.ProofTuples[i].C.X == nil {
-			return false, &errorProof{"RangeProof.Verify", fmt.Sprintf("entry %d has nil point", i)}
-		}
-		if proof.ProofTuples[i].S == nil {
-			return false, &errorProof{"RangeProof.Verify", fmt.Sprintf("entry %d has nil scalar", i)}
-
-		}
-
-		// give proof to the verify gorouting
-		go verifyGen(zkpcp, i, proof.ProofE, proof.ProofTuples[i], resultBox)
-	}
-
-	for i := 0; i < proofLength; i++ {
-		result := <-resultBox
-
+	err := forEachIndex(ctx, 0, proofLength, func(i int) error {
 		// only reason we do this is for the hash of the point.
 		// could do something commutative here too?
-		Rpoints[result.index] = result.Rpoint
+		Rpoints[i] = verifyGen(zkpcp, i, proof.ProofE, proof.ProofTuples[i])
+		return nil
+	})
+	if err != nil {
+		return false, zkpcp.traceError(&ProofError{Type: RangeProofType, Op: "RangeProof.Verify", Err: err})
+	}
 
+	for i := 0; i < proofLength; i++ {
 		// add to totalpoint here (commutative)
 		totalPoint = zkpcp.Add(totalPoint, proof.ProofTuples[i].C)
 	}
 
-	rHash := sha256.New()
-	for _, rpoint := range Rpoints {
-		rHash.Write(rpoint.X.Bytes())
-		rHash.Write(rpoint.Y.Bytes())
-	}
-	calculatedE0 := rHash.Sum(nil)
+	calculatedE0 := pointsChallenge(zkpcp, Rpoints...)
 
-	if proof.ProofE.Cmp(new(big.Int).SetBytes(calculatedE0[:])) != 0 {
-		return false, &errorProof{"RangeProof.Verify", fmt.Sprintf("calculatedE0 does not match")}
+	if proof.ProofE.Cmp(calculatedE0) != 0 {
+		return false, zkpcp.traceError(&ProofError{Type: RangeProofType, Op: "RangeProof.Verify", Err: ErrChallengeMismatch, Msg: "calculatedE0 does not match"})
 	}
+	zkpcp.trace(RangeProofType, "verifier challenge", "bits", proofLength, "challenge", proof.ProofE)
 
 	if !totalPoint.Equal(proof.ProofAggregate) {
-		return false, &errorProof{"RangeProof.Verify", fmt.Sprintf("ProofAggregate does not match totalPoint")}
+		return false, zkpcp.traceError(&ProofError{Type: RangeProofType, Op: "RangeProof.Verify", Err: ErrEquationFailed, Equation: 1, Msg: "ProofAggregate does not match totalPoint"})
 	}
 
 	if !comm.Equal(totalPoint) {
-		return false, &errorProof{"RangeProof.Verify", fmt.Sprintf("ProofAggregate does not match commitment")}
+		return false, zkpcp.traceError(&ProofError{Type: RangeProofType, Op: "RangeProof.Verify", Err: ErrEquationFailed, Equation: 2, Msg: "ProofAggregate does not match commitment"})
 	}
 
+	zkpcp.trace(RangeProofType, "verified")
 	return true, nil
 }
 
-// Bytes returns a byte slice with a serialized representation of RangeProof proof
-func (proof *RangeProof) Bytes() []byte {
-	var buf bytes.Buffer
+// ChallengeHash returns the hash the challenges of proof were computed with
+func (proof *RangeProof) ChallengeHash() ChallengeHash {
+	return proof.hash
+}
+
+// Type returns RangeProofType
+func (proof *RangeProof) Type() ProofType {
+	return RangeProofType
+}
+
+// VerifyStatement checks proof against a RangeStatement, see Verify
+func (proof *RangeProof) VerifyStatement(s Statement) (bool, error) {
+	st, ok := s.(RangeStatement)
+	if !ok {
+		return false, wrongStatement(RangeProofType, s)
+	}
+	return proof.Verify(st.Params, st.Comm)
+}
+
+// Diagnose runs every check of Verify and reports the outcome of each, see
+// VerificationReport
+func (proof *RangeProof) Diagnose(zkpcp ZKPCurveParams, comm ECPoint) *VerificationReport {
+	r := newReport(RangeProofType, zkpcp)
+	if proof == nil || len(proof.ProofTuples) == 0 || len(proof.ProofTuples) > len(zkpcp.HPoints) {
+		return r.malformed()
+	}
+	points := []ECPoint{comm, proof.ProofAggregate}
+	scalars := []*big.Int{proof.ProofE}
+	for _, t := range proof.ProofTuples {
+		points = append(points, t.C)
+		scalars = append(scalars, t.S)
+	}
+	if !r.wellFormed(points, scalars) {
+		return r
+	}
+	r.hash(proof.hash)
+
+	Rpoints := make([]ECPoint, len(proof.ProofTuples))
+	totalPoint := Zero
+	for i, t := range proof.ProofTuples {
+		Rpoints[i] = verifyGen(zkpcp, i, proof.ProofE, t)
+		totalPoint = zkpcp.Add(totalPoint, t.C)
+	}
+	calculatedE0 := pointsChallenge(zkpcp, Rpoints...)
+	r.scalar("HASH(R_0, ..., R_n) == ProofE", calculatedE0, proof.ProofE)
+
+	r.point("C_0 + ... + C_n == ProofAggregate", 1, totalPoint, proof.ProofAggregate)
+	r.point("C_0 + ... + C_n == commitment", 2, totalPoint, comm)
+	return r
+}
+
+// DiagnoseStatement diagnoses proof against a RangeStatement, see Diagnose
+func (proof *RangeProof) DiagnoseStatement(s Statement) (*VerificationReport, error) {
+	st, ok := s.(RangeStatement)
+	if !ok {
+		return nil, wrongStatement(RangeProofType, s)
+	}
+	return proof.Diagnose(st.Params, st.Comm), nil
+}
 
-	WriteECPoint(&buf, proof.ProofAggregate)
-	WriteBigInt(&buf, proof.ProofE)
-	wire.WriteVarInt(&buf, uint64(len(proof.ProofTuples)))
+// WriteTo writes the serialized representation of RangeProof proof to w
+func (proof *RangeProof) WriteTo(w io.Writer) (int64, error) {
+	pw := newProofWriter(w, proofCurve(proof.curve), proof.hash)
+	pw.point(proof.ProofAggregate)
+	pw.scalar(proof.ProofE)
+	pw.count(len(proof.ProofTuples))
 	for _, t := range proof.ProofTuples {
-		WriteECPoint(&buf, t.C)
-		WriteBigInt(&buf, t.S)
+		pw.point(t.C)
+		pw.scalar(t.S)
 	}
+	return pw.done()
+}
+
+// ReadFrom reads one RangeProof written by WriteTo from r into proof.  It reads no
+// further than the end of the proof, so proofs can be read back to back from
+// a stream; io.EOF is returned if r ends before the next proof starts.
+func (proof *RangeProof) ReadFrom(r io.Reader) (int64, error) {
+	var p RangeProof
+	pr := newProofReader("RangeProof.ReadFrom", r)
+	p.curve, p.hash = pr.curve, pr.hash
+	p.ProofAggregate = pr.point("ProofAggregate")
+	p.ProofE = pr.scalar("ProofE")
+	// Verify indexes HPoints by tuple, so there can be no more tuples than that.
+	numTuples := pr.count("ProofTuples", numHPoints)
+	p.ProofTuples = make([]rangeProofTuple, numTuples)
+	for i := range p.ProofTuples {
+		p.ProofTuples[i].C = pr.point(fmt.Sprintf("ProofTuples[%d].C", i))
+		p.ProofTuples[i].S = pr.scalar(fmt.Sprintf("ProofTuples[%d].S", i))
+	}
+	n, err := pr.done()
+	if err != nil {
+		return n, err
+	}
+	*proof = p
+	return n, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (proof *RangeProof) MarshalBinary() ([]byte, error) {
+	return marshalProof(proof)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.  b must hold exactly
+// one proof.
+func (proof *RangeProof) UnmarshalBinary(b []byte) error {
+	return unmarshalProof("RangeProof.UnmarshalBinary", b, proof)
+}
 
-	return buf.Bytes()
+// Bytes returns a byte slice with a serialized representation of RangeProof proof, or
+// nil if proof can not be serialized
+func (proof *RangeProof) Bytes() []byte {
+	b, _ := proof.MarshalBinary()
+	return b
 }
 
 // NewRangeProofFromBytes returns a RangeProof generated from the
 // deserialization of byte slice b
 func NewRangeProofFromBytes(b []byte) (*RangeProof, error) {
 	proof := new(RangeProof)
-	buf := bytes.NewBuffer(b)
+	if err := proof.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
 
-	proof.ProofAggregate, _ = ReadECPoint(buf)
-	proof.ProofE, _ = ReadBigInt(buf)
-	numTuples, _ := wire.ReadVarInt(buf)
-	proof.ProofTuples = make([]rangeProofTuple, numTuples)
-	for i := uint64(0); i < numTuples; i++ {
-		proof.ProofTuples[i] = rangeProofTuple{}
-		proof.ProofTuples[i].C, _ = ReadECPoint(buf)
-		proof.ProofTuples[i].S, _ = ReadBigInt(buf)
+// MarshalJSON encodes proof as a JSON object in its group, see Group.Encode
+func (proof *RangeProof) MarshalJSON() ([]byte, error) {
+	curve := proofCurve(proof.curve)
+	w := newJSONWriter(curve)
+	w.group()
+	w.hash(proof.hash)
+	w.point("ProofAggregate", proof.ProofAggregate)
+	w.scalar("ProofE", proof.ProofE)
+	tuples := make([]*jsonWriter, len(proof.ProofTuples))
+	for i, t := range proof.ProofTuples {
+		tuples[i] = newJSONWriter(curve)
+		tuples[i].point("C", t.C)
+		tuples[i].scalar("S", t.S)
 	}
+	w.objects("ProofTuples", tuples)
+	return w.marshal()
+}
 
-	return proof, nil
+// UnmarshalJSON decodes a proof written by MarshalJSON
+func (proof *RangeProof) UnmarshalJSON(b []byte) error {
+	var p RangeProof
+	r := newJSONReader("RangeProof.UnmarshalJSON", b)
+	r.group()
+	p.hash = r.hash()
+	p.ProofAggregate = r.point("ProofAggregate")
+	p.ProofE = r.scalar("ProofE")
+	tuples := r.objects("ProofTuples", numHPoints)
+	p.ProofTuples = make([]rangeProofTuple, len(tuples))
+	for i, t := range tuples {
+		p.ProofTuples[i].C = t.point("C")
+		p.ProofTuples[i].S = t.scalar("S")
+		r.fail("ProofTuples", t.done())
+	}
+	if err := r.done(); err != nil {
+		return err
+	}
+	p.curve = r.curve
+	*proof = p
+	return nil
 }
@@ -1,23 +1,31 @@
 package zksigma
 
 import (
+	"context"
 	"crypto/rand"
+	"errors"
 	"math/big"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // Copy-pasted from original apl implementation by Willy (github.com/wrv)
 func TestRangeProver_Verify(t *testing.T) {
+	forEachCurve(t, testRangeProver_Verify)
+}
+
+func testRangeProver_Verify(t *testing.T, zkpcp ZKPCurveParams) {
 	value, _ := rand.Int(rand.Reader, big.NewInt(1099511627775))
-	proof, rp, err := NewRangeProof(TestCurve, value)
+	proof, rp, err := NewRangeProof(zkpcp, value)
 	if err != nil {
 		t.Fatalf("TestRangeProver_Verify failed to generate proof\n")
 	}
-	comm := PedCommitR(TestCurve, value, rp)
+	comm := PedCommitR(zkpcp, value, rp)
 	if !comm.Equal(proof.ProofAggregate) {
 		t.Error("Error computing the randomnesses used -- commitments did not check out when supposed to")
 	} else {
-		ok, err := proof.Verify(TestCurve, comm)
+		ok, err := proof.Verify(zkpcp, comm)
 		if !ok {
 			t.Errorf("** Range proof failed: %s", err)
 		} else {
@@ -27,8 +35,12 @@ func TestRangeProver_Verify(t *testing.T) {
 }
 
 func TestRangeProverSerialization(t *testing.T) {
+	forEachCurve(t, testRangeProverSerialization)
+}
+
+func testRangeProverSerialization(t *testing.T, zkpcp ZKPCurveParams) {
 	value, _ := rand.Int(rand.Reader, big.NewInt(1099511627775))
-	proof, rp, err := NewRangeProof(TestCurve, value)
+	proof, rp, err := NewRangeProof(zkpcp, value)
 	if err != nil {
 		t.Fatalf("TestRangeProverSerialization failed to generate proof\n")
 	}
@@ -36,11 +48,11 @@ func TestRangeProverSerialization(t *testing.T) {
 	if err != nil {
 		t.Fatalf("TestRangeProverSerialization failed to deserialize\n")
 	}
-	comm := PedCommitR(TestCurve, value, rp)
+	comm := PedCommitR(zkpcp, value, rp)
 	if !comm.Equal(proof.ProofAggregate) {
 		t.Error("Error computing the randomnesses used -- commitments did not check out when supposed to")
 	} else {
-		ok, err := proof.Verify(TestCurve, comm)
+		ok, err := proof.Verify(zkpcp, comm)
 		if !ok {
 			t.Errorf("** Range proof failed: %s", err)
 		} else {
@@ -50,15 +62,76 @@ func TestRangeProverSerialization(t *testing.T) {
 }
 
 func TestOutOfRangeRangeProver_Verify(t *testing.T) {
+	forEachCurve(t, testOutOfRangeRangeProver_Verify)
+}
+
+func testOutOfRangeRangeProver_Verify(t *testing.T, zkpcp ZKPCurveParams) {
 	min := new(big.Int).Exp(new(big.Int).SetInt64(2), new(big.Int).SetInt64(64), nil)
 
-	value, err := rand.Int(rand.Reader, new(big.Int).Add(new(big.Int).Sub(TestCurve.C.Params().N, min), min)) // want to make sure it's out of range
+	value, err := rand.Int(rand.Reader, new(big.Int).Add(new(big.Int).Sub(zkpcp.C.Order(), min), min)) // want to make sure it's out of range
 	if err != nil {
 		t.Error(err)
 	}
 
-	_, _, err = NewRangeProof(TestCurve, value)
+	_, _, err = NewRangeProof(zkpcp, value)
 	if err == nil {
 		t.Error("Computing the range proof shouldn't work but it did")
 	}
 }
+
+func TestRangeProofContext(t *testing.T) {
+	value := big.NewInt(123456789)
+	proof, r, err := NewRangeProofContext(context.Background(), TestCurve, value)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	comm := PedCommitR(TestCurve, value, r)
+	if ok, err := proof.VerifyContext(context.Background(), TestCurve, comm); !ok {
+		t.Fatalf("range proof failed: %v\n", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := NewRangeProofContext(ctx, TestCurve, value); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a cancelled prover, got %v\n", err)
+	}
+	if ok, err := proof.VerifyContext(ctx, TestCurve, comm); ok || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a cancelled verifier, got %v, %v\n", ok, err)
+	}
+
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	var pe *ProofError
+	if _, _, err := NewRangeProofContext(ctx, TestCurve, value); !errors.Is(err, context.DeadlineExceeded) || !errors.As(err, &pe) {
+		t.Fatalf("expected a ProofError for the deadline, got %v\n", err)
+	}
+}
+
+func TestForEachIndex(t *testing.T) {
+	var calls int32
+	err := forEachIndex(context.Background(), 0, 1000, func(i int) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+	if err != nil || calls != 1000 {
+		t.Fatalf("expected 1000 calls and no error, got %d, %v\n", calls, err)
+	}
+
+	// The first error is returned and no further indices are handed out.
+	errStop := errors.New("stop")
+	calls = 0
+	err = forEachIndex(context.Background(), 0, 1000, func(i int) error {
+		atomic.AddInt32(&calls, 1)
+		if i == 0 {
+			return errStop
+		}
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+	if err != errStop {
+		t.Fatalf("expected %v, got %v\n", errStop, err)
+	}
+	if calls == 1000 {
+		t.Fatalf("work went on after an error\n")
+	}
+}
@@ -0,0 +1,172 @@
+package zksigma
+
+import (
+	"encoding/json"
+	"math/big"
+)
+
+// VerificationReport is the result of diagnosing a proof: unlike Verify,
+// which stops at the first check that fails, Diagnose evaluates every check
+// of the proof and records each outcome.
+type VerificationReport struct {
+	Type   ProofType
+	Group  Group
+	Checks []CheckResult
+	Inner  []*VerificationReport // reports of nested proofs, e.g. ABCProof.DisjuncAC
+
+	zkpcp ZKPCurveParams
+}
+
+// CheckResult is one check of a VerificationReport, that Left equals Right.
+// Point checks fill in Left and Right, challenge checks LeftScalar and
+// RightScalar and the challenge hash check LeftText and RightText.
+type CheckResult struct {
+	Name     string // the check, e.g. "s*Base + c*A == RandCommit"
+	Equation int    // verification equation, as in ProofError.Equation, or 0
+	Passed   bool
+
+	Left, Right             ECPoint
+	LeftScalar, RightScalar *big.Int
+	LeftText, RightText     string
+}
+
+func newReport(t ProofType, zkpcp ZKPCurveParams) *VerificationReport {
+	return &VerificationReport{Type: t, Group: zkpcp.C, zkpcp: zkpcp}
+}
+
+// OK reports whether every check, including those of nested proofs, passed.
+func (r *VerificationReport) OK() bool {
+	if len(r.Checks) == 0 {
+		return false
+	}
+	for _, c := range r.Checks {
+		if !c.Passed {
+			return false
+		}
+	}
+	for _, inner := range r.Inner {
+		if !inner.OK() {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed returns the checks that did not pass, not including nested proofs.
+func (r *VerificationReport) Failed() []CheckResult {
+	var failed []CheckResult
+	for _, c := range r.Checks {
+		if !c.Passed {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// wellFormedCheck is the first check of every report.  The equations are
+// only evaluated if it passes.
+const wellFormedCheck = "proof and statement points and scalars are valid"
+
+// wellFormed records whether the generators, the points, the scalars and the
+// challenges of compact proofs all pass the checks verifiers make on their
+// inputs, see validPoint, validScalar and validChallenge.  That must hold
+// before any equation can be evaluated.
+func (r *VerificationReport) wellFormed(points []ECPoint, scalars []*big.Int, challenges ...*big.Int) bool {
+	if r.Group == nil {
+		r.malformed()
+		return false
+	}
+	ok := true
+	for _, p := range append([]ECPoint{r.zkpcp.G, r.zkpcp.H}, points...) {
+		ok = ok && validPoint(r.Group, p) == nil
+	}
+	for _, s := range scalars {
+		ok = ok && validScalar(r.Group, s) == nil
+	}
+	for _, c := range challenges {
+		ok = ok && validChallenge(c) == nil
+	}
+	r.Checks = append(r.Checks, CheckResult{Name: wellFormedCheck, Passed: ok})
+	return ok
+}
+
+// malformed records a failed wellFormed check, for proofs that are nil or
+// otherwise cannot be diagnosed, and returns r.
+func (r *VerificationReport) malformed() *VerificationReport {
+	r.Checks = append(r.Checks, CheckResult{Name: wellFormedCheck})
+	return r
+}
+
+// point records the check left == right.
+func (r *VerificationReport) point(name string, equation int, left, right ECPoint) {
+	r.Checks = append(r.Checks, CheckResult{
+		Name:     name,
+		Equation: equation,
+		Passed:   left.Equal(right),
+		Left:     left,
+		Right:    right,
+	})
+}
+
+// scalar records the check left == right.
+func (r *VerificationReport) scalar(name string, left, right *big.Int) {
+	r.Checks = append(r.Checks, CheckResult{
+		Name:        name,
+		Passed:      left != nil && right != nil && left.Cmp(right) == 0,
+		LeftScalar:  left,
+		RightScalar: right,
+	})
+}
+
+// hash records the check that the proof was made with zkpcp.Hash, with the
+// names of the hashes as LeftText and RightText.
+func (r *VerificationReport) hash(h ChallengeHash) {
+	r.Checks = append(r.Checks, CheckResult{
+		Name:      "ChallengeHash == zkpcp.Hash",
+		Passed:    h == r.zkpcp.Hash,
+		LeftText:  h.String(),
+		RightText: r.zkpcp.Hash.String(),
+	})
+}
+
+// MarshalJSON encodes r for logging, with points encoded in hex as in
+// proofs, scalars in hex and hash names as they are.
+func (r *VerificationReport) MarshalJSON() ([]byte, error) {
+	type check struct {
+		Name     string `json:"name"`
+		Equation int    `json:"equation,omitempty"`
+		Passed   bool   `json:"passed"`
+		Left     string `json:"left,omitempty"`
+		Right    string `json:"right,omitempty"`
+	}
+	curve := proofCurve(r.Group)
+	hexValue := func(p ECPoint, s *big.Int, text string) string {
+		switch {
+		case text != "":
+			return text
+		case s != nil:
+			return s.Text(16)
+		case p.isNil():
+			return ""
+		}
+		return tracedPoint{curve, p}.LogValue().String()
+	}
+
+	checks := make([]check, len(r.Checks))
+	for i, c := range r.Checks {
+		checks[i] = check{
+			Name:     c.Name,
+			Equation: c.Equation,
+			Passed:   c.Passed,
+			Left:     hexValue(c.Left, c.LeftScalar, c.LeftText),
+			Right:    hexValue(c.Right, c.RightScalar, c.RightText),
+		}
+	}
+	return json.Marshal(struct {
+		Type   string                `json:"type"`
+		Group  string                `json:"group"`
+		OK     bool                  `json:"ok"`
+		Checks []check               `json:"checks"`
+		Inner  []*VerificationReport `json:"inner,omitempty"`
+	}{r.Type.String(), curve.Name(), r.OK(), checks, r.Inner})
+}
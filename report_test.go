@@ -0,0 +1,111 @@
+package zksigma
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestDiagnose(t *testing.T) {
+	forEachCurve(t, testDiagnose)
+}
+
+func testDiagnose(t *testing.T, zkpcp ZKPCurveParams) {
+	for _, ps := range sampleProofs(t, zkpcp) {
+		r, err := ps.proof.DiagnoseStatement(ps.st)
+		if err != nil {
+			t.Fatalf("%v: %v\n", ps.proof.Type(), err)
+		}
+		if !r.OK() || len(r.Failed()) != 0 || r.Type != ps.proof.Type() {
+			t.Fatalf("%v: valid proof should pass every check: %+v\n", ps.proof.Type(), r.Checks)
+		}
+		// well formed, a challenge and at least one equation, which compact
+		// proofs fold into the challenge
+		want := 3
+		if ps.proof.Type() >= CompactGSPFSProofType {
+			want = 2
+		}
+		if len(r.Checks) < want {
+			t.Fatalf("%v: only %d checks\n", ps.proof.Type(), len(r.Checks))
+		}
+		if _, err := ps.proof.DiagnoseStatement(RangeStatement{zkpcp, Zero}); ps.proof.Type() != RangeProofType && err == nil {
+			t.Fatalf("%v: should not diagnose a RangeStatement\n", ps.proof.Type())
+		}
+	}
+
+	// A wrong response fails only the equation it is in.
+	x := big.NewInt(21)
+	X := zkpcp.Mult(zkpcp.G, x)
+	XH := zkpcp.Mult(zkpcp.H, x)
+	eq, err := NewEquivalenceProof(zkpcp, zkpcp.G, X, zkpcp.H, XH, x)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	bad := *eq
+	bad.UH = zkpcp.Add(eq.UH, zkpcp.G)
+	bad.Challenge = GenerateChallenge(zkpcp, zkpcp.Bytes(zkpcp.G), zkpcp.Bytes(X),
+		zkpcp.Bytes(zkpcp.H), zkpcp.Bytes(XH), zkpcp.Bytes(bad.UG), zkpcp.Bytes(bad.UH))
+	r := bad.Diagnose(zkpcp, zkpcp.G, X, zkpcp.H, XH)
+	failed := r.Failed()
+	if r.OK() || len(failed) != 2 || failed[0].Equation != 1 || failed[1].Equation != 2 {
+		t.Fatalf("expected both equations to fail, got %+v\n", failed)
+	}
+	if r.Checks[1].Passed != true {
+		t.Fatalf("recomputed challenge should match: %+v\n", r.Checks[1])
+	}
+
+	// A broken inner proof shows up in Inner only.
+	PK, sk := KeyGen(zkpcp.C, zkpcp.H)
+	CM, u, err := PedCommit(zkpcp, x)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	CMTok := zkpcp.Mult(PK, u)
+	abc, err := NewABCProof(zkpcp, CM, CMTok, x, sk, Right)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	dj := *abc.DisjuncAC
+	dj.S1 = zkpcp.C.AddScalars(dj.S1, big.NewInt(1))
+	abc.DisjuncAC = &dj
+	r = abc.Diagnose(zkpcp, CM, CMTok)
+	if r.OK() || len(r.Failed()) != 0 || len(r.Inner) != 1 || len(r.Inner[0].Failed()) != 1 {
+		t.Fatalf("expected one failure in the disjunctive proof, got %+v\n", r)
+	}
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	if !strings.Contains(string(b), `"ok":false`) || !strings.Contains(string(b), `"name":"S1*Base1 == T1 + C1*Result1","equation":1,"passed":false`) {
+		t.Fatalf("unexpected JSON report %s\n", b)
+	}
+
+	// The challenge hash check names both hashes.
+	gspfs, err := NewGSPFSProof(zkpcp.WithHash(HashSHA256), X, x)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	r = gspfs.Diagnose(zkpcp, X)
+	if r.OK() {
+		t.Fatalf("proof with another hash should fail: %+v\n", r.Checks)
+	}
+	b, err = json.Marshal(r)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	if !strings.Contains(string(b), `"passed":false,"left":"SHA-256-wide","right":"SHAKE256"`) {
+		t.Fatalf("unexpected JSON report %s\n", b)
+	}
+
+	// Missing fields are reported instead of panicking.
+	r = (*RangeProof)(nil).Diagnose(zkpcp, X)
+	if r.OK() || len(r.Checks) != 1 || r.Checks[0].Name != wellFormedCheck {
+		t.Fatalf("nil proof should fail the first check: %+v\n", r.Checks)
+	}
+	r = (&GSPFSProof{Base: zkpcp.G, RandCommit: X}).Diagnose(zkpcp, X)
+	if r.OK() || len(r.Checks) != 1 {
+		t.Fatalf("proof without scalars should fail the first check: %+v\n", r.Checks)
+	}
+}
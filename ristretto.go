@@ -0,0 +1,399 @@
+package zksigma
+
+import (
+	"crypto/sha512"
+	"errors"
+	"math/big"
+	"sync"
+)
+
+// Ristretto255 is implemented following RFC 9496 on top of the twisted
+// Edwards curve -x^2 + y^2 = 1 + dx^2y^2 over GF(2^255 - 19).  The field
+// arithmetic is plain big.Int arithmetic and is not constant time, so
+// SecretScalarMult uses the fixed size arithmetic of ristretto_ct.go instead.
+//
+// A ristretto255 element is a class of Edwards points.  ECPoints hold the
+// affine coordinates of the representative that Decode picks for the class,
+// so two ECPoints are the same element if and only if they are Equal.  As for
+// every Group in zksigma, the identity is stored as Zero rather than (0, 1).
+
+var (
+	edP          = fromDecimal("57896044618658097711785492504343953926634992332820282019728792003956564819949")
+	edD          = fromDecimal("37095705934669439343138083508754565189542113879843219016388785533085940283555")
+	edSqrtM1     = fromDecimal("19681161376707505956807079304988542015446066515923890162744021073123829784752")
+	edSqrtADMin1 = fromDecimal("25063068953384623474111414158702152701244531502492656460079210482610430750235")
+	edInvSqrtAMD = fromDecimal("54469307008909316920995813868745141605393597292927456921205312896311721017578")
+	edOneMinDSq  = fromDecimal("1159843021668779879193775521855586647937357759715417654439879720876111806838")
+	edDMinOneSq  = fromDecimal("40440834346308536858101042469323190826248399146238708352240133220865137265952")
+	edOrder      = fromDecimal("7237005577332262213973186563042994240857116359379907606001950938285454250989")
+
+	edOne = big.NewInt(1)
+	edTwo = big.NewInt(2)
+)
+
+func fromDecimal(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid decimal constant: " + s)
+	}
+	return n
+}
+
+// ====== GF(2^255 - 19) ======
+// All fe* functions return fresh values reduced into [0, p).
+
+func feMul(a, b *big.Int) *big.Int {
+	r := new(big.Int).Mul(a, b)
+	return r.Mod(r, edP)
+}
+
+func feAdd(a, b *big.Int) *big.Int {
+	r := new(big.Int).Add(a, b)
+	return r.Mod(r, edP)
+}
+
+func feSub(a, b *big.Int) *big.Int {
+	r := new(big.Int).Sub(a, b)
+	return r.Mod(r, edP)
+}
+
+func feNeg(a *big.Int) *big.Int {
+	r := new(big.Int).Neg(a)
+	return r.Mod(r, edP)
+}
+
+func feIsNegative(a *big.Int) bool {
+	return a.Bit(0) == 1
+}
+
+func feAbs(a *big.Int) *big.Int {
+	if feIsNegative(a) {
+		return feNeg(a)
+	}
+	return new(big.Int).Set(a)
+}
+
+// feSqrtRatioM1 returns (true, sqrt(u/v)) if u/v is square and
+// (false, sqrt(i*u/v)) otherwise, always picking the non-negative root.
+func feSqrtRatioM1(u, v *big.Int) (bool, *big.Int) {
+	v3 := feMul(feMul(v, v), v)
+	v7 := feMul(feMul(v3, v3), v)
+	exp := new(big.Int).Sub(edP, big.NewInt(5))
+	exp.Rsh(exp, 3)
+	r := feMul(feMul(u, v3), new(big.Int).Exp(feMul(u, v7), exp, edP))
+	check := feMul(v, feMul(r, r))
+
+	correctSign := check.Cmp(u) == 0
+	flippedSign := check.Cmp(feNeg(u)) == 0
+	flippedSignI := check.Cmp(feNeg(feMul(u, edSqrtM1))) == 0
+
+	if flippedSign || flippedSignI {
+		r = feMul(r, edSqrtM1)
+	}
+	return correctSign || flippedSign, feAbs(r)
+}
+
+// ====== Edwards points in extended coordinates ======
+
+type edPoint struct {
+	X, Y, Z, T *big.Int
+}
+
+func edIdentity() edPoint {
+	return edPoint{big.NewInt(0), big.NewInt(1), big.NewInt(1), big.NewInt(0)}
+}
+
+func edFromAffine(x, y *big.Int) edPoint {
+	return edPoint{new(big.Int).Set(x), new(big.Int).Set(y), big.NewInt(1), feMul(x, y)}
+}
+
+// edAdd uses the complete addition formula for a = -1
+// (add-2008-hwcd-3), which also handles doubling.
+func edAdd(p, q edPoint) edPoint {
+	a := feMul(feSub(p.Y, p.X), feSub(q.Y, q.X))
+	b := feMul(feAdd(p.Y, p.X), feAdd(q.Y, q.X))
+	c := feMul(feMul(p.T, q.T), feMul(edTwo, edD))
+	d := feMul(feMul(p.Z, q.Z), edTwo)
+	e, f, g, h := feSub(b, a), feSub(d, c), feAdd(d, c), feAdd(b, a)
+	return edPoint{feMul(e, f), feMul(g, h), feMul(f, g), feMul(e, h)}
+}
+
+func edNeg(p edPoint) edPoint {
+	return edPoint{feNeg(p.X), new(big.Int).Set(p.Y), new(big.Int).Set(p.Z), feNeg(p.T)}
+}
+
+// edScalarMult computes k * p with a 4-bit fixed window, k must be non-negative.
+func edScalarMult(p edPoint, k *big.Int) edPoint {
+	var table [16]edPoint
+	table[0] = edIdentity()
+	for i := 1; i < 16; i++ {
+		table[i] = edAdd(table[i-1], p)
+	}
+
+	q := edIdentity()
+	for i := (k.BitLen() + 3) / 4; i >= 0; i-- {
+		for j := 0; j < 4; j++ {
+			q = edAdd(q, q)
+		}
+		w := k.Bit(4*i) | k.Bit(4*i+1)<<1 | k.Bit(4*i+2)<<2 | k.Bit(4*i+3)<<3
+		q = edAdd(q, table[w])
+	}
+	return q
+}
+
+// ristrettoEncode is the ENCODE function of RFC 9496 section 4.3.2, returning
+// s as a field element.
+func ristrettoEncode(p edPoint) *big.Int {
+	u1 := feMul(feAdd(p.Z, p.Y), feSub(p.Z, p.Y))
+	u2 := feMul(p.X, p.Y)
+	_, invsqrt := feSqrtRatioM1(edOne, feMul(u1, feMul(u2, u2)))
+	den1 := feMul(invsqrt, u1)
+	den2 := feMul(invsqrt, u2)
+	zInv := feMul(feMul(den1, den2), p.T)
+
+	x, y, denInv := p.X, p.Y, den2
+	if feIsNegative(feMul(p.T, zInv)) {
+		x = feMul(p.Y, edSqrtM1)
+		y = feMul(p.X, edSqrtM1)
+		denInv = feMul(den1, edInvSqrtAMD)
+	}
+	if feIsNegative(feMul(x, zInv)) {
+		y = feNeg(y)
+	}
+	return feAbs(feMul(denInv, feSub(p.Z, y)))
+}
+
+// ristrettoDecode is the DECODE function of RFC 9496 section 4.3.1, taking s
+// as an integer.  It returns the affine coordinates of the representative.
+func ristrettoDecode(s *big.Int) (*big.Int, *big.Int, error) {
+	if s.Cmp(edP) >= 0 || feIsNegative(s) {
+		return nil, nil, errors.New("ristretto255: non-canonical encoding")
+	}
+	ss := feMul(s, s)
+	u1 := feSub(edOne, ss)
+	u2 := feAdd(edOne, ss)
+	u2Sqr := feMul(u2, u2)
+	v := feSub(feNeg(feMul(edD, feMul(u1, u1))), u2Sqr)
+	wasSquare, invsqrt := feSqrtRatioM1(edOne, feMul(v, u2Sqr))
+	denX := feMul(invsqrt, u2)
+	denY := feMul(feMul(invsqrt, denX), v)
+	x := feAbs(feMul(feMul(edTwo, s), denX))
+	y := feMul(u1, denY)
+	if !wasSquare || feIsNegative(feMul(x, y)) || y.Sign() == 0 {
+		return nil, nil, errors.New("ristretto255: invalid encoding")
+	}
+	return x, y, nil
+}
+
+// ristrettoMap is the MAP function of RFC 9496 section 4.3.4.
+func ristrettoMap(b []byte) edPoint {
+	t := new(big.Int).SetBytes(reverse(b))
+	t.SetBit(t, 255, 0)
+	t.Mod(t, edP)
+
+	r := feMul(edSqrtM1, feMul(t, t))
+	u := feMul(feAdd(r, edOne), edOneMinDSq)
+	v := feMul(feSub(feNeg(edOne), feMul(r, edD)), feAdd(r, edD))
+	wasSquare, s := feSqrtRatioM1(u, v)
+	c := feNeg(edOne)
+	if !wasSquare {
+		s = feNeg(feAbs(feMul(s, t)))
+		c = r
+	}
+	n := feSub(feMul(feMul(c, feSub(r, edOne)), edDMinOneSq), v)
+
+	w0 := feMul(feMul(edTwo, s), v)
+	w1 := feMul(n, edSqrtADMin1)
+	w2 := feSub(edOne, feMul(s, s))
+	w3 := feAdd(edOne, feMul(s, s))
+	return edPoint{feMul(w0, w3), feMul(w2, w1), feMul(w1, w3), feMul(w0, w2)}
+}
+
+// reverse returns a reversed copy of b, converting between the little-endian
+// encodings of RFC 9496 and big.Int's big-endian ones.
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
+
+// ====== Group ======
+
+type ristretto255Group struct {
+	scalarField
+	g ECPoint
+}
+
+var (
+	ristretto255Once     sync.Once
+	ristretto255Instance *ristretto255Group
+)
+
+// Ristretto255 returns the ristretto255 Group.  Its generator is the
+// ristretto255 base point, and Encode produces the canonical 32-byte
+// encoding.
+func Ristretto255() Group {
+	ristretto255Once.Do(func() {
+		g := &ristretto255Group{scalarField: scalarField{edOrder}}
+		// The Ed25519 base point: y = 4/5, x non-negative.
+		y := feMul(big.NewInt(4), new(big.Int).ModInverse(big.NewInt(5), edP))
+		yy := feMul(y, y)
+		_, x := feSqrtRatioM1(feSub(yy, edOne), feAdd(feMul(edD, yy), edOne))
+		g.g = g.fromEdwards(edFromAffine(x, y))
+		ristretto255Instance = g
+	})
+	return ristretto255Instance
+}
+
+// fromEdwards maps an Edwards point to the ECPoint for its class.  ENCODE
+// only produces valid encodings for points of the ristretto255 group; for any
+// other point of the curve it returns the nil ECPoint{}.
+func (g *ristretto255Group) fromEdwards(p edPoint) ECPoint {
+	s := ristrettoEncode(p)
+	if s.Sign() == 0 {
+		return Zero
+	}
+	x, y, err := ristrettoDecode(s)
+	if err != nil {
+		return ECPoint{}
+	}
+	return ECPoint{x, y}
+}
+
+// onCurve reports whether p is the identity or a point on the Edwards curve
+// with reduced coordinates.  It is the cheap part of Contains, which Add,
+// Neg and the scalar multiplications check their inputs with so that points
+// off the curve give the nil ECPoint{} rather than a panic in fromEdwards.
+func (g *ristretto255Group) onCurve(p ECPoint) bool {
+	if p.X == nil || p.Y == nil {
+		return false
+	}
+	if g.IsIdentity(p) {
+		return true
+	}
+	if p.X.Sign() < 0 || p.X.Cmp(edP) >= 0 || p.Y.Sign() < 0 || p.Y.Cmp(edP) >= 0 {
+		return false
+	}
+	// -x^2 + y^2 = 1 + dx^2y^2
+	xx, yy := feMul(p.X, p.X), feMul(p.Y, p.Y)
+	return feSub(yy, xx).Cmp(feAdd(edOne, feMul(edD, feMul(xx, yy)))) == 0
+}
+
+func (g *ristretto255Group) toEdwards(p ECPoint) edPoint {
+	if g.IsIdentity(p) {
+		return edIdentity()
+	}
+	return edFromAffine(p.X, p.Y)
+}
+
+func (g *ristretto255Group) Name() string {
+	return "ristretto255"
+}
+
+func (g *ristretto255Group) Generator() ECPoint {
+	return g.g
+}
+
+func (g *ristretto255Group) Identity() ECPoint {
+	return Zero
+}
+
+func (g *ristretto255Group) IsIdentity(p ECPoint) bool {
+	return p.X.Sign() == 0 && p.Y.Sign() == 0
+}
+
+func (g *ristretto255Group) Contains(p ECPoint) bool {
+	if !g.onCurve(p) {
+		return false
+	}
+	if g.IsIdentity(p) {
+		return true
+	}
+	// Only the representative Decode picks is a valid ECPoint.
+	return g.fromEdwards(edFromAffine(p.X, p.Y)).Equal(p)
+}
+
+// Add returns p + q, or the nil ECPoint{} if p or q is not on the curve.
+func (g *ristretto255Group) Add(p, q ECPoint) ECPoint {
+	if !g.onCurve(p) || !g.onCurve(q) {
+		return ECPoint{}
+	}
+	return g.fromEdwards(edAdd(g.toEdwards(p), g.toEdwards(q)))
+}
+
+// Neg returns -p, or the nil ECPoint{} if p is not on the curve.
+func (g *ristretto255Group) Neg(p ECPoint) ECPoint {
+	if !g.onCurve(p) {
+		return ECPoint{}
+	}
+	return g.fromEdwards(edNeg(g.toEdwards(p)))
+}
+
+// ScalarMult returns k * p, or the nil ECPoint{} if p is not on the curve.
+func (g *ristretto255Group) ScalarMult(p ECPoint, k *big.Int) ECPoint {
+	if !g.onCurve(p) {
+		return ECPoint{}
+	}
+	return g.fromEdwards(edScalarMult(g.toEdwards(p), g.ReduceScalar(k)))
+}
+
+// SecretScalarMult computes k * p with ctScalarMult, in time that does not
+// depend on k.  Only mapping the result back to its representative uses
+// big.Ints, and the result is not secret.
+func (g *ristretto255Group) SecretScalarMult(p ECPoint, k *big.Int) ECPoint {
+	if !g.onCurve(p) {
+		return ECPoint{}
+	}
+	if g.IsIdentity(p) {
+		return Zero
+	}
+	modK := g.ReduceScalar(k)
+	var kb [32]byte
+	modK.FillBytes(kb[:])
+	q := ctScalarMult(g.toEdwards(p), &kb)
+	WipeScalars(modK)
+	wipeBytes(kb[:])
+	return g.fromEdwards(q)
+}
+
+func (g *ristretto255Group) ScalarBaseMult(k *big.Int) ECPoint {
+	return g.ScalarMult(g.g, k)
+}
+
+func (g *ristretto255Group) PointSize() int {
+	return 32
+}
+
+// Encode returns the canonical 32-byte encoding of p.
+func (g *ristretto255Group) Encode(p ECPoint) []byte {
+	b := make([]byte, 32)
+	if g.IsIdentity(p) {
+		return b
+	}
+	ristrettoEncode(g.toEdwards(p)).FillBytes(b)
+	return reverse(b)
+}
+
+func (g *ristretto255Group) Decode(b []byte) (ECPoint, error) {
+	if len(b) != 32 {
+		return Zero, errors.New("ristretto255: encoding must be 32 bytes")
+	}
+	s := new(big.Int).SetBytes(reverse(b))
+	if s.Sign() == 0 {
+		return Zero, nil
+	}
+	x, y, err := ristrettoDecode(s)
+	if err != nil {
+		return Zero, err
+	}
+	return ECPoint{x, y}, nil
+}
+
+// HashToPoint hashes msg with SHA-512 and applies the one-way map of RFC 9496
+// section 4.3.4 to the digest.
+func (g *ristretto255Group) HashToPoint(msg []byte) ECPoint {
+	h := sha512.Sum512(msg)
+	return g.fromEdwards(edAdd(ristrettoMap(h[:32]), ristrettoMap(h[32:])))
+}
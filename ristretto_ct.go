@@ -0,0 +1,233 @@
+package zksigma
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+)
+
+// The rest of ristretto.go works on big.Ints, whose running time depends on
+// their values.  SecretScalarMult instead uses the fixed size field elements
+// and points below: every operation runs the same instructions and reads the
+// same memory whatever the scalar, like btcec's ScalarMultConstantTime.
+
+// ctFe is an element of GF(2^255 - 19) in five 51-bit limbs, little-endian.
+// After any operation each limb is below 2^52.
+type ctFe [5]uint64
+
+const ctMaskLow51 = 1<<51 - 1
+
+// ctFeFromBig returns x, which must be in [0, p), as a ctFe.
+func ctFeFromBig(x *big.Int) ctFe {
+	var b [32]byte
+	x.FillBytes(b[:])
+	le := reverse(b[:])
+	var v ctFe
+	v[0] = binary.LittleEndian.Uint64(le[0:8]) & ctMaskLow51
+	v[1] = binary.LittleEndian.Uint64(le[6:14]) >> 3 & ctMaskLow51
+	v[2] = binary.LittleEndian.Uint64(le[12:20]) >> 6 & ctMaskLow51
+	v[3] = binary.LittleEndian.Uint64(le[19:27]) >> 1 & ctMaskLow51
+	v[4] = binary.LittleEndian.Uint64(le[24:32]) >> 12 & ctMaskLow51
+	return v
+}
+
+// big returns v, fully reduced, as a big.Int.
+func (v ctFe) big() *big.Int {
+	v.reduce()
+	var le [32]byte
+	for i, l := range v {
+		off := i * 51
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], l<<uint(off%8))
+		for j, b := range buf {
+			if off/8+j >= len(le) {
+				break
+			}
+			le[off/8+j] |= b
+		}
+	}
+	return new(big.Int).SetBytes(reverse(le[:]))
+}
+
+// carry brings every limb below 2^51 + 2^13 * 19, folding the carry out of
+// the top limb back into the bottom one as 2^255 = 19.
+func (v *ctFe) carry() {
+	c0, c1, c2, c3, c4 := v[0]>>51, v[1]>>51, v[2]>>51, v[3]>>51, v[4]>>51
+	v[0] = v[0]&ctMaskLow51 + c4*19
+	v[1] = v[1]&ctMaskLow51 + c0
+	v[2] = v[2]&ctMaskLow51 + c1
+	v[3] = v[3]&ctMaskLow51 + c2
+	v[4] = v[4]&ctMaskLow51 + c3
+}
+
+// reduce brings v into [0, p).
+func (v *ctFe) reduce() {
+	v.carry()
+	// c is 1 if v >= p, that is if v + 19 carries out of 2^255.
+	c := (v[0] + 19) >> 51
+	c = (v[1] + c) >> 51
+	c = (v[2] + c) >> 51
+	c = (v[3] + c) >> 51
+	c = (v[4] + c) >> 51
+	v[0] += 19 * c
+	v[1] += v[0] >> 51
+	v[0] &= ctMaskLow51
+	v[2] += v[1] >> 51
+	v[1] &= ctMaskLow51
+	v[3] += v[2] >> 51
+	v[2] &= ctMaskLow51
+	v[4] += v[3] >> 51
+	v[3] &= ctMaskLow51
+	v[4] &= ctMaskLow51
+}
+
+func ctFeAdd(a, b *ctFe) ctFe {
+	v := ctFe{a[0] + b[0], a[1] + b[1], a[2] + b[2], a[3] + b[3], a[4] + b[4]}
+	v.carry()
+	return v
+}
+
+// ctFeSub adds 2p before subtracting so that no limb underflows.
+func ctFeSub(a, b *ctFe) ctFe {
+	v := ctFe{
+		a[0] + 0xFFFFFFFFFFFDA - b[0],
+		a[1] + 0xFFFFFFFFFFFFE - b[1],
+		a[2] + 0xFFFFFFFFFFFFE - b[2],
+		a[3] + 0xFFFFFFFFFFFFE - b[3],
+		a[4] + 0xFFFFFFFFFFFFE - b[4],
+	}
+	v.carry()
+	return v
+}
+
+// ctUint128 is a 128-bit product or sum of products.
+type ctUint128 struct {
+	lo, hi uint64
+}
+
+func (r ctUint128) addMul(a, b uint64) ctUint128 {
+	hi, lo := bits.Mul64(a, b)
+	lo, c := bits.Add64(lo, r.lo, 0)
+	hi, _ = bits.Add64(hi, r.hi, c)
+	return ctUint128{lo, hi}
+}
+
+func (r ctUint128) shiftRight51() uint64 {
+	return r.hi<<13 | r.lo>>51
+}
+
+// ctFeMul is schoolbook multiplication, folding the limbs above 2^255 back in
+// times 19.
+func ctFeMul(a, b *ctFe) ctFe {
+	a1x19, a2x19, a3x19, a4x19 := a[1]*19, a[2]*19, a[3]*19, a[4]*19
+
+	var r0, r1, r2, r3, r4 ctUint128
+	r0 = r0.addMul(a[0], b[0]).addMul(a1x19, b[4]).addMul(a2x19, b[3]).addMul(a3x19, b[2]).addMul(a4x19, b[1])
+	r1 = r1.addMul(a[0], b[1]).addMul(a[1], b[0]).addMul(a2x19, b[4]).addMul(a3x19, b[3]).addMul(a4x19, b[2])
+	r2 = r2.addMul(a[0], b[2]).addMul(a[1], b[1]).addMul(a[2], b[0]).addMul(a3x19, b[4]).addMul(a4x19, b[3])
+	r3 = r3.addMul(a[0], b[3]).addMul(a[1], b[2]).addMul(a[2], b[1]).addMul(a[3], b[0]).addMul(a4x19, b[4])
+	r4 = r4.addMul(a[0], b[4]).addMul(a[1], b[3]).addMul(a[2], b[2]).addMul(a[3], b[1]).addMul(a[4], b[0])
+
+	c0, c1, c2, c3, c4 := r0.shiftRight51(), r1.shiftRight51(), r2.shiftRight51(), r3.shiftRight51(), r4.shiftRight51()
+	v := ctFe{
+		r0.lo&ctMaskLow51 + c4*19,
+		r1.lo&ctMaskLow51 + c0,
+		r2.lo&ctMaskLow51 + c1,
+		r3.lo&ctMaskLow51 + c2,
+		r4.lo&ctMaskLow51 + c3,
+	}
+	v.carry()
+	return v
+}
+
+// ctFeInvert returns a^(p - 2), which is 1/a for non-zero a.  The exponent is
+// public, so the square-and-multiply branches only on its bits.
+func ctFeInvert(a *ctFe) ctFe {
+	e := new(big.Int).Sub(edP, edTwo)
+	r := ctFe{1}
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		r = ctFeMul(&r, &r)
+		if e.Bit(i) == 1 {
+			r = ctFeMul(&r, a)
+		}
+	}
+	return r
+}
+
+// ctPoint is an Edwards point in extended coordinates, see edPoint.
+type ctPoint struct {
+	X, Y, Z, T ctFe
+}
+
+// ctD2 is 2d, for ctPoint.add.
+var ctD2 = ctFeFromBig(feMul(edTwo, edD))
+
+func ctPointFromEdwards(p edPoint) ctPoint {
+	return ctPoint{ctFeFromBig(p.X), ctFeFromBig(p.Y), ctFeFromBig(p.Z), ctFeFromBig(p.T)}
+}
+
+// add sets p to p1 + p2 with the same complete formula as edAdd.  p may alias
+// p1 or p2.
+func (p *ctPoint) add(p1, p2 *ctPoint) {
+	y1mx1, y2mx2 := ctFeSub(&p1.Y, &p1.X), ctFeSub(&p2.Y, &p2.X)
+	y1px1, y2px2 := ctFeAdd(&p1.Y, &p1.X), ctFeAdd(&p2.Y, &p2.X)
+	a := ctFeMul(&y1mx1, &y2mx2)
+	b := ctFeMul(&y1px1, &y2px2)
+	t1t2 := ctFeMul(&p1.T, &p2.T)
+	c := ctFeMul(&t1t2, &ctD2)
+	z1z2 := ctFeMul(&p1.Z, &p2.Z)
+	d := ctFeAdd(&z1z2, &z1z2)
+	e, f, g, h := ctFeSub(&b, &a), ctFeSub(&d, &c), ctFeAdd(&d, &c), ctFeAdd(&b, &a)
+	p.X, p.Y, p.Z, p.T = ctFeMul(&e, &f), ctFeMul(&g, &h), ctFeMul(&f, &g), ctFeMul(&e, &h)
+}
+
+// lookup sets p to table[idx], reading every entry of table.
+func (p *ctPoint) lookup(table *[16]ctPoint, idx byte) {
+	*p = ctPoint{}
+	for i := range table {
+		mask := uint64(-subtle.ConstantTimeByteEq(byte(i), idx))
+		for j := range p.X {
+			p.X[j] |= table[i].X[j] & mask
+			p.Y[j] |= table[i].Y[j] & mask
+			p.Z[j] |= table[i].Z[j] & mask
+			p.T[j] |= table[i].T[j] & mask
+		}
+	}
+}
+
+// affine returns the affine coordinates of p, dividing by Z in constant time.
+func (p *ctPoint) affine() (*big.Int, *big.Int) {
+	zInv := ctFeInvert(&p.Z)
+	x, y := ctFeMul(&p.X, &zInv), ctFeMul(&p.Y, &zInv)
+	return x.big(), y.big()
+}
+
+// ctScalarMult computes k * p for the big-endian scalar k with a fixed 4-bit
+// window over all 256 bits of k.
+func ctScalarMult(p edPoint, k *[32]byte) edPoint {
+	// table[i] = i * p, table[0] is the identity (0:1:1:0).
+	var table [16]ctPoint
+	table[0] = ctPoint{Y: ctFe{1}, Z: ctFe{1}}
+	table[1] = ctPointFromEdwards(p)
+	for i := 2; i < len(table); i++ {
+		table[i].add(&table[i-1], &table[1])
+	}
+
+	q := table[0]
+	var t ctPoint
+	for i := 0; i < 64; i++ {
+		for j := 0; j < 4; j++ {
+			q.add(&q, &q)
+		}
+		w := k[i/2] >> 4
+		if i%2 == 1 {
+			w = k[i/2] & 0x0f
+		}
+		t.lookup(&table, w)
+		q.add(&q, &t)
+	}
+
+	x, y := q.affine()
+	return edFromAffine(x, y)
+}
@@ -0,0 +1,123 @@
+package zksigma
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// Encodings of 0*B through 5*B from RFC 9496, appendix A.1.
+var ristrettoMultiplesOfB = []string{
+	"0000000000000000000000000000000000000000000000000000000000000000",
+	"e2f2ae0a6abc4e71a884a961c500515f58e30b6aa582dd8db6a65945e08d2d76",
+	"6a493210f7499cd17fecb510ae0cea23a110e8d5b901f8acadd3095c73a3b919",
+	"94741f5d5d52755ece4f23f044ee27d5d1ea1e2bd196b462166b16152a9d0259",
+	"da80862773358b466ffadfe0b3293ab3d9fd53c5ea6c955358f568322daf6a57",
+	"e882b131016b52c1d3337080187cf768423efccbb517bb495ab812c4160ff44e",
+}
+
+func TestRistrettoMultiplesOfB(t *testing.T) {
+	curve := Ristretto255()
+	for i, want := range ristrettoMultiplesOfB {
+		enc := curve.Encode(curve.ScalarBaseMult(big.NewInt(int64(i))))
+		if hex.EncodeToString(enc) != want {
+			t.Fatalf("%d*B encoded to %x, want %s\n", i, enc, want)
+		}
+
+		b, _ := hex.DecodeString(want)
+		p, err := curve.Decode(b)
+		if err != nil {
+			t.Fatalf("failed to decode %d*B: %v\n", i, err)
+		}
+		if !bytes.Equal(curve.Encode(p), b) {
+			t.Fatalf("%d*B did not round trip\n", i)
+		}
+	}
+}
+
+func TestRistrettoMap(t *testing.T) {
+	// First one-way map vector from RFC 9496, appendix A.3.
+	in, _ := hex.DecodeString("5d1be09e3d0c82fc538112490e35701979d99e06ca3e2b5b54bffe8b4dc772c1" +
+		"4d98b696a1bbfb5ca32c436cc61c16563790306c79eaca7705668b47dffe5bb6")
+	want := "3066f82a1a747d45120d1740f14358531a8f04bbffe6a819f86dfe50f44a0a46"
+
+	curve := Ristretto255().(*ristretto255Group)
+	p1 := ristrettoMap(in[:32])
+	p2 := ristrettoMap(in[32:])
+	got := curve.Encode(curve.fromEdwards(edAdd(p1, p2)))
+	if hex.EncodeToString(got) != want {
+		t.Fatalf("map encoded to %x, want %s\n", got, want)
+	}
+}
+
+func TestRistrettoRejectsNonCanonical(t *testing.T) {
+	curve := Ristretto255()
+	// The field prime itself is a non-canonical encoding of 0.
+	p, _ := hex.DecodeString("edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	if _, err := curve.Decode(p); err == nil {
+		t.Fatalf("decoding a non-canonical field element should fail\n")
+	}
+	if _, err := curve.Decode(make([]byte, 31)); err == nil {
+		t.Fatalf("decoding a short encoding should fail\n")
+	}
+}
+
+func TestRistrettoConstantTimeField(t *testing.T) {
+	values := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(19),
+		new(big.Int).Sub(edP, edOne), new(big.Int).Sub(edP, big.NewInt(19)),
+		new(big.Int).Lsh(edOne, 254), edD, edSqrtM1}
+	for _, a := range values {
+		fa := ctFeFromBig(a)
+		if fa.big().Cmp(a) != 0 {
+			t.Fatalf("%v did not round trip\n", a)
+		}
+		for _, b := range values {
+			fb := ctFeFromBig(b)
+			if got := ctFeMul(&fa, &fb); got.big().Cmp(feMul(a, b)) != 0 {
+				t.Fatalf("%v * %v = %v, want %v\n", a, b, got.big(), feMul(a, b))
+			}
+			if got := ctFeAdd(&fa, &fb); got.big().Cmp(feAdd(a, b)) != 0 {
+				t.Fatalf("%v + %v = %v, want %v\n", a, b, got.big(), feAdd(a, b))
+			}
+			if got := ctFeSub(&fa, &fb); got.big().Cmp(feSub(a, b)) != 0 {
+				t.Fatalf("%v - %v = %v, want %v\n", a, b, got.big(), feSub(a, b))
+			}
+		}
+		if a.Sign() != 0 {
+			if got := ctFeInvert(&fa); got.big().Cmp(new(big.Int).ModInverse(a, edP)) != 0 {
+				t.Fatalf("1/%v = %v\n", a, got.big())
+			}
+		}
+	}
+}
+
+func TestRistrettoOffCurve(t *testing.T) {
+	curve := Ristretto255()
+	zkpcp, err := NewZKPCurveParams(curve, []byte("zksigma ristretto255 H"))
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	// (1, 2) is not on the Edwards curve.
+	for _, off := range []ECPoint{{big.NewInt(1), big.NewInt(2)}, {}} {
+		for _, p := range []ECPoint{
+			curve.Add(off, zkpcp.G), curve.Add(zkpcp.G, off), curve.Neg(off),
+			curve.ScalarMult(off, big.NewInt(3)), curve.(SecretScalarMultiplier).SecretScalarMult(off, big.NewInt(3)),
+			zkpcp.Add(off, zkpcp.G), zkpcp.Mult(off, big.NewInt(3)),
+		} {
+			if curve.Contains(p) {
+				t.Fatalf("arithmetic on %v gave the group element %v\n", off, p)
+			}
+		}
+
+		x := big.NewInt(5)
+		X := zkpcp.Mult(zkpcp.G, x)
+		if _, err := NewGSPFSProofBase(zkpcp, off, X, x); !errors.Is(err, ErrInvalidPoint) {
+			t.Fatalf("GSPFS proof over %v: expected %v, got %v\n", off, ErrInvalidPoint, err)
+		}
+		if _, err := NewDisjunctiveProof(zkpcp, zkpcp.G, X, off, zkpcp.H, x, Left); !errors.Is(err, ErrInvalidPoint) {
+			t.Fatalf("disjunctive proof over %v: expected %v, got %v\n", off, ErrInvalidPoint, err)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package zksigma
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/mit-dci/zksigma/btcec"
+)
+
+// secp256k1Group implements Group over btcec's secp256k1, using its
+// precomputed tables for multiples of G and of btcec's secondary generator H.
+type secp256k1Group struct {
+	scalarField
+	curve *btcec.KoblitzCurve
+	g     ECPoint // btcec's base point
+	h     ECPoint // point backing btcec.KoblitzCurve.ScalarBaseMultH
+}
+
+var (
+	secp256k1Once     sync.Once
+	secp256k1Instance *secp256k1Group
+)
+
+// Secp256k1 returns the secp256k1 Group.
+func Secp256k1() Group {
+	secp256k1Once.Do(func() {
+		curve := btcec.S256()
+		g := &secp256k1Group{
+			scalarField: scalarField{curve.N},
+			curve:       curve,
+			g:           ECPoint{curve.Gx, curve.Gy},
+		}
+		hX, hY := curve.ScalarBaseMultH(big.NewInt(1).Bytes())
+		g.h = ECPoint{hX, hY}
+		secp256k1Instance = g
+	})
+	return secp256k1Instance
+}
+
+func (g *secp256k1Group) Name() string {
+	return "secp256k1"
+}
+
+func (g *secp256k1Group) Generator() ECPoint {
+	return g.g
+}
+
+func (g *secp256k1Group) Identity() ECPoint {
+	return Zero
+}
+
+func (g *secp256k1Group) IsIdentity(p ECPoint) bool {
+	return p.X.Sign() == 0 && p.Y.Sign() == 0
+}
+
+func (g *secp256k1Group) Contains(p ECPoint) bool {
+	if p.X == nil || p.Y == nil {
+		return false
+	}
+	if g.IsIdentity(p) {
+		return true
+	}
+	P := g.curve.Params().P
+	if p.X.Sign() < 0 || p.X.Cmp(P) >= 0 || p.Y.Sign() < 0 || p.Y.Cmp(P) >= 0 {
+		return false
+	}
+	return g.curve.IsOnCurve(p.X, p.Y)
+}
+
+func (g *secp256k1Group) Add(p, q ECPoint) ECPoint {
+	X, Y := g.curve.Add(p.X, p.Y, q.X, q.Y)
+	return ECPoint{X, Y}
+}
+
+func (g *secp256k1Group) Neg(p ECPoint) ECPoint {
+	if g.IsIdentity(p) {
+		return Zero
+	}
+	negY := new(big.Int).Neg(p.Y)
+	return ECPoint{p.X, negY.Mod(negY, g.curve.Params().P)}
+}
+
+func (g *secp256k1Group) ScalarMult(p ECPoint, k *big.Int) ECPoint {
+	modK := g.ReduceScalar(k)
+	switch {
+	case g.IsIdentity(p) || modK.Sign() == 0:
+		// btcec has no encoding for the identity as an input point
+		return Zero
+	case p.Equal(g.g):
+		X, Y := g.curve.ScalarBaseMult(modK.Bytes())
+		return ECPoint{X, Y}
+	case p.Equal(g.h):
+		X, Y := g.curve.ScalarBaseMultH(modK.Bytes())
+		return ECPoint{X, Y}
+	}
+	X, Y := g.curve.ScalarMult(p.X, p.Y, modK.Bytes())
+	return ECPoint{X, Y}
+}
+
+// SecretScalarMult computes k * p with btcec's ScalarMultConstantTime, for G
+// and H too, as their tables are indexed by the bytes of k.
+func (g *secp256k1Group) SecretScalarMult(p ECPoint, k *big.Int) ECPoint {
+	if g.IsIdentity(p) {
+		return Zero
+	}
+	modK := g.ReduceScalar(k)
+	var kb [32]byte
+	modK.FillBytes(kb[:])
+	X, Y := g.curve.ScalarMultConstantTime(p.X, p.Y, &kb)
+	WipeScalars(modK)
+	wipeBytes(kb[:])
+	return ECPoint{X, Y}
+}
+
+func (g *secp256k1Group) ScalarBaseMult(k *big.Int) ECPoint {
+	X, Y := g.curve.ScalarBaseMult(g.ReduceScalar(k).Bytes())
+	return ECPoint{X, Y}
+}
+
+func (g *secp256k1Group) PointSize() int {
+	return btcec.PubKeyBytesLenCompressed
+}
+
+// Encode returns the 33-byte compressed SEC1 encoding of p.  SEC1 encodes the
+// identity as a single zero byte; we pad that out to 33 zero bytes so that
+// every encoding has the same length.
+func (g *secp256k1Group) Encode(p ECPoint) []byte {
+	if g.IsIdentity(p) {
+		return make([]byte, btcec.PubKeyBytesLenCompressed)
+	}
+	key := btcec.PublicKey{Curve: g.curve, X: p.X, Y: p.Y}
+	return key.SerializeCompressed()
+}
+
+// Decode parses a compressed point with btcec.ParsePubKey, which recovers y
+// and checks the result is on the curve.
+func (g *secp256k1Group) Decode(b []byte) (ECPoint, error) {
+	if len(b) != btcec.PubKeyBytesLenCompressed {
+		return Zero, errors.New("secp256k1: invalid point encoding")
+	}
+	if isZeroBytes(b) {
+		return Zero, nil
+	}
+	key, err := btcec.ParsePubKey(b, g.curve)
+	if err != nil {
+		return Zero, fmt.Errorf("secp256k1: %v", err)
+	}
+	return ECPoint{key.X, key.Y}, nil
+}
+
+// HashToPoint hashes msg with SHA-256 and uses the digest as the x coordinate
+// of a point with even y, rehashing the digest until it lands on the curve.
+// This is how btcec derives its secondary generator H, so hashing
+// G.X + 2 reproduces the point ScalarBaseMultH is built for.
+func (g *secp256k1Group) HashToPoint(msg []byte) ECPoint {
+	x := sha256.Sum256(msg)
+	for {
+		key, err := btcec.ParsePubKey(append([]byte{0x02}, x[:]...), g.curve)
+		if err == nil {
+			return ECPoint{key.X, key.Y}
+		}
+		x = sha256.Sum256(x[:])
+	}
+}
@@ -0,0 +1,72 @@
+package zksigma
+
+import (
+	"io"
+	"math/big"
+)
+
+// SecretScalar holds a secret scalar, such as a private key or a witness,
+// until it is wiped from memory with Destroy.
+//
+// Wiping is best effort: Go may have copied the value while it was computed
+// with, and the garbage collector may have moved it, so this only shortens the
+// time a secret lingers in memory.
+type SecretScalar struct {
+	v *big.Int
+}
+
+// NewSecretScalar returns a SecretScalar holding a copy of v.  Wipe v with
+// WipeScalars if it is no longer needed.
+func NewSecretScalar(v *big.Int) *SecretScalar {
+	return &SecretScalar{new(big.Int).Set(v)}
+}
+
+// Int returns the secret, to be passed to provers, or nil once s is destroyed.
+// It must not be kept or modified: Destroy wipes it in place.
+func (s *SecretScalar) Int() *big.Int {
+	return s.v
+}
+
+// Destroy wipes the secret.  It is safe to call more than once.
+func (s *SecretScalar) Destroy() {
+	WipeScalars(s.v)
+	s.v = nil
+}
+
+// Destroyed reports whether Destroy has been called.
+func (s *SecretScalar) Destroyed() bool {
+	return s.v == nil
+}
+
+// WipeScalars overwrites the memory of every x with zeros, leaving each set to
+// 0.  nil entries are skipped.
+func WipeScalars(xs ...*big.Int) {
+	for _, x := range xs {
+		if x == nil {
+			continue
+		}
+		words := x.Bits()
+		words = words[:cap(words)]
+		for i := range words {
+			words[i] = 0
+		}
+		x.SetInt64(0)
+	}
+}
+
+// wipeBytes overwrites b with zeros.
+func wipeBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// GenerateSecretKey is GenerateKey with the secret key returned as a
+// SecretScalar, to be destroyed once it is no longer needed.
+func GenerateSecretKey(curve Group, base ECPoint, r io.Reader) (ECPoint, *SecretScalar, error) {
+	pk, sk, err := GenerateKey(curve, base, r)
+	if err != nil {
+		return Zero, nil, err
+	}
+	return pk, &SecretScalar{sk}, nil
+}
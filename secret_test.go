@@ -0,0 +1,81 @@
+package zksigma
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestWipeScalars(t *testing.T) {
+	x, _ := new(big.Int).SetString("123456789abcdef0123456789abcdef0123456789abcdef", 16)
+	words := x.Bits()
+	WipeScalars(x, nil)
+	if x.Sign() != 0 {
+		t.Fatalf("expected 0, got %v\n", x)
+	}
+	for i, w := range words {
+		if w != 0 {
+			t.Fatalf("word %d not wiped\n", i)
+		}
+	}
+}
+
+func TestSecretScalar(t *testing.T) {
+	forEachCurve(t, testSecretScalar)
+}
+
+func testSecretScalar(t *testing.T, zkpcp ZKPCurveParams) {
+	PK, sk, err := GenerateSecretKey(zkpcp.C, zkpcp.H, rand.Reader)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	if !zkpcp.Mult(zkpcp.H, sk.Int()).Equal(PK) {
+		t.Fatalf("public key does not match the secret key\n")
+	}
+
+	// Provers wipe their own copies, never the witness passed in.
+	x := NewSecretScalar(big.NewInt(100))
+	X := zkpcp.Mult(zkpcp.G, x.Int())
+	proof, err := NewGSPFSProof(zkpcp, X, x.Int())
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	if x.Int().Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("prover modified the witness\n")
+	}
+	if ok, err := proof.Verify(zkpcp, X); !ok {
+		t.Fatalf("%v\n", err)
+	}
+
+	x.Destroy()
+	sk.Destroy()
+	sk.Destroy()
+	if !x.Destroyed() || x.Int() != nil || !sk.Destroyed() {
+		t.Fatalf("secrets not destroyed\n")
+	}
+}
+
+func TestNonceSourceDestroy(t *testing.T) {
+	for _, deterministic := range []bool{false, true} {
+		zkpcp := TestCurve
+		zkpcp.DeterministicNonces = deterministic
+		ns, err := zkpcp.newNonceSource(GSPFSProofType, []*big.Int{big.NewInt(7)})
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+		var nonces []*big.Int
+		for i := 0; i < 3; i++ {
+			k, err := ns.scalar()
+			if err != nil {
+				t.Fatalf("%v\n", err)
+			}
+			nonces = append(nonces, k)
+		}
+		ns.destroy()
+		for i, k := range nonces {
+			if k.Sign() != 0 {
+				t.Fatalf("deterministic=%v: nonce %d not wiped\n", deterministic, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package zksigma
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// validPoint returns an error unless p is an element of curve other than the
+// identity.  Every Group zksigma supports has prime order, so such a point
+// also generates the whole group: there are no small subgroups to land in.
+func validPoint(curve Group, p ECPoint) error {
+	switch {
+	case p.isNil():
+		return errors.New("missing point")
+	case !curve.Contains(p):
+		return errors.New("point is not on the curve")
+	case curve.IsIdentity(p):
+		return errors.New("point is the identity")
+	}
+	return nil
+}
+
+// validScalar returns an error unless s is in [0, N).
+func validScalar(curve Group, s *big.Int) error {
+	switch {
+	case s == nil:
+		return errors.New("missing scalar")
+	case s.Sign() < 0 || s.Cmp(curve.Order()) >= 0:
+		return errors.New("scalar is not in [0, N)")
+	}
+	return nil
+}
+
+// validChallenge returns an error unless c is a challenge of a compact proof,
+// that is in [0, 2^128).
+func validChallenge(c *big.Int) error {
+	switch {
+	case c == nil:
+		return errors.New("missing challenge")
+	case c.Sign() < 0 || c.BitLen() > 8*compactChallengeSize:
+		return fmt.Errorf("challenge is not in [0, 2^%d)", 8*compactChallengeSize)
+	}
+	return nil
+}
+
+// inputValidator checks the statement and proof a verifier was given, or the
+// statement a prover was given, before any arithmetic is done with them.  Like proofReader it keeps the first error
+// and makes later checks no-ops, so a verifier lists all of its inputs and
+// checks the error once with done.
+type inputValidator struct {
+	zkpcp ZKPCurveParams
+	t     ProofType
+	op    string
+	err   *ProofError
+}
+
+// validateInputs starts checking the inputs of prover or verifier op of proof
+// type t, beginning with the generators of zkpcp.
+func (zkpcp ZKPCurveParams) validateInputs(t ProofType, op string) *inputValidator {
+	v := &inputValidator{zkpcp: zkpcp, t: t, op: op}
+	if zkpcp.C == nil {
+		v.err = &ProofError{Type: t, Op: op, Err: ErrInvalidPoint, Msg: "ZKPCurveParams has no group"}
+		return v
+	}
+	v.point("G", zkpcp.G)
+	v.point("H", zkpcp.H)
+	return v
+}
+
+func (v *inputValidator) point(name string, p ECPoint) {
+	if v.err != nil {
+		return
+	}
+	if err := validPoint(v.zkpcp.C, p); err != nil {
+		v.err = &ProofError{Type: v.t, Op: v.op, Err: ErrInvalidPoint, Msg: fmt.Sprintf("%s: %v", name, err)}
+	}
+}
+
+func (v *inputValidator) scalar(name string, s *big.Int) {
+	if v.err != nil {
+		return
+	}
+	if err := validScalar(v.zkpcp.C, s); err != nil {
+		v.err = &ProofError{Type: v.t, Op: v.op, Err: ErrInvalidScalar, Msg: fmt.Sprintf("%s: %v", name, err)}
+	}
+}
+
+func (v *inputValidator) challenge(name string, c *big.Int) {
+	if v.err != nil {
+		return
+	}
+	if err := validChallenge(c); err != nil {
+		v.err = &ProofError{Type: v.t, Op: v.op, Err: ErrInvalidScalar, Msg: fmt.Sprintf("%s: %v", name, err)}
+	}
+}
+
+// hash checks that the proof was made with the hash the verifier computes
+// challenges with.  Otherwise every challenge would fail to match, and the
+// error would not say why.
+func (v *inputValidator) hash(h ChallengeHash) {
+	if v.err != nil {
+		return
+	}
+	if h != v.zkpcp.Hash {
+		v.err = &ProofError{Type: v.t, Op: v.op, Err: ErrHashMismatch, Msg: fmt.Sprintf("proof was made with %v, the parameters use %v", h, v.zkpcp.Hash)}
+	}
+}
+
+// done returns the first error found, if any.
+func (v *inputValidator) done() error {
+	if v.err == nil {
+		return nil
+	}
+	return v.zkpcp.traceError(v.err)
+}
@@ -0,0 +1,132 @@
+package zksigma
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestVerifierInputValidation(t *testing.T) {
+	forEachCurve(t, testVerifierInputValidation)
+}
+
+func testVerifierInputValidation(t *testing.T, zkpcp ZKPCurveParams) {
+	offCurve := ECPoint{new(big.Int).Add(zkpcp.G.X, big.NewInt(1)), zkpcp.G.Y}
+	if zkpcp.C.Contains(offCurve) {
+		t.Fatalf("test point is on the curve\n")
+	}
+
+	rejects := func(name string, err, target error) {
+		t.Helper()
+		if !errors.Is(err, target) {
+			t.Fatalf("%s: expected %v, got %v\n", name, target, err)
+		}
+	}
+
+	// Bad generators are rejected by every verifier.
+	for _, ps := range sampleProofs(t, zkpcp) {
+		for name, bad := range map[string]ZKPCurveParams{
+			"off-curve G": {C: zkpcp.C, G: offCurve, H: zkpcp.H, HPoints: zkpcp.HPoints},
+			"identity H":  {C: zkpcp.C, G: zkpcp.G, H: zkpcp.C.Identity(), HPoints: zkpcp.HPoints},
+			"no group":    {G: zkpcp.G, H: zkpcp.H, HPoints: zkpcp.HPoints},
+		} {
+			st := withCurveParams(ps.st, bad)
+			_, err := ps.proof.VerifyStatement(st)
+			rejects(ps.proof.Type().String()+" "+name, err, ErrInvalidPoint)
+			if r, _ := ps.proof.DiagnoseStatement(st); r == nil || r.OK() || r.Checks[0].Passed {
+				t.Fatalf("%v %s: diagnosis should fail the %q check\n", ps.proof.Type(), name, wellFormedCheck)
+			}
+		}
+	}
+
+	x := big.NewInt(100)
+	X := zkpcp.Mult(zkpcp.G, x)
+	gspfs, err := NewGSPFSProof(zkpcp, X, x)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	_, err = gspfs.Verify(zkpcp, offCurve)
+	rejects("off-curve statement", err, ErrInvalidPoint)
+	_, err = gspfs.Verify(zkpcp, zkpcp.C.Identity())
+	rejects("identity statement", err, ErrInvalidPoint)
+
+	bad := *gspfs
+	bad.RandCommit = offCurve
+	_, err = bad.Verify(zkpcp, X)
+	rejects("off-curve commitment", err, ErrInvalidPoint)
+
+	bad = *gspfs
+	bad.HiddenValue = new(big.Int).Add(gspfs.HiddenValue, zkpcp.C.Order())
+	_, err = bad.Verify(zkpcp, X)
+	rejects("unreduced response", err, ErrInvalidScalar)
+	if bad.Diagnose(zkpcp, X).OK() {
+		t.Fatalf("diagnosis of an unreduced response should fail\n")
+	}
+
+	bad = *gspfs
+	bad.Challenge = nil
+	_, err = bad.Verify(zkpcp, X)
+	rejects("missing challenge", err, ErrInvalidScalar)
+
+	rp, r, err := NewRangeProof(zkpcp, x)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	comm := PedCommitR(zkpcp, x, r)
+
+	badRP := *rp
+	badRP.ProofTuples = append([]rangeProofTuple(nil), rp.ProofTuples...)
+	badRP.ProofTuples[3].C = offCurve
+	_, err = badRP.Verify(zkpcp, comm)
+	rejects("off-curve range entry", err, ErrInvalidPoint)
+
+	badRP.ProofTuples = append([]rangeProofTuple(nil), rp.ProofTuples...)
+	badRP.ProofTuples[3].S = new(big.Int).Neg(big.NewInt(1))
+	_, err = badRP.Verify(zkpcp, comm)
+	rejects("negative range entry", err, ErrInvalidScalar)
+
+	badRP.ProofTuples = append(rp.ProofTuples, rp.ProofTuples...)
+	_, err = badRP.Verify(zkpcp, comm)
+	rejects("too many range entries", err, ErrMalformedProof)
+}
+
+// withCurveParams returns st with its ZKPCurveParams replaced by zkpcp.
+func withCurveParams(st Statement, zkpcp ZKPCurveParams) Statement {
+	switch st := st.(type) {
+	case GSPFSStatement:
+		st.Params = zkpcp
+		return st
+	case EquivalenceStatement:
+		st.Params = zkpcp
+		return st
+	case DisjunctiveStatement:
+		st.Params = zkpcp
+		return st
+	case ConsistencyStatement:
+		st.Params = zkpcp
+		return st
+	case ABCStatement:
+		st.Params = zkpcp
+		return st
+	case InequalityStatement:
+		st.Params = zkpcp
+		return st
+	case RangeStatement:
+		st.Params = zkpcp
+		return st
+	case CompactGSPFSStatement:
+		st.Params = zkpcp
+		return st
+	case CompactEquivalenceStatement:
+		st.Params = zkpcp
+		return st
+	case CompactDisjunctiveStatement:
+		st.Params = zkpcp
+		return st
+	case CompactConsistencyStatement:
+		st.Params = zkpcp
+		return st
+	}
+	panic("unknown statement type")
+}
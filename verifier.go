@@ -0,0 +1,129 @@
+package zksigma
+
+import (
+	"context"
+	"runtime"
+)
+
+// Verifier verifies many proofs, of any mix of types, in parallel.  The zero
+// Verifier is ready to use.
+type Verifier struct {
+	// Workers is the number of proofs verified at once, GOMAXPROCS if it is
+	// not positive.
+	Workers int
+}
+
+// NewVerifier returns a Verifier that verifies up to workers proofs at once.
+func NewVerifier(workers int) *Verifier {
+	return &Verifier{Workers: workers}
+}
+
+// VerifyJob is a proof and the statement to verify it against.
+type VerifyJob struct {
+	Proof     Proof
+	Statement Statement
+}
+
+// VerifyResult is the outcome of a VerifyJob: what Proof.VerifyStatement
+// returned, or ctx.Err() if the job was cancelled before it was verified.
+type VerifyResult struct {
+	Index int // position of the job among those given to the Verifier
+	Job   VerifyJob
+	OK    bool
+	Err   error
+}
+
+func (v *Verifier) workers() int {
+	if v.Workers <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return v.Workers
+}
+
+func verifyJob(ctx context.Context, i int, job VerifyJob) VerifyResult {
+	r := VerifyResult{Index: i, Job: job}
+	switch {
+	case ctx.Err() != nil:
+		r.Err = ctx.Err()
+	case job.Proof == nil:
+		r.Err = &ProofError{Op: "Verifier.Verify", Err: ErrMalformedProof, Msg: "job has no proof"}
+	default:
+		r.OK, r.Err = job.Proof.VerifyStatement(job.Statement)
+	}
+	return r
+}
+
+// VerifyAll verifies jobs and returns their results in the same order.  Jobs
+// that were not verified because ctx was done fail with ctx.Err().
+func (v *Verifier) VerifyAll(ctx context.Context, jobs []VerifyJob) []VerifyResult {
+	results := make([]VerifyResult, len(jobs))
+	verified := make([]bool, len(jobs))
+	err := forEachIndex(ctx, v.workers(), len(jobs), func(i int) error {
+		results[i] = verifyJob(ctx, i, jobs[i])
+		verified[i] = true
+		return nil
+	})
+	if err != nil {
+		for i := range results {
+			if !verified[i] {
+				results[i] = VerifyResult{Index: i, Job: jobs[i], Err: err}
+			}
+		}
+	}
+	return results
+}
+
+// Verify verifies the jobs read from jobs until it is closed or ctx is done,
+// and sends their results on the returned channel in the order the jobs were
+// read.  At most Workers jobs are verified at once, and as the results are
+// kept in order, at most Workers more are held until they are read.  Jobs
+// already read when ctx is done fail with ctx.Err().  The channel is closed
+// after the last result, and must be read until then.
+func (v *Verifier) Verify(ctx context.Context, jobs <-chan VerifyJob) <-chan VerifyResult {
+	workers := v.workers()
+	type task struct {
+		i      int
+		job    VerifyJob
+		result chan VerifyResult
+	}
+	tasks := make(chan task)
+	pending := make(chan chan VerifyResult, workers)
+	results := make(chan VerifyResult)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			for t := range tasks {
+				t.result <- verifyJob(ctx, t.i, t.job)
+			}
+		}()
+	}
+
+	// Hand each job to a worker, queueing the channel its result will arrive
+	// on so that results can be sent in order.
+	go func() {
+		defer close(pending)
+		defer close(tasks)
+		for i := 0; ; i++ {
+			var job VerifyJob
+			ok := false
+			select {
+			case job, ok = <-jobs:
+			case <-ctx.Done():
+			}
+			if !ok {
+				return
+			}
+			t := task{i, job, make(chan VerifyResult, 1)}
+			pending <- t.result
+			tasks <- t
+		}
+	}()
+
+	go func() {
+		defer close(results)
+		for r := range pending {
+			results <- <-r
+		}
+	}()
+	return results
+}
@@ -0,0 +1,88 @@
+package zksigma
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// verifierJobs returns the sample proofs of every curve, each followed by the
+// same proof against a statement of the wrong type, which must fail.
+func verifierJobs(t *testing.T) []VerifyJob {
+	var jobs []VerifyJob
+	forEachCurve(t, func(t *testing.T, zkpcp ZKPCurveParams) {
+		for _, ps := range sampleProofs(t, zkpcp) {
+			jobs = append(jobs,
+				VerifyJob{ps.proof, ps.st},
+				VerifyJob{ps.proof, GSPFSStatement{zkpcp, zkpcp.G, zkpcp.G}})
+		}
+	})
+	return append(jobs, VerifyJob{nil, nil})
+}
+
+func checkVerifyResults(t *testing.T, jobs []VerifyJob, results []VerifyResult) {
+	t.Helper()
+	if len(results) != len(jobs) {
+		t.Fatalf("expected %d results, got %d\n", len(jobs), len(results))
+	}
+	for i, r := range results {
+		if r.Index != i || r.Job.Proof != jobs[i].Proof {
+			t.Fatalf("result %d is for job %d\n", i, r.Index)
+		}
+		last := i == len(jobs)-1
+		if wantOK := i%2 == 0 && !last; r.OK != wantOK || (r.Err == nil) != wantOK {
+			t.Fatalf("job %d: expected ok=%v, got %v, %v\n", i, wantOK, r.OK, r.Err)
+		}
+		if last && !errors.Is(r.Err, ErrMalformedProof) {
+			t.Fatalf("job without a proof: expected %v, got %v\n", ErrMalformedProof, r.Err)
+		}
+	}
+}
+
+func TestVerifierVerifyAll(t *testing.T) {
+	jobs := verifierJobs(t)
+	for _, workers := range []int{0, 1, 3} {
+		checkVerifyResults(t, jobs, NewVerifier(workers).VerifyAll(context.Background(), jobs))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i, r := range new(Verifier).VerifyAll(ctx, jobs) {
+		if r.Index != i || r.OK || !errors.Is(r.Err, context.Canceled) {
+			t.Fatalf("job %d: expected cancellation, got %v, %v\n", i, r.OK, r.Err)
+		}
+	}
+}
+
+func TestVerifierStream(t *testing.T) {
+	jobs := verifierJobs(t)
+	for _, workers := range []int{0, 1, 3} {
+		in := make(chan VerifyJob)
+		go func() {
+			for _, job := range jobs {
+				in <- job
+			}
+			close(in)
+		}()
+		var results []VerifyResult
+		for r := range NewVerifier(workers).Verify(context.Background(), in) {
+			results = append(results, r)
+		}
+		checkVerifyResults(t, jobs, results)
+	}
+
+	// Cancelling stops reading jobs and closes the results.
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan VerifyJob)
+	out := NewVerifier(2).Verify(ctx, in)
+	in <- jobs[0]
+	if r := <-out; !r.OK {
+		t.Fatalf("expected the first job to verify, got %v\n", r.Err)
+	}
+	cancel()
+	for r := range out {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Fatalf("expected cancellation, got %v, %v\n", r.OK, r.Err)
+		}
+	}
+}
@@ -16,10 +16,7 @@ More info on Github
 package zksigma
 
 import (
-	"crypto/sha256"
 	"math/big"
-
-	"github.com/mit-dci/zksigma/btcec"
 )
 
 // Side is an enum to pick what side of the proof you want to generate
@@ -33,27 +30,29 @@ const (
 )
 
 // TestCurve is a global cache for the curve and two generator points used in the test cases.
-// It is equal to ZKLedger's curve - but for abstraction the actual curve parameters are
-// passed into the proof functions. We just test with the same params that ZKLedger uses.
+// It is secp256k1 with G the group's generator and H hashed from G.X + 2 - but for
+// abstraction the actual curve parameters are passed into the proof functions.
 var TestCurve ZKPCurveParams
 
-func generateH2tothe() []ECPoint {
-	Hslice := make([]ECPoint, 64)
-	for i := range Hslice {
-		m := big.NewInt(1 << uint(i))
-		Hslice[i].X, Hslice[i].Y = TestCurve.C.ScalarBaseMult(m.Bytes())
+// generateH2tothe returns 2^i * G for i in [0...63], doubling from G so that
+// it also works for a G that is not the group's generator.
+func generateH2tothe(curve Group, G ECPoint) []ECPoint {
+	Hslice := make([]ECPoint, numHPoints)
+	Hslice[0] = G
+	for i := 1; i < len(Hslice); i++ {
+		Hslice[i] = curve.Add(Hslice[i-1], Hslice[i-1])
 	}
 	return Hslice
 }
 
 func init() {
-	s256 := sha256.New()
-	hashedString := s256.Sum([]byte("This is the new random point in zksigma"))
-	HX, HY := btcec.S256().ScalarMult(btcec.S256().Gx, btcec.S256().Gy, hashedString)
-	TestCurve = ZKPCurveParams{
-		C: btcec.S256(),
-		G: ECPoint{btcec.S256().Gx, btcec.S256().Gy},
-		H: ECPoint{HX, HY},
+	curve := Secp256k1()
+	// H is hashed from G.X + 2, which is the point btcec has precomputed
+	// tables for, so multiplying by H stays on the fast path.
+	seed := new(big.Int).Add(curve.Generator().X, big.NewInt(2)).Bytes()
+	var err error
+	TestCurve, err = NewZKPCurveParams(curve, seed)
+	if err != nil {
+		panic(err)
 	}
-	TestCurve.HPoints = generateH2tothe()
 }
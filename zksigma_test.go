@@ -0,0 +1,62 @@
+package zksigma
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"sync"
+	"testing"
+)
+
+// debug makes the tests log every proof step and failure to stderr.
+var debug = flag.Bool("debug1", false, "Debug output")
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if *debug {
+		TestCurve.Logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	os.Exit(m.Run())
+}
+
+func proofStatus(e *ProofError) int {
+	if *debug && e != nil {
+		fmt.Printf("ERROR: %v \n", e.Error())
+		return -1
+	}
+	return 0
+}
+
+var (
+	testCurvesOnce sync.Once
+	testCurves     []ZKPCurveParams
+)
+
+// forEachCurve runs test as a subtest once for every group zksigma supports.
+func forEachCurve(t *testing.T, test func(t *testing.T, zkpcp ZKPCurveParams)) {
+	testCurvesOnce.Do(func() {
+		R, err := NewZKPCurveParams(Ristretto255(), []byte("zksigma ristretto255 H"))
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		// Same derivation as TestCurve, so H takes the P-256 fast path.
+		p256 := P256()
+		P, err := NewZKPCurveParams(p256, new(big.Int).Add(p256.Generator().X, big.NewInt(2)).Bytes())
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		R.Logger, P.Logger = TestCurve.Logger, TestCurve.Logger
+		testCurves = []ZKPCurveParams{TestCurve, R, P}
+	})
+
+	for _, zkpcp := range testCurves {
+		zkpcp := zkpcp
+		t.Run(zkpcp.C.Name(), func(t *testing.T) {
+			test(t, zkpcp)
+		})
+	}
+}